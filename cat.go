@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/labtiva/stoptail/internal/es"
+	"github.com/spf13/cobra"
+)
+
+var (
+	catOutputFlag string
+	catFilterFlag string
+)
+
+// catReport is one of the reports "stoptail cat" can print.
+type catReport struct {
+	name  string
+	short string
+	build func(ctx context.Context, client *es.Client, filter string) (*table, error)
+}
+
+var catReports = []catReport{
+	{"health", "Cluster health", catHealth},
+	{"indices", "Indices with health, docs and size", catIndices},
+	{"shards", "Shard allocation", catShards},
+	{"nodes", "Node heap, disk and shard counts", catNodes},
+	{"tasks", "Long-running tasks (reindex, by-query, force merge, snapshot)", catTasks},
+	{"shard-health", "Shard sizing analysis per index", catShardHealth},
+}
+
+func newCatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cat",
+		Short: "Print cluster reports without the TUI",
+		Long: `Print a report as an aligned table, JSON or CSV and exit.
+
+--filter narrows indices, shards and shard-health by index name (substring,
+or prefix when it ends in "*") or exact alias, like the overview filter.
+For nodes it matches the node name, for tasks the action or description.
+
+Examples:
+  stoptail cat health production
+  stoptail cat indices production --filter 'logs-*'
+  stoptail cat shard-health production -o csv > shards.csv
+  stoptail cat nodes -o json | jq -r '.[].name'`,
+	}
+	cmd.PersistentFlags().StringVarP(&catOutputFlag, "output", "o", "table", "Output format: table, json, csv")
+	cmd.PersistentFlags().StringVar(&catFilterFlag, "filter", "", "Only show matching indices, nodes or tasks")
+
+	for _, r := range catReports {
+		report := r
+		cmd.AddCommand(&cobra.Command{
+			Use:          report.name + " [cluster]",
+			Short:        report.short,
+			Args:         cobra.MaximumNArgs(1),
+			SilenceUsage: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runCat(cmd.OutOrStdout(), report, args)
+			},
+		})
+	}
+	return cmd
+}
+
+func runCat(w io.Writer, report catReport, args []string) error {
+	switch catOutputFlag {
+	case "table", "json", "csv":
+	default:
+		return fmt.Errorf("unknown output format %q (use: table, json, csv)", catOutputFlag)
+	}
+
+	resolved, awsProfile, err := resolveESURL(args, true)
+	if err != nil {
+		return err
+	}
+	client, _, err := openCluster(resolved, awsProfile)
+	if err != nil {
+		return err
+	}
+	if auditLog := client.AuditLog(); auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	t, err := report.build(context.Background(), client, catFilterFlag)
+	if err != nil {
+		return err
+	}
+	return t.write(w, catOutputFlag)
+}
+
+// table is a report's columns and rows, printable in every output format.
+// JSON output uses the column names with dots and dashes turned into
+// underscores as keys.
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return t.writeJSON(w)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(t.columns)
+		cw.WriteAll(t.rows)
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.columns, "\t")))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (t *table) writeJSON(w io.Writer) error {
+	keys := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		keys[i] = strings.NewReplacer(".", "_", "-", "_").Replace(c)
+	}
+
+	// Build the objects by hand so keys keep column order.
+	var b strings.Builder
+	b.WriteString("[")
+	for i, row := range t.rows {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n  {")
+		for j, cell := range row {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			key, _ := json.Marshal(keys[j])
+			value, _ := json.Marshal(cell)
+			b.Write(key)
+			b.WriteString(": ")
+			b.Write(value)
+		}
+		b.WriteString("}")
+	}
+	if len(t.rows) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("]\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func catHealth(ctx context.Context, client *es.Client, _ string) (*table, error) {
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{columns: []string{"status", "nodes", "indices", "active_primary_shards", "active_shards", "unassigned_shards"}}
+	h := state.Health
+	t.add(h.Status, strconv.Itoa(len(state.Nodes)), strconv.Itoa(len(state.Indices)),
+		strconv.Itoa(h.ActivePrimaryShards), strconv.Itoa(h.ActiveShards), strconv.Itoa(h.UnassignedShards))
+	return t, nil
+}
+
+func catIndices(ctx context.Context, client *es.Client, filter string) (*table, error) {
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{columns: []string{"index", "health", "status", "pri", "rep", "docs.count", "docs.deleted", "store.size", "pri.store.size", "aliases"}}
+	for _, idx := range filterIndices(state, filter) {
+		t.add(idx.Name, idx.Health, idx.Status, idx.Pri, idx.Rep, idx.DocsCount, idx.DocsDeleted,
+			idx.StoreSize, idx.PriStoreSize, strings.Join(state.GetAliasesForIndex(idx.Name), ","))
+	}
+	return t, nil
+}
+
+func catShards(ctx context.Context, client *es.Client, filter string) (*table, error) {
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var shards []es.ShardInfo
+	for _, sh := range state.Shards {
+		if filter == "" || state.IndexMatchesFilter(sh.Index, filter) {
+			shards = append(shards, sh)
+		}
+	}
+	sort.SliceStable(shards, func(i, j int) bool {
+		if shards[i].Index != shards[j].Index {
+			return shards[i].Index < shards[j].Index
+		}
+		a, _ := strconv.Atoi(shards[i].Shard)
+		b, _ := strconv.Atoi(shards[j].Shard)
+		if a != b {
+			return a < b
+		}
+		return shards[i].Primary && !shards[j].Primary
+	})
+
+	t := &table{columns: []string{"index", "shard", "prirep", "state", "node"}}
+	for _, sh := range shards {
+		t.add(sh.Index, sh.Shard, sh.PriRep, sh.State, sh.Node)
+	}
+	return t, nil
+}
+
+func catNodes(ctx context.Context, client *es.Client, filter string) (*table, error) {
+	nodes, err := client.FetchNodeStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{columns: []string{"name", "master", "version", "heap.percent", "heap.current", "heap.max", "disk.used_percent", "disk.used", "disk.total", "shards"}}
+	for _, n := range nodes {
+		if filter != "" && !es.MatchesIndexFilter(n.Name, filter) {
+			continue
+		}
+		t.add(n.Name, n.Master, n.Version, n.HeapPercent, n.HeapCurrent, n.HeapMax, n.DiskPercent, n.DiskUsed, n.DiskTotal, n.Shards)
+	}
+	return t, nil
+}
+
+func catTasks(ctx context.Context, client *es.Client, filter string) (*table, error) {
+	tasks, err := client.FetchTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{columns: []string{"id", "action", "node", "running_time", "running_time_ms", "cancellable", "description"}}
+	for _, task := range tasks {
+		if filter != "" && !es.MatchesIndexFilter(task.Action, filter) && !es.MatchesIndexFilter(task.Description, filter) {
+			continue
+		}
+		t.add(task.ID, task.Action, task.Node, task.RunningTime, strconv.FormatInt(task.RunningTimeMs, 10),
+			strconv.FormatBool(task.Cancellable), task.Description)
+	}
+	return t, nil
+}
+
+var shardHealthStatusNames = map[es.ShardHealthStatus]string{
+	es.ShardHealthOK:       "ok",
+	es.ShardHealthWarning:  "warning",
+	es.ShardHealthCritical: "critical",
+}
+
+// catShardHealth lists indices worst first, like the Cluster tab's Shards view.
+func catShardHealth(ctx context.Context, client *es.Client, filter string) (*table, error) {
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var health []es.ShardHealth
+	for _, idx := range filterIndices(state, filter) {
+		health = append(health, es.AnalyzeShardHealth(idx))
+	}
+	sort.SliceStable(health, func(i, j int) bool {
+		return health[i].Status > health[j].Status
+	})
+
+	t := &table{columns: []string{"index", "status", "summary", "shards", "total_size", "avg_shard_size", "docs", "avg_docs_per_shard", "issues"}}
+	for _, h := range health {
+		t.add(h.IndexName, shardHealthStatusNames[h.Status], h.StatusText, strconv.Itoa(h.ShardCount),
+			es.FormatBytes(h.TotalSize), es.FormatBytes(h.AvgShardSize),
+			strconv.FormatInt(h.DocsCount, 10), strconv.FormatInt(h.AvgDocsPerShard, 10),
+			strings.Join(h.Issues, "; "))
+	}
+	return t, nil
+}
+
+func filterIndices(state *es.ClusterState, filter string) []es.IndexInfo {
+	var indices []es.IndexInfo
+	for _, idx := range state.Indices {
+		if filter == "" || state.IndexMatchesFilter(idx.Name, filter) {
+			indices = append(indices, idx)
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i].Name < indices[j].Name
+	})
+	return indices
+}
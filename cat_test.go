@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/estest"
+)
+
+func catReportNamed(name string) catReport {
+	for _, r := range catReports {
+		if r.name == name {
+			return r
+		}
+	}
+	panic("no report " + name)
+}
+
+func TestCatReports(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv := estest.NewServer(t, estest.Fixture{
+		Nodes: []estest.Node{{Name: "node-1", Master: true}, {Name: "node-2"}},
+		Indices: []estest.Index{
+			{Name: "logs-2024.05", Aliases: []string{"archive"}},
+			{Name: "logs-2024.06", Shards: 2, Replicas: 1},
+			{Name: "metrics", Replicas: 1, States: map[string]string{"0r": "UNASSIGNED"}},
+		},
+		Tasks: []estest.Task{
+			{Node: "node-1", ID: 7, Action: "indices:data/write/reindex", Description: "reindex logs", RunningMs: 5000, Cancellable: true},
+		},
+	})
+
+	tests := []struct {
+		report string
+		output string
+		filter string
+		want   []string
+		reject []string
+	}{
+		{"health", "table", "", []string{"STATUS", "yellow"}, nil},
+		{"indices", "table", "logs*", []string{"logs-2024.05", "logs-2024.06", "archive"}, []string{"metrics"}},
+		{"indices", "csv", "archive", []string{"index,health,status", "logs-2024.05,"}, []string{"logs-2024.06"}},
+		{"shards", "json", "metrics", []string{`"state": "UNASSIGNED"`, `"prirep": "r"`}, []string{"logs"}},
+		{"nodes", "table", "node-2", []string{"node-2"}, []string{"node-1"}},
+		{"tasks", "csv", "reindex", []string{"node-1:7,indices:data/write/reindex"}, nil},
+		{"shard-health", "json", "", []string{`"index": "logs-2024.05"`, `"status": "ok"`}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.report+"/"+tt.output, func(t *testing.T) {
+			catOutputFlag, catFilterFlag = tt.output, tt.filter
+			defer func() { catOutputFlag, catFilterFlag = "table", "" }()
+
+			var out bytes.Buffer
+			if err := runCat(&out, catReportNamed(tt.report), []string{srv.URL}); err != nil {
+				t.Fatalf("runCat() error = %v", err)
+			}
+			got := out.String()
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output should contain %q:\n%s", want, got)
+				}
+			}
+			for _, reject := range tt.reject {
+				if strings.Contains(got, reject) {
+					t.Errorf("output should not contain %q:\n%s", reject, got)
+				}
+			}
+			if tt.output == "json" && !json.Valid(out.Bytes()) {
+				t.Errorf("output is not valid JSON:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestCatRejectsUnknownOutput(t *testing.T) {
+	catOutputFlag = "xml"
+	defer func() { catOutputFlag = "table" }()
+	if err := runCat(&bytes.Buffer{}, catReportNamed("health"), nil); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+}
+
+func TestTableWriteJSONEmpty(t *testing.T) {
+	var out bytes.Buffer
+	tbl := &table{columns: []string{"index"}}
+	if err := tbl.write(&out, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "[]\n" {
+		t.Errorf("got %q, want empty array", out.String())
+	}
+}
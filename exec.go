@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/labtiva/stoptail/internal/es"
+	"github.com/labtiva/stoptail/internal/storage"
+	"github.com/labtiva/stoptail/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	execDataFlag     string
+	execFileFlag     string
+	execBookmarkFlag string
+	execOutputFlag   string
+)
+
+var httpMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "DELETE": true, "HEAD": true, "PATCH": true}
+
+func newExecCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "exec [cluster] [METHOD PATH]",
+		Short: "Send one request without the TUI",
+		Long: `Send a single request and print the response. Exits non-zero when the
+request fails or the server answers with an HTTP error.
+
+The request is given inline, read from a file in console format (first
+line "METHOD /path", the rest is the body), or taken from a bookmark.
+The cluster is resolved exactly like the TUI does.
+
+Examples:
+  stoptail exec production GET /_cluster/health
+  stoptail exec GET /logs/_search --data '{"size":1}'
+  stoptail exec staging --file reindex.txt --output raw
+  stoptail exec production --bookmark "slow queries" --output yaml`,
+		Args:         cobra.MaximumNArgs(3),
+		SilenceUsage: true,
+		RunE:         runExec,
+	}
+
+	cmd.Flags().StringVarP(&execDataFlag, "data", "d", "", "Request body (@file to read a file, @- for stdin)")
+	cmd.Flags().StringVarP(&execFileFlag, "file", "f", "", "Read the request from a file (- for stdin)")
+	cmd.Flags().StringVarP(&execBookmarkFlag, "bookmark", "b", "", "Run a saved bookmark")
+	cmd.Flags().StringVarP(&execOutputFlag, "output", "o", "json", "Output format: raw, json, yaml")
+	cmd.Flags().BoolVar(&readOnlyFlag, "read-only", false, "Block every request that could modify the cluster")
+	cmd.MarkFlagsMutuallyExclusive("file", "bookmark")
+	cmd.MarkFlagsMutuallyExclusive("data", "bookmark")
+	return cmd
+}
+
+type execRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+func runExec(cmd *cobra.Command, args []string) error {
+	switch execOutputFlag {
+	case "raw", "json", "yaml":
+	default:
+		return fmt.Errorf("unknown output format %q (use: raw, json, yaml)", execOutputFlag)
+	}
+
+	clusterArgs, req, err := parseExecArgs(args, cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	resolved, awsProfile, err := resolveESURL(clusterArgs, true)
+	if err != nil {
+		return err
+	}
+	client, cfg, err := openCluster(resolved, awsProfile)
+	if err != nil {
+		return err
+	}
+	req, err = expandExecRequest(req, cfg.Variables)
+	if err != nil {
+		return err
+	}
+	if auditLog := client.AuditLog(); auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	result := client.Request(context.Background(), req.Method, req.Path, req.Body)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	out, err := formatExecOutput(result, execOutputFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), out)
+
+	if result.StatusCode >= 400 {
+		return fmt.Errorf("%s %s: HTTP %d", req.Method, req.Path, result.StatusCode)
+	}
+	return nil
+}
+
+// parseExecArgs splits the positional arguments into the optional cluster
+// and the request, which comes from the arguments, --file or --bookmark.
+// The cluster is told apart by position, never by name, so a cluster
+// called "delete" is not taken for the method.
+func parseExecArgs(args []string, stdin io.Reader) ([]string, execRequest, error) {
+	var clusterArgs []string
+	var req execRequest
+	switch {
+	case execBookmarkFlag != "":
+		if len(args) > 1 {
+			return nil, req, fmt.Errorf("--bookmark cannot be combined with METHOD PATH")
+		}
+		clusterArgs = args
+		bookmarks, err := storage.LoadBookmarks()
+		if err != nil {
+			return nil, req, fmt.Errorf("loading bookmarks: %w", err)
+		}
+		b := bookmarks.Get(execBookmarkFlag)
+		if b == nil {
+			return nil, req, fmt.Errorf("bookmark %q not found", execBookmarkFlag)
+		}
+		req = execRequest{Method: b.Method, Path: b.Path, Body: b.Body}
+
+	case execFileFlag != "":
+		if len(args) > 1 {
+			return nil, req, fmt.Errorf("--file cannot be combined with METHOD PATH")
+		}
+		clusterArgs = args
+		data, err := readInput(execFileFlag, stdin)
+		if err != nil {
+			return nil, req, err
+		}
+		req, err = parseExecFile(string(data))
+		if err != nil {
+			return nil, req, fmt.Errorf("%s: %w", execFileFlag, err)
+		}
+
+	default:
+		if len(args) == 3 {
+			clusterArgs, args = args[:1], args[1:]
+		}
+		if len(args) != 2 {
+			return nil, req, fmt.Errorf("expected METHOD PATH, --file or --bookmark")
+		}
+		req = execRequest{Method: args[0], Path: args[1]}
+	}
+
+	if execDataFlag != "" {
+		body := execDataFlag
+		if name, ok := strings.CutPrefix(body, "@"); ok {
+			data, err := readInput(name, stdin)
+			if err != nil {
+				return nil, req, err
+			}
+			body = string(data)
+		}
+		req.Body = body
+	}
+
+	req.Method = strings.ToUpper(req.Method)
+	if !httpMethods[req.Method] {
+		return nil, req, fmt.Errorf("unsupported method %q", req.Method)
+	}
+	if !strings.HasPrefix(req.Path, "/") {
+		req.Path = "/" + req.Path
+	}
+	return clusterArgs, req, nil
+}
+
+// expandExecRequest fills in {{variables}} from the cluster's config the
+// way the Workbench does, so bookmarks and console files work unchanged.
+func expandExecRequest(req execRequest, variables map[string]string) (execRequest, error) {
+	v := ui.Variables{Values: variables, Now: time.Now()}
+	expanded, err := v.ExpandRequest(ui.ConsoleRequest{Method: req.Method, Path: req.Path, Body: req.Body})
+	if err != nil {
+		return req, err
+	}
+	return execRequest{Method: expanded.Method, Path: expanded.Path, Body: expanded.Body}, nil
+}
+
+func readInput(name string, stdin io.Reader) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(stdin)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("reading request: %w", err)
+	}
+	return data, nil
+}
+
+// parseExecFile reads the request from a file in console format, parsed
+// exactly like the Workbench console so a saved console file can be sent
+// as is. The file must hold a single request.
+func parseExecFile(text string) (execRequest, error) {
+	requests := ui.ParseConsole(text)
+	switch len(requests) {
+	case 0:
+		return execRequest{}, fmt.Errorf("no request found, expected a \"METHOD /path\" line")
+	case 1:
+		r := requests[0]
+		return execRequest{Method: r.Method, Path: r.Path, Body: r.Body}, nil
+	}
+	return execRequest{}, fmt.Errorf("found %d requests, exec sends one", len(requests))
+}
+
+// formatExecOutput renders the response for --output. raw prints the body
+// byte for byte as the server sent it.
+func formatExecOutput(result es.RequestResult, format string) (string, error) {
+	if format == "raw" {
+		return string(result.Raw), nil
+	}
+	body := []byte(result.Body)
+	if !json.Valid(body) {
+		return ensureNewline(result.Body), nil
+	}
+	if format == "yaml" {
+		return jsonToYAML(body)
+	}
+	return ensureNewline(result.Body), nil
+}
+
+// jsonToYAML converts via yaml.Node so object keys keep the order the
+// server returned them in.
+func jsonToYAML(data []byte) (string, error) {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return "", fmt.Errorf("converting to yaml: %w", err)
+	}
+	clearStyle(&node)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return "", fmt.Errorf("converting to yaml: %w", err)
+	}
+	return out.String(), nil
+}
+
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+func ensureNewline(s string) string {
+	if s == "" || strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
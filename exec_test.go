@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+func TestParseExecFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    execRequest
+		wantErr bool
+	}{
+		{"no body", "GET /_cluster/health\n", execRequest{Method: "GET", Path: "/_cluster/health"}, false},
+		{"with body", "\nPOST /logs/_search\n{\n  \"size\": 1\n}\n", execRequest{Method: "POST", Path: "/logs/_search", Body: "{\n  \"size\": 1\n}"}, false},
+		{"console file", "# reindex\npost logs/_reindex\n{\"script\": \"\"\"ctx._source.n++\"\"\"}\n", execRequest{Method: "POST", Path: "/logs/_reindex", Body: `{"script": "ctx._source.n++"}`}, false},
+		{"missing path", "GET\n", execRequest{}, true},
+		{"empty", "", execRequest{}, true},
+		{"two requests", "GET /\nGET /_cat/indices\n", execRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExecFile(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExecArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		data        string
+		wantCluster []string
+		want        execRequest
+		wantErr     bool
+	}{
+		{"method and path", []string{"get", "_cat/indices"}, "", nil, execRequest{Method: "GET", Path: "/_cat/indices"}, false},
+		{"cluster first", []string{"production", "GET", "/"}, "", []string{"production"}, execRequest{Method: "GET", Path: "/"}, false},
+		{"inline body", []string{"POST", "/logs/_search"}, `{"size":1}`, nil, execRequest{Method: "POST", Path: "/logs/_search", Body: `{"size":1}`}, false},
+		{"body from stdin", []string{"POST", "/logs/_search"}, "@-", nil, execRequest{Method: "POST", Path: "/logs/_search", Body: "stdin body"}, false},
+		{"missing path", []string{"production"}, "", nil, execRequest{}, true},
+		{"bad method", []string{"production", "FETCH", "/"}, "", nil, execRequest{}, true},
+		{"cluster named like a method", []string{"delete", "GET", "/"}, "", []string{"delete"}, execRequest{Method: "GET", Path: "/"}, false},
+		{"too many arguments", []string{"production", "GET", "/", "extra"}, "", nil, execRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			execDataFlag = tt.data
+			defer func() { execDataFlag = "" }()
+
+			cluster, got, err := parseExecArgs(tt.args, strings.NewReader("stdin body"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(cluster, ",") != strings.Join(tt.wantCluster, ",") {
+				t.Errorf("cluster = %v, want %v", cluster, tt.wantCluster)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExecArgsFromFile(t *testing.T) {
+	execFileFlag = filepath.Join(t.TempDir(), "request.txt")
+	defer func() { execFileFlag = "" }()
+	if err := os.WriteFile(execFileFlag, []byte("GET /{{prefix}}-*/_count\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster, req, err := parseExecArgs([]string{"delete"}, strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(cluster, ",") != "delete" {
+		t.Errorf("with --file the only argument is the cluster, got %v", cluster)
+	}
+	if _, _, err := parseExecArgs([]string{"production", "GET", "/"}, strings.NewReader("")); err == nil {
+		t.Error("--file with METHOD PATH should fail")
+	}
+
+	req, err = expandExecRequest(req, map[string]string{"prefix": "logs-prod"})
+	if err != nil || req.Path != "/logs-prod-*/_count" {
+		t.Errorf("expandExecRequest() = %+v, %v, want the prefix filled in", req, err)
+	}
+}
+
+func TestExpandExecRequest(t *testing.T) {
+	req := execRequest{Method: "POST", Path: "/{{prefix}}/_search/template", Body: `{"source": {"query": {"term": {"user": "{{user}}"}}}, "params": {"user": "{{owner}}"}}`}
+	got, err := expandExecRequest(req, map[string]string{"prefix": "logs", "owner": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := execRequest{Method: "POST", Path: "/logs/_search/template", Body: `{"source": {"query": {"term": {"user": "{{user}}"}}}, "params": {"user": "alice"}}`}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestFormatExecOutput(t *testing.T) {
+	body := "{\n  \"status\": \"green\",\n  \"number_of_nodes\": 3,\n  \"indices\": [\"a\", \"b\"]\n}"
+	raw := `{"status":"green", "number_of_nodes":3,"indices":["a","b"]}`
+	tests := []struct {
+		format string
+		body   string
+		raw    string
+		want   string
+	}{
+		{"json", body, raw, body + "\n"},
+		{"raw", body, raw, raw},
+		{"yaml", body, raw, "status: green\nnumber_of_nodes: 3\nindices:\n  - a\n  - b\n"},
+		{"yaml", "green 3 nodes", "green 3 nodes", "green 3 nodes\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := formatExecOutput(es.RequestResult{Body: tt.body, Raw: []byte(tt.raw)}, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
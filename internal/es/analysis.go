@@ -187,6 +187,31 @@ func (s *ClusterState) GetAliasesForIndex(index string) []string {
 	return aliases
 }
 
+// MatchesIndexFilter reports whether name matches the overview filter: a
+// case-insensitive substring, or a prefix when the filter ends in "*".
+func MatchesIndexFilter(name, filter string) bool {
+	name, filter = strings.ToLower(name), strings.ToLower(filter)
+	if strings.Contains(name, filter) {
+		return true
+	}
+	prefix, ok := strings.CutSuffix(filter, "*")
+	return ok && strings.HasPrefix(name, prefix)
+}
+
+// IndexMatchesFilter is MatchesIndexFilter extended to aliases: an index
+// also matches when one of its aliases is exactly the filter.
+func (s *ClusterState) IndexMatchesFilter(index, filter string) bool {
+	if MatchesIndexFilter(index, filter) {
+		return true
+	}
+	for _, alias := range s.GetAliasesForIndex(index) {
+		if strings.EqualFold(alias, filter) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *ClusterState) GetShardsForIndexAndNode(index, node string) []ShardInfo {
 	var shards []ShardInfo
 	for _, sh := range s.Shards {
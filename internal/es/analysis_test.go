@@ -102,6 +102,31 @@ func TestUniqueAliases(t *testing.T) {
 	}
 }
 
+func TestIndexMatchesFilter(t *testing.T) {
+	s := &ClusterState{Aliases: []AliasInfo{{Alias: "current-logs", Index: "logs-2024.06"}}}
+	tests := []struct {
+		index  string
+		filter string
+		want   bool
+	}{
+		{"logs-2024.06", "2024", true},
+		{"logs-2024.06", "LOGS", true},
+		{"logs-2024.06", "logs*", true},
+		{"logs-2024.06", "metrics*", false},
+		{"logs-2024.06", "current-logs", true},
+		{"logs-2024.06", "current", false},
+		{"metrics", "current-logs", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.index+"/"+tt.filter, func(t *testing.T) {
+			if got := s.IndexMatchesFilter(tt.index, tt.filter); got != tt.want {
+				t.Errorf("IndexMatchesFilter(%q, %q) = %v, want %v", tt.index, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFormatSettingValue(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,99 @@
+package es
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labtiva/stoptail/internal/storage"
+)
+
+// auditTransport records every request to the audit log, whether it came
+// from the workbench or a typed operation. It sits outside the node pool so
+// failover retries appear as a single entry.
+type auditTransport struct {
+	wrapped http.RoundTripper
+	mu      sync.RWMutex
+	log     *storage.AuditLog
+	pending *sync.WaitGroup // requests being recorded to log
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	log, pending := t.log, t.pending
+	if log != nil {
+		pending.Add(1)
+	}
+	t.mu.RUnlock()
+	if log == nil {
+		return t.wrapped.RoundTrip(req)
+	}
+	defer pending.Done()
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.wrapped.RoundTrip(req)
+
+	entry := storage.AuditEntry{
+		Time:       start,
+		Method:     req.Method,
+		Path:       req.URL.RequestURI(),
+		BodySHA256: storage.HashBody(body),
+		BodyBytes:  len(body),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.Status = resp.StatusCode
+	}
+	_ = log.Record(entry)
+
+	return resp, err
+}
+
+// SetAuditLog starts recording every request sent through the client.
+func (c *Client) SetAuditLog(log *storage.AuditLog) {
+	c.audit.mu.Lock()
+	defer c.audit.mu.Unlock()
+	c.audit.log = log
+	c.audit.pending = &sync.WaitGroup{}
+}
+
+// CloseAuditLog stops recording and closes the log once the requests
+// already in flight have been recorded, without waiting for them.
+func (c *Client) CloseAuditLog() {
+	c.audit.mu.Lock()
+	log, pending := c.audit.log, c.audit.pending
+	c.audit.log, c.audit.pending = nil, nil
+	c.audit.mu.Unlock()
+	if log == nil {
+		return
+	}
+	go func() {
+		pending.Wait()
+		log.Close()
+	}()
+}
+
+// AuditLog returns the log requests are recorded to, or nil.
+func (c *Client) AuditLog() *storage.AuditLog {
+	c.audit.mu.RLock()
+	defer c.audit.mu.RUnlock()
+	return c.audit.log
+}
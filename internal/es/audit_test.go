@@ -0,0 +1,107 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/storage"
+)
+
+func TestAuditLogCoversRawAndTypedRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{"acknowledged":true}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	log, err := storage.OpenAuditLog("test")
+	if err != nil {
+		t.Fatalf("OpenAuditLog() error = %v", err)
+	}
+	defer log.Close()
+
+	client, err := NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetAuditLog(log)
+
+	client.Request(context.Background(), "POST", "/logs/_search", `{"size":1}`)
+	if err := client.DeleteIndex(context.Background(), "logs"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+
+	entries := log.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(entries))
+	}
+	if e := entries[0]; e.Method != "POST" || e.Path != "/logs/_search" || e.Status != 200 || e.BodySHA256 != storage.HashBody([]byte(`{"size":1}`)) {
+		t.Errorf("workbench entry = %+v", e)
+	}
+	if e := entries[1]; e.Method != "DELETE" || e.Path != "/logs" || e.Cluster != "test" {
+		t.Errorf("delete index entry = %+v", e)
+	}
+
+	if _, err := os.Stat(filepath.Join(os.Getenv("HOME"), ".stoptail", "audit.log")); err != nil {
+		t.Errorf("audit log not written: %v", err)
+	}
+}
+
+func TestCloseAuditLogWaitsForInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{"errors":false}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	log, err := storage.OpenAuditLog("test")
+	if err != nil {
+		t.Fatalf("OpenAuditLog() error = %v", err)
+	}
+
+	client, err := NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	client.SetAuditLog(log)
+
+	done := make(chan struct{})
+	go func() {
+		client.Request(context.Background(), "POST", "/logs/_bulk", `{"index":{}}`)
+		close(done)
+	}()
+	<-started
+	client.CloseAuditLog()
+	if client.AuditLog() != nil {
+		t.Error("AuditLog() still set after CloseAuditLog()")
+	}
+	close(release)
+	<-done
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, err := os.ReadFile(log.Path())
+		if err != nil {
+			t.Fatalf("reading audit log: %v", err)
+		}
+		if strings.Contains(string(data), "/logs/_bulk") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("in-flight request missing from audit log: %q", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -54,7 +54,7 @@ func (t *sigv4Transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return t.wrapped.RoundTrip(req)
 }
 
-func newAWSTransport(cfg *config.Config) (http.RoundTripper, error) {
+func newAWSTransport(cfg *config.Config, wrapped http.RoundTripper) (http.RoundTripper, error) {
 	ctx := context.Background()
 
 	var opts []func(*awsconfig.LoadOptions) error
@@ -74,7 +74,7 @@ func newAWSTransport(cfg *config.Config) (http.RoundTripper, error) {
 	}
 
 	return &sigv4Transport{
-		wrapped: http.DefaultTransport,
+		wrapped: wrapped,
 		signer:  v4.NewSigner(),
 		creds:   awsCfg.Credentials,
 		region:  cfg.AWSRegion,
@@ -10,6 +10,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
@@ -21,57 +22,86 @@ type Client struct {
 	es         *elasticsearch.Client
 	cfg        *config.Config
 	httpClient *http.Client
+	pool       *nodePool
+	audit      *auditTransport
+	infoMu     sync.RWMutex
+	info       *ServerInfo
 }
 
 func NewClient(cfg *config.Config) (*Client, error) {
 	if cfg.IsAWS() && cfg.IsMTLS() {
 		return nil, fmt.Errorf("cannot use both AWS and mTLS authentication")
 	}
+	if cfg.IsAWS() && cfg.IsTokenAuth() {
+		return nil, fmt.Errorf("cannot use both AWS and API key or bearer token authentication")
+	}
 
 	esCfg := elasticsearch.Config{
 		Addresses: []string{cfg.Host},
 	}
 
+	timeout := cfg.RequestTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultRequestTimeout
+	}
+
 	transport := &http.Transport{
-		ResponseHeaderTimeout: 30 * time.Second,
+		ResponseHeaderTimeout: timeout,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConnsPerHost:   10,
 	}
 
-	if cfg.IsMTLS() {
-		tlsCert, err := tls.X509KeyPair([]byte(cfg.TLSCert), []byte(cfg.TLSKey))
+	if cfg.HasCustomTLS() {
+		tlsConfig, err := newTLSConfig(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("loading mTLS credentials: %w", err)
-		}
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{tlsCert},
-			MinVersion:   tls.VersionTLS12,
-		}
-		if cfg.TLSCA != "" {
-			pool := x509.NewCertPool()
-			if !pool.AppendCertsFromPEM([]byte(cfg.TLSCA)) {
-				return nil, fmt.Errorf("failed to parse CA certificate")
-			}
-			tlsConfig.RootCAs = pool
+			return nil, err
 		}
 		transport.TLSClientConfig = tlsConfig
-		esCfg.Transport = transport
 	}
 
 	var httpTransport http.RoundTripper = transport
 
-	if cfg.IsAWS() {
-		awsTransport, err := newAWSTransport(cfg)
+	if cfg.ReplayDir != "" {
+		replay, err := newReplayTransport(cfg.ReplayDir)
+		if err != nil {
+			return nil, err
+		}
+		httpTransport = replay
+	} else if cfg.IsAWS() {
+		awsTransport, err := newAWSTransport(cfg, transport)
 		if err != nil {
 			return nil, err
 		}
-		esCfg.Transport = awsTransport
 		httpTransport = awsTransport
+	} else if cfg.APIKey != "" {
+		esCfg.APIKey = cfg.APIKey
+	} else if cfg.BearerToken != "" {
+		esCfg.ServiceToken = cfg.BearerToken
 	} else if cfg.Username != "" {
 		esCfg.Username = cfg.Username
 		esCfg.Password = cfg.Password
 	}
 
+	hosts := cfg.Hosts
+	if len(hosts) == 0 {
+		hosts = []string{cfg.Host}
+	}
+	pool := newNodePool(hosts, cfg.NodeSelection)
+	httpTransport = &poolTransport{wrapped: httpTransport, pool: pool}
+	if cfg.RecordDir != "" {
+		record, err := newRecordTransport(cfg.RecordDir, httpTransport)
+		if err != nil {
+			return nil, err
+		}
+		httpTransport = record
+	}
+	audit := &auditTransport{wrapped: httpTransport}
+	httpTransport = audit
+	esCfg.Transport = httpTransport
+	// poolTransport already tries each node once; retrying on top of it
+	// would multiply the attempts.
+	esCfg.DisableRetry = true
+
 	es, err := elasticsearch.NewClient(esCfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating ES client: %w", err)
@@ -80,28 +110,80 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	return &Client{
 		es:         es,
 		cfg:        cfg,
-		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: httpTransport},
+		httpClient: &http.Client{Timeout: timeout, Transport: httpTransport},
+		pool:       pool,
+		audit:      audit,
 	}, nil
 }
 
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         cfg.TLSServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if cfg.IsMTLS() {
+		tlsCert, err := tls.X509KeyPair([]byte(cfg.TLSCert), []byte(cfg.TLSKey))
+		if err != nil {
+			return nil, fmt.Errorf("loading mTLS credentials: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{tlsCert}
+	}
+
+	if cfg.TLSCA != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.TLSCA)) {
+			return nil, fmt.Errorf("failed to parse CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
 func (c *Client) Ping(ctx context.Context) error {
-	res, err := c.es.Info(c.es.Info.WithContext(ctx))
+	// GET / goes through the plain HTTP client so OpenSearch, which fails
+	// the go-elasticsearch product check, can still be identified.
+	req, err := http.NewRequestWithContext(ctx, "GET", c.cfg.Host+"/", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	c.setAuth(req)
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("connecting to ES: %w", err)
 	}
-	defer res.Body.Close()
+	defer resp.Body.Close()
 
-	if res.IsError() {
-		io.Copy(io.Discard, res.Body)
-		return fmt.Errorf("ES error: %s", res.Status())
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading server info: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("ES error: %s", resp.Status)
+	}
+
+	info, err := parseServerInfo(body)
+	if err != nil {
+		return err
+	}
+	c.infoMu.Lock()
+	c.info = info
+	c.infoMu.Unlock()
+
+	if c.cfg.Sniff {
+		// Sniffing is best effort; the configured addresses keep working.
+		_ = c.Sniff(ctx)
 	}
-	io.Copy(io.Discard, res.Body)
 	return nil
 }
 
 type RequestResult struct {
 	StatusCode int
-	Body       string
+	Body       string // pretty-printed when the response is JSON
+	Raw        []byte // the body exactly as the server sent it
 	Duration   time.Duration
 	Error      error
 }
@@ -109,6 +191,12 @@ type RequestResult struct {
 func (c *Client) Request(ctx context.Context, method, path, body string) RequestResult {
 	start := time.Now()
 
+	if IsMutatingRequest(method, path) {
+		if err := c.CheckWritable(method + " " + path); err != nil {
+			return RequestResult{Error: err}
+		}
+	}
+
 	var bodyReader io.Reader
 	if body != "" {
 		bodyReader = strings.NewReader(body)
@@ -123,9 +211,7 @@ func (c *Client) Request(ctx context.Context, method, path, body string) Request
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	if c.cfg.Username != "" {
-		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
-	}
+	c.setAuth(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -143,6 +229,7 @@ func (c *Client) Request(ctx context.Context, method, path, body string) Request
 		return RequestResult{
 			StatusCode: resp.StatusCode,
 			Body:       pretty.String(),
+			Raw:        respBody,
 			Duration:   time.Since(start),
 		}
 	}
@@ -150,10 +237,23 @@ func (c *Client) Request(ctx context.Context, method, path, body string) Request
 	return RequestResult{
 		StatusCode: resp.StatusCode,
 		Body:       string(respBody),
+		Raw:        respBody,
 		Duration:   time.Since(start),
 	}
 }
 
+// setAuth applies the configured credentials to hand-built requests sent
+// through httpClient. AWS requests are signed by the transport instead.
+func (c *Client) setAuth(req *http.Request) {
+	if auth := c.cfg.AuthorizationHeader(); auth != "" {
+		req.Header.Set("Authorization", auth)
+		return
+	}
+	if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+}
+
 func readBody(res *esapi.Response, errContext string) ([]byte, error) {
 	if res.IsError() {
 		body, _ := io.ReadAll(res.Body)
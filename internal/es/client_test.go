@@ -1,7 +1,15 @@
 package es
 
 import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labtiva/stoptail/internal/config"
 )
@@ -21,3 +29,132 @@ func TestNewClient(t *testing.T) {
 		t.Fatal("NewClient() returned nil")
 	}
 }
+
+func TestRequestAuthorization(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"api key", config.Config{APIKey: "abc"}, "ApiKey abc"},
+		{"bearer token", config.Config{BearerToken: "tok"}, "Bearer tok"},
+		{"basic auth", config.Config{Username: "elastic", Password: "test"}, "Basic ZWxhc3RpYzp0ZXN0"},
+		{"none", config.Config{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			cfg := tt.cfg
+			cfg.Host = server.URL
+			client, err := NewClient(&cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			result := client.Request(context.Background(), "GET", "/_cluster/health", "")
+			if result.Error != nil {
+				t.Fatalf("Request() error = %v", result.Error)
+			}
+			if got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClient_AWSWithToken(t *testing.T) {
+	cfg := &config.Config{
+		Host:      "https://search-x.us-east-1.es.amazonaws.com",
+		AWSRegion: "us-east-1",
+		APIKey:    "abc",
+	}
+	if _, err := NewClient(cfg); err == nil {
+		t.Fatal("expected error combining AWS and API key auth")
+	}
+}
+
+func TestNewClient_CustomCA(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{}`))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr bool
+	}{
+		{"untrusted", config.Config{}, true},
+		{"custom ca", config.Config{TLSCA: caPEM}, false},
+		{"insecure skip verify", config.Config{InsecureSkipVerify: true}, false},
+		{"wrong server name", config.Config{TLSCA: caPEM, TLSServerName: "es.invalid"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Host = server.URL
+			client, err := NewClient(&cfg)
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			result := client.Request(context.Background(), "GET", "/", "")
+			if (result.Error != nil) != tt.wantErr {
+				t.Errorf("Request() error = %v, wantErr %v", result.Error, tt.wantErr)
+			}
+			if err := client.Ping(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient_InvalidCA(t *testing.T) {
+	cfg := &config.Config{Host: "https://localhost:9200", TLSCA: "not a pem"}
+	if _, err := NewClient(cfg); err == nil {
+		t.Fatal("expected error for invalid CA")
+	}
+}
+
+func TestRequestTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(&config.Config{Host: server.URL, RequestTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if result := client.Request(context.Background(), "GET", "/_search", ""); result.Error == nil {
+		t.Error("expected timeout error")
+	}
+
+	client, err = NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := client.Request(ctx, "GET", "/_search", ""); !errors.Is(result.Error, context.Canceled) {
+		t.Errorf("Request() error = %v, want context.Canceled", result.Error)
+	}
+}
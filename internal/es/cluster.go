@@ -337,6 +337,10 @@ func flattenSettings(m map[string]any) map[string]string {
 }
 
 func (c *Client) FetchDeprecations(ctx context.Context) (*DeprecationInfo, error) {
+	if info := c.ServerInfo(); !info.SupportsDeprecations() {
+		return nil, fmt.Errorf("%w: %s has no deprecations API", ErrUnsupported, info)
+	}
+
 	res, err := c.es.Migration.Deprecations(c.es.Migration.Deprecations.WithContext(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("fetching deprecations: %w", err)
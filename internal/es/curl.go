@@ -0,0 +1,285 @@
+package es
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// CurlRequest is the request described by a curl command line.
+type CurlRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+// curlValueFlags are curl options that take an argument stoptail has no use
+// for; the argument is skipped along with the flag.
+var curlValueFlags = map[string]bool{
+	"-u": true, "--user": true, "-o": true, "--output": true, "-A": true, "--user-agent": true,
+	"-e": true, "--referer": true, "-b": true, "--cookie": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "--cacert": true, "--cert": true, "--key": true, "-E": true,
+	"--aws-sigv4": true, "-w": true, "--write-out": true, "--resolve": true, "-x": true, "--proxy": true,
+}
+
+// curlShortValueFlags are the single-letter options whose value may be
+// attached, as in -XPOST.
+const curlShortValueFlags = "XdHuoAebmEwx"
+
+// ParseCurl reads the method, path and body from a curl command such as one
+// copied from a ticket or the Elasticsearch docs. Headers and credentials are
+// ignored: requests always go to the connected cluster with its own auth.
+// The URL may start with a shell variable, as in "$ES_URL/_search".
+func ParseCurl(command string) (CurlRequest, error) {
+	args, err := splitShellWords(command)
+	if err != nil {
+		return CurlRequest{}, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return CurlRequest{}, fmt.Errorf("not a curl command")
+	}
+
+	var (
+		method, rawURL string
+		data           strings.Builder
+		hasData, head  bool
+	)
+	// Like curl, repeated data options are joined with &, except that
+	// --json pieces are joined as they are.
+	addData := func(d, sep string) {
+		if hasData {
+			data.WriteString(sep)
+		}
+		data.WriteString(d)
+		hasData = true
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if len(arg) > 2 && arg[0] == '-' && strings.ContainsRune(curlShortValueFlags, rune(arg[1])) {
+			// -XPOST, -d'{...}': the value is attached to the flag.
+			args = slices.Insert(args, i+1, arg[2:])
+			arg = arg[:2]
+		}
+		next := func() string {
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		switch {
+		case arg == "-X" || arg == "--request":
+			method = next()
+		case arg == "-d" || arg == "--data" || arg == "--data-raw" || arg == "--data-binary" ||
+			arg == "--data-ascii" || arg == "--json":
+			d := next()
+			if strings.HasPrefix(d, "@") && arg != "--data-raw" {
+				return CurlRequest{}, fmt.Errorf("curl reads the body from %s; paste the body instead", d[1:])
+			}
+			sep := "&"
+			if arg == "--json" {
+				sep = ""
+			}
+			addData(d, sep)
+		case arg == "--data-urlencode":
+			d, err := curlURLEncode(next())
+			if err != nil {
+				return CurlRequest{}, err
+			}
+			addData(d, "&")
+		case arg == "-I" || arg == "--head":
+			head = true
+		case arg == "--url":
+			rawURL = next()
+		case arg == "-H" || arg == "--header" || curlValueFlags[arg]:
+			next()
+		case strings.HasPrefix(arg, "-"):
+			// Switches such as -s, -k, -v or --compressed.
+		case rawURL == "":
+			rawURL = arg
+		}
+	}
+
+	if rawURL == "" {
+		return CurlRequest{}, fmt.Errorf("curl command has no URL")
+	}
+	path, err := curlPath(rawURL)
+	if err != nil {
+		return CurlRequest{}, err
+	}
+
+	switch {
+	case method != "":
+		method = strings.ToUpper(method)
+	case head:
+		method = "HEAD"
+	case hasData:
+		method = "POST"
+	default:
+		method = "GET"
+	}
+	return CurlRequest{Method: method, Path: path, Body: data.String()}, nil
+}
+
+// curlURLEncode encodes the value of --data-urlencode the way curl does:
+// "content" and "=content" encode content, "name=content" keeps the name
+// and encodes the content.
+func curlURLEncode(d string) (string, error) {
+	i := strings.IndexAny(d, "=@")
+	if i >= 0 && d[i] == '@' {
+		return "", fmt.Errorf("curl reads the body from %s; paste the body instead", d[i+1:])
+	}
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+	switch {
+	case i < 0:
+		return escape(d), nil
+	case i == 0:
+		return escape(d[1:]), nil
+	}
+	return d[:i] + "=" + escape(d[i+1:]), nil
+}
+
+// curlPath returns the path and query of rawURL without the host.
+func curlPath(rawURL string) (string, error) {
+	if strings.HasPrefix(rawURL, "$") {
+		// $ES_URL/_search or ${ES}/_search: the host is the variable.
+		i := strings.Index(rawURL, "/")
+		if i < 0 {
+			return "/", nil
+		}
+		rawURL = "http://host" + rawURL[i:]
+	} else if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL in curl command: %w", err)
+	}
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
+// splitShellWords splits a command line the way a POSIX shell would for the
+// quoting curl snippets use: single and double quotes, backslash escapes and
+// backslash-newline continuations.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+			if r == '\n' {
+				continue
+			}
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			inWord = true
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in curl command", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// CurlCommand renders a request as a curl command against the connected
+// cluster. Secrets are never included: the command reads them from the
+// ES_AUTH environment variable (user:password for basic auth and AWS, the
+// key or token otherwise). The config holds certificates and keys as PEM
+// text, not paths, so curl gets the files from ES_CACERT, ES_CERT and
+// ES_KEY instead.
+func (c *Client) CurlCommand(method, path, body string) string {
+	parts := []string{"curl"}
+	switch method {
+	case "GET":
+	case "HEAD":
+		parts = append(parts, "-I")
+	default:
+		parts = append(parts, "-X", method)
+	}
+	parts = append(parts, shellQuote(c.cfg.Host+path))
+
+	switch {
+	case c.cfg.IsAWS():
+		service := c.cfg.AWSService
+		if service == "" {
+			service = "es"
+		}
+		parts = append(parts, "--aws-sigv4", shellQuote(fmt.Sprintf("aws:amz:%s:%s", c.cfg.AWSRegion, service)), `-u "$ES_AUTH"`)
+	case c.cfg.APIKey != "":
+		parts = append(parts, `-H "Authorization: ApiKey $ES_AUTH"`)
+	case c.cfg.BearerToken != "":
+		parts = append(parts, `-H "Authorization: Bearer $ES_AUTH"`)
+	case c.cfg.Username != "":
+		parts = append(parts, `-u "$ES_AUTH"`)
+	}
+	if c.cfg.TLSCA != "" {
+		parts = append(parts, `--cacert "$ES_CACERT"`)
+	}
+	if c.cfg.IsMTLS() {
+		parts = append(parts, `--cert "$ES_CERT" --key "$ES_KEY"`)
+	}
+	if c.cfg.InsecureSkipVerify {
+		parts = append(parts, "-k")
+	}
+
+	if body = strings.TrimSpace(body); body != "" {
+		parts = append(parts, "-H", shellQuote("Content-Type: application/json"), "-d", shellQuote(body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// shellQuote wraps s in single quotes unless it is made only of characters
+// a shell leaves alone.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:@,+=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,144 @@
+package es
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestParseCurl(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    CurlRequest
+		wantErr bool
+	}{
+		{"plain get", `curl localhost:9200/_cat/indices?v`, CurlRequest{Method: "GET", Path: "/_cat/indices?v"}, false},
+		{"docs style",
+			"curl -X POST \"localhost:9200/logs/_search?pretty\" -H 'Content-Type: application/json' -d'\n{\n  \"query\": { \"match_all\": {} }\n}\n'",
+			CurlRequest{Method: "POST", Path: "/logs/_search?pretty", Body: "\n{\n  \"query\": { \"match_all\": {} }\n}\n"}, false},
+		{"data implies post", `curl -s -k -u elastic:secret https://es.example.com:9200/logs/_count --data-raw '{"query":{}}'`,
+			CurlRequest{Method: "POST", Path: "/logs/_count", Body: `{"query":{}}`}, false},
+		{"joined method and continuation", "curl -XPUT \\\n  \"$ES_URL/logs/_settings\" \\\n  --json '{\"index\":{\"number_of_replicas\":0}}'",
+			CurlRequest{Method: "PUT", Path: "/logs/_settings", Body: `{"index":{"number_of_replicas":0}}`}, false},
+		{"head", `curl -I http://localhost:9200/logs`, CurlRequest{Method: "HEAD", Path: "/logs"}, false},
+		{"escaped quote", `curl -X delete "http://h/it\"s"`, CurlRequest{Method: "DELETE", Path: `/it%22s`}, false},
+		{"body from file", `curl -X POST localhost:9200/_bulk --data-binary @bulk.json`, CurlRequest{}, true},
+		{"repeated data", `curl localhost:9200/_search -d 'a=1' --data b=2 -d'c=3'`, CurlRequest{Method: "POST", Path: "/_search", Body: "a=1&b=2&c=3"}, false},
+		{"repeated json", `curl localhost:9200/_search --json '{"size":' --json '1}'`, CurlRequest{Method: "POST", Path: "/_search", Body: `{"size":1}`}, false},
+		{"data urlencode", `curl localhost:9200/_sql --data-urlencode 'q=a b&c+d' --data-urlencode '=x/y' --data-urlencode plain`,
+			CurlRequest{Method: "POST", Path: "/_sql", Body: "q=a%20b%26c%2Bd&x%2Fy&plain"}, false},
+		{"data urlencode from file", `curl localhost:9200/_sql --data-urlencode q@query.txt`, CurlRequest{}, true},
+		{"not curl", `wget localhost:9200`, CurlRequest{}, true},
+		{"no url", `curl -X GET`, CurlRequest{}, true},
+		{"unterminated", `curl 'localhost:9200`, CurlRequest{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCurl(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCurl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseCurl() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurlCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.Config
+		method string
+		path   string
+		body   string
+		want   string
+	}{
+		{"no auth", config.Config{Host: "http://localhost:9200"}, "GET", "/_cat/indices?v", "",
+			`curl 'http://localhost:9200/_cat/indices?v'`},
+		{"basic auth is masked", config.Config{Host: "https://es:9200", Username: "elastic", Password: "secret"},
+			"POST", "/logs/_search", `{"query": {"term": {"user": "o'neil"}}}`,
+			`curl -X POST https://es:9200/logs/_search -u "$ES_AUTH" -H 'Content-Type: application/json' -d '{"query": {"term": {"user": "o'\''neil"}}}'`},
+		{"api key", config.Config{Host: "https://es:9200", APIKey: "abc", InsecureSkipVerify: true}, "HEAD", "/logs", "",
+			`curl -I https://es:9200/logs -H "Authorization: ApiKey $ES_AUTH" -k`},
+		{"bearer", config.Config{Host: "https://es:9200", BearerToken: "t"}, "DELETE", "/logs", "",
+			`curl -X DELETE https://es:9200/logs -H "Authorization: Bearer $ES_AUTH"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(&tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := client.CurlCommand(tt.method, tt.path, tt.body)
+			if got != tt.want {
+				t.Errorf("CurlCommand() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if tt.cfg.Password != "" && strings.Contains(got, tt.cfg.Password) {
+				t.Error("the command must not contain the password")
+			}
+			if parsed, err := ParseCurl(got); err != nil || parsed.Method != tt.method || parsed.Path != tt.path {
+				t.Errorf("ParseCurl(CurlCommand()) = %+v, %v", parsed, err)
+			}
+		})
+	}
+}
+
+func TestCurlCommandLeavesOutTLSMaterial(t *testing.T) {
+	cert, key := selfSignedPEM(t)
+	client, err := NewClient(&config.Config{Host: "https://es:9200", TLSCA: cert, TLSCert: cert, TLSKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := client.CurlCommand("GET", "/", "")
+	want := `curl https://es:9200/ --cacert "$ES_CACERT" --cert "$ES_CERT" --key "$ES_KEY"`
+	if got != want {
+		t.Errorf("CurlCommand() =\n%s\nwant\n%s", got, want)
+	}
+	for _, pemText := range []string{cert, key} {
+		for _, line := range strings.Split(strings.TrimSpace(pemText), "\n") {
+			if strings.Contains(got, line) {
+				t.Errorf("the command contains PEM line %q", line)
+			}
+		}
+	}
+}
+
+// selfSignedPEM returns a throwaway certificate and its private key.
+func selfSignedPEM(t *testing.T) (cert, key string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "es"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	key = string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return cert, key
+}
@@ -114,7 +114,24 @@ func flattenFields(fields []MappingField) []string {
 	return result
 }
 
-func (c *Client) FetchIndexMappings(ctx context.Context, indexName string) (*IndexMappings, error) {
+// flattenFieldTypes lists every field with its type, objects and
+// multi-fields included.
+func flattenFieldTypes(fields []MappingField) []FieldType {
+	var result []FieldType
+	for _, f := range fields {
+		result = append(result, FieldType{Name: f.Name, Type: f.Type})
+		result = append(result, flattenFieldTypes(f.Children)...)
+	}
+	return result
+}
+
+type mappingsResponse map[string]struct {
+	Mappings struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+	} `json:"mappings"`
+}
+
+func (c *Client) getMappings(ctx context.Context, indexName string) (mappingsResponse, error) {
 	res, err := c.es.Indices.GetMapping(
 		c.es.Indices.GetMapping.WithContext(ctx),
 		c.es.Indices.GetMapping.WithIndex(indexName),
@@ -129,15 +146,18 @@ func (c *Client) FetchIndexMappings(ctx context.Context, indexName string) (*Ind
 		return nil, err
 	}
 
-	var response map[string]struct {
-		Mappings struct {
-			Properties map[string]json.RawMessage `json:"properties"`
-		} `json:"mappings"`
-	}
-
+	var response mappingsResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("parsing mappings: %w", err)
 	}
+	return response, nil
+}
+
+func (c *Client) FetchIndexMappings(ctx context.Context, indexName string) (*IndexMappings, error) {
+	response, err := c.getMappings(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
 
 	result := &IndexMappings{IndexName: indexName}
 	if indexData, ok := response[indexName]; ok {
@@ -148,12 +168,32 @@ func (c *Client) FetchIndexMappings(ctx context.Context, indexName string) (*Ind
 	return result, nil
 }
 
-func (c *Client) FetchMapping(ctx context.Context, index string) ([]string, error) {
-	mappings, err := c.FetchIndexMappings(ctx, index)
+// FetchMapping returns the fields of an index, pattern or alias with their
+// types. When it resolves to several indices, the first index in name
+// order decides the type of a field mapped differently.
+func (c *Client) FetchMapping(ctx context.Context, index string) ([]FieldType, error) {
+	response, err := c.getMappings(ctx, index)
 	if err != nil {
 		return nil, err
 	}
-	return flattenFields(mappings.Fields), nil
+	names := make([]string, 0, len(response))
+	for name := range response {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []FieldType
+	seen := make(map[string]bool)
+	for _, name := range names {
+		for _, f := range flattenFieldTypes(parseMappingProperties(response[name].Mappings.Properties, "")) {
+			if !seen[f.Name] {
+				seen[f.Name] = true
+				fields = append(fields, f)
+			}
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields, nil
 }
 
 func (c *Client) FetchIndexAnalyzers(ctx context.Context, indexName string) ([]AnalyzerInfo, error) {
@@ -246,6 +286,10 @@ func parseAnalyzerInfo(name, kind string, raw json.RawMessage) AnalyzerInfo {
 }
 
 func (c *Client) CreateIndex(ctx context.Context, name string, shards, replicas int) error {
+	if err := c.CheckWritable("create index"); err != nil {
+		return err
+	}
+
 	reqBodyObj := struct {
 		Settings struct {
 			NumberOfShards   int `json:"number_of_shards"`
@@ -269,6 +313,10 @@ func (c *Client) CreateIndex(ctx context.Context, name string, shards, replicas
 }
 
 func (c *Client) DeleteIndex(ctx context.Context, name string) error {
+	if err := c.CheckWritable("delete index"); err != nil {
+		return err
+	}
+
 	res, err := c.es.Indices.Delete(
 		[]string{name},
 		c.es.Indices.Delete.WithContext(ctx),
@@ -282,6 +330,10 @@ func (c *Client) DeleteIndex(ctx context.Context, name string) error {
 }
 
 func (c *Client) OpenIndex(ctx context.Context, name string) error {
+	if err := c.CheckWritable("open index"); err != nil {
+		return err
+	}
+
 	res, err := c.es.Indices.Open(
 		[]string{name},
 		c.es.Indices.Open.WithContext(ctx),
@@ -295,6 +347,10 @@ func (c *Client) OpenIndex(ctx context.Context, name string) error {
 }
 
 func (c *Client) CloseIndex(ctx context.Context, name string) error {
+	if err := c.CheckWritable("close index"); err != nil {
+		return err
+	}
+
 	res, err := c.es.Indices.Close(
 		[]string{name},
 		c.es.Indices.Close.WithContext(ctx),
@@ -308,6 +364,10 @@ func (c *Client) CloseIndex(ctx context.Context, name string) error {
 }
 
 func (c *Client) updateAlias(ctx context.Context, action, index, alias string) error {
+	if err := c.CheckWritable(action + " alias"); err != nil {
+		return err
+	}
+
 	type aliasTarget struct {
 		Index string `json:"index"`
 		Alias string `json:"alias"`
@@ -400,6 +460,10 @@ func parseIndexSettings(indexName string, data []byte) (*IndexSettings, error) {
 }
 
 func (c *Client) FetchIndexTemplates(ctx context.Context) ([]IndexTemplate, error) {
+	if !c.ServerInfo().SupportsComposableTemplates() {
+		return c.fetchLegacyTemplates(ctx)
+	}
+
 	res, err := c.es.Indices.GetIndexTemplate(
 		c.es.Indices.GetIndexTemplate.WithContext(ctx),
 	)
@@ -453,12 +517,63 @@ func (c *Client) FetchIndexTemplates(ctx context.Context) ([]IndexTemplate, erro
 		templates = append(templates, t)
 	}
 
+	sortTemplates(templates)
+	return templates, nil
+}
+
+// fetchLegacyTemplates reads _template for clusters that predate composable
+// templates; order is reported as priority.
+func (c *Client) fetchLegacyTemplates(ctx context.Context) ([]IndexTemplate, error) {
+	res, err := c.es.Indices.GetTemplate(
+		c.es.Indices.GetTemplate.WithContext(ctx),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching index templates: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := readBody(res, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]struct {
+		Order         int      `json:"order"`
+		Version       int      `json:"version"`
+		IndexPatterns []string `json:"index_patterns"`
+		Settings      struct {
+			Index struct {
+				NumberOfShards   string `json:"number_of_shards"`
+				NumberOfReplicas string `json:"number_of_replicas"`
+			} `json:"index"`
+		} `json:"settings"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing templates: %w", err)
+	}
+
+	var templates []IndexTemplate
+	for name, item := range response {
+		templates = append(templates, IndexTemplate{
+			Name:             name,
+			IndexPatterns:    item.IndexPatterns,
+			Priority:         item.Order,
+			Version:          item.Version,
+			NumberOfShards:   item.Settings.Index.NumberOfShards,
+			NumberOfReplicas: item.Settings.Index.NumberOfReplicas,
+		})
+	}
+
+	sortTemplates(templates)
+	return templates, nil
+}
+
+func sortTemplates(templates []IndexTemplate) {
 	sort.Slice(templates, func(i, j int) bool {
 		if templates[i].Priority != templates[j].Priority {
 			return templates[i].Priority > templates[j].Priority
 		}
 		return templates[i].Name < templates[j].Name
 	})
-
-	return templates, nil
 }
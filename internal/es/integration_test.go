@@ -0,0 +1,322 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/estest"
+)
+
+func newTestClient(t *testing.T, srv *estest.Server, cfg config.Config) *Client {
+	t.Helper()
+	cfg.Host = srv.URL
+	client, err := NewClient(&cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+func testFixture() estest.Fixture {
+	docs := make([]map[string]any, 25)
+	for i := range docs {
+		docs[i] = map[string]any{"n": i, "user": map[string]any{"name": []string{"ann", "bob", "cy"}[i%3]}}
+	}
+	return estest.Fixture{
+		Nodes: []estest.Node{{Name: "node-1", Master: true}, {Name: "node-2"}},
+		Indices: []estest.Index{
+			{Name: "logs", Shards: 2, Replicas: 1, Aliases: []string{"current"}, Docs: docs,
+				Mappings: map[string]string{"message": "text", "user.name": "keyword"}},
+			{Name: "metrics", Replicas: 1, States: map[string]string{"0r": "UNASSIGNED"}},
+			{Name: "old", Status: "close"},
+		},
+		Tasks: []estest.Task{
+			{Node: "node-1", ID: 7, Action: "indices:data/write/reindex", Description: "reindex logs", RunningMs: 5000, Cancellable: true},
+			{Node: "node-1", ID: 8, Action: "indices:data/write/bulk[s]", RunningMs: 10},
+		},
+		PendingTasks: []estest.PendingTask{{Priority: "URGENT", Source: "create-index [x]"}},
+	}
+}
+
+func TestIntegrationClusterState(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+	ctx := context.Background()
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := client.ServerInfo().String(); got != "Elasticsearch 8.15.0" {
+		t.Errorf("ServerInfo() = %q", got)
+	}
+
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		t.Fatalf("FetchClusterState() error = %v", err)
+	}
+	if state.Health.Status != "yellow" {
+		t.Errorf("health = %q, want yellow", state.Health.Status)
+	}
+	if len(state.Indices) != 3 || len(state.Nodes) != 2 || len(state.Aliases) != 1 {
+		t.Fatalf("got %d indices, %d nodes, %d aliases", len(state.Indices), len(state.Nodes), len(state.Aliases))
+	}
+	if state.Indices[0].Name != "logs" || state.Indices[0].DocsCount != "25" || state.Indices[0].Version != "8.x" {
+		t.Errorf("logs = %+v", state.Indices[0])
+	}
+	if state.Indices[1].Health != "yellow" {
+		t.Errorf("metrics health = %q, want yellow", state.Indices[1].Health)
+	}
+
+	unassigned := 0
+	for _, s := range state.Shards {
+		if s.State == "UNASSIGNED" {
+			unassigned++
+			if s.Index != "metrics" || s.Node != "" {
+				t.Errorf("unexpected unassigned shard %+v", s)
+			}
+		}
+	}
+	if len(state.Shards) != 6 || unassigned != 1 {
+		t.Errorf("got %d shards with %d unassigned, want 6 and 1", len(state.Shards), unassigned)
+	}
+
+	explain, err := client.FetchAllocationExplain(ctx, "metrics", 0, false)
+	if err != nil {
+		t.Fatalf("FetchAllocationExplain() error = %v", err)
+	}
+	if explain.AllocationStatus != "no" || explain.UnassignedReason != "NODE_LEFT" {
+		t.Errorf("FetchAllocationExplain() = %+v", explain)
+	}
+}
+
+func TestIntegrationSearchDocumentsPaging(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+
+	var seen []string
+	var after []interface{}
+	for page := 0; page < 5; page++ {
+		result, err := client.SearchDocuments(context.Background(), "current", after, 10)
+		if err != nil {
+			t.Fatalf("SearchDocuments() error = %v", err)
+		}
+		if result.Total != 25 {
+			t.Fatalf("Total = %d, want 25", result.Total)
+		}
+		if len(result.Hits) == 0 {
+			break
+		}
+		for _, hit := range result.Hits {
+			seen = append(seen, hit.ID)
+		}
+		after = result.Hits[len(result.Hits)-1].Sort
+	}
+	if len(seen) != 25 || seen[0] != "1" || seen[24] != "25" {
+		t.Errorf("paged through %d docs: %v", len(seen), seen)
+	}
+}
+
+func TestIntegrationMappingsAndSettings(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+	ctx := context.Background()
+
+	fields, err := client.FetchMapping(ctx, "logs")
+	if err != nil {
+		t.Fatalf("FetchMapping() error = %v", err)
+	}
+	if fmt.Sprint(fields) != "[{message text} {user object} {user.name keyword}]" {
+		t.Errorf("FetchMapping() = %v", fields)
+	}
+	if fields, err := client.FetchMapping(ctx, "current"); err != nil || len(fields) != 3 {
+		t.Errorf("FetchMapping(current) = %v, %v; an alias should resolve to its index", fields, err)
+	}
+
+	values, err := client.FetchFieldValues(ctx, "current", "user.name", 2)
+	if err != nil {
+		t.Fatalf("FetchFieldValues() error = %v", err)
+	}
+	if fmt.Sprint(values) != "[{ann 9} {bob 8}]" {
+		t.Errorf("FetchFieldValues() = %v, want the two most common names", values)
+	}
+
+	settings, err := client.FetchIndexSettings(ctx, "logs")
+	if err != nil {
+		t.Fatalf("FetchIndexSettings() error = %v", err)
+	}
+	if settings.NumberOfShards != "2" {
+		t.Errorf("NumberOfShards = %q, want 2", settings.NumberOfShards)
+	}
+
+	if _, err := client.FetchIndexMappings(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "index_not_found_exception") {
+		t.Errorf("FetchIndexMappings(missing) error = %v, want index_not_found_exception", err)
+	}
+}
+
+func TestIntegrationIndexOperations(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+	ctx := context.Background()
+
+	if err := client.CreateIndex(ctx, "events", 3, 0); err != nil {
+		t.Fatalf("CreateIndex() error = %v", err)
+	}
+	if err := client.CreateIndex(ctx, "events", 1, 0); err == nil {
+		t.Error("CreateIndex() on existing index should fail")
+	}
+	if err := client.AddAlias(ctx, "events", "recent"); err != nil {
+		t.Fatalf("AddAlias() error = %v", err)
+	}
+	if err := client.CloseIndex(ctx, "logs"); err != nil {
+		t.Fatalf("CloseIndex() error = %v", err)
+	}
+	if err := client.DeleteIndex(ctx, "old"); err != nil {
+		t.Fatalf("DeleteIndex() error = %v", err)
+	}
+	if err := client.DeleteIndex(ctx, "old"); err == nil {
+		t.Error("DeleteIndex() on missing index should fail")
+	}
+
+	state, err := client.FetchClusterState(ctx)
+	if err != nil {
+		t.Fatalf("FetchClusterState() error = %v", err)
+	}
+	var names []string
+	for _, idx := range state.Indices {
+		names = append(names, idx.Name+":"+idx.Status+":"+idx.Pri)
+	}
+	if got := strings.Join(names, ","); got != "events:open:3,logs:close:2,metrics:open:1" {
+		t.Errorf("indices = %s", got)
+	}
+	if err := client.RemoveAlias(ctx, "events", "recent"); err != nil {
+		t.Errorf("RemoveAlias() error = %v", err)
+	}
+}
+
+func TestIntegrationTasks(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+	ctx := context.Background()
+
+	tasks, err := client.FetchTasks(ctx)
+	if err != nil {
+		t.Fatalf("FetchTasks() error = %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != "node-1:7" {
+		t.Fatalf("FetchTasks() = %+v", tasks)
+	}
+
+	pending, err := client.FetchPendingTasks(ctx)
+	if err != nil {
+		t.Fatalf("FetchPendingTasks() error = %v", err)
+	}
+	if len(pending) != 1 || pending[0].Priority != "URGENT" {
+		t.Errorf("FetchPendingTasks() = %+v", pending)
+	}
+
+	if err := client.CancelTask(ctx, "node-1:7"); err != nil {
+		t.Fatalf("CancelTask() error = %v", err)
+	}
+	if tasks, _ := client.FetchTasks(ctx); len(tasks) != 0 {
+		t.Errorf("task still listed after cancel: %+v", tasks)
+	}
+	if err := client.CancelTask(ctx, "node-1:7"); err == nil {
+		t.Error("CancelTask() on missing task should fail")
+	}
+}
+
+func TestIntegrationNodes(t *testing.T) {
+	srv := estest.NewServer(t, testFixture())
+	client := newTestClient(t, srv, config.Config{})
+	ctx := context.Background()
+
+	pools, err := client.FetchThreadPools(ctx)
+	if err != nil {
+		t.Fatalf("FetchThreadPools() error = %v", err)
+	}
+	if len(pools) == 0 {
+		t.Error("FetchThreadPools() returned nothing")
+	}
+	threads, err := client.FetchHotThreads(ctx)
+	if err != nil {
+		t.Fatalf("FetchHotThreads() error = %v", err)
+	}
+	if !strings.Contains(threads, "node-2") {
+		t.Errorf("FetchHotThreads() = %q", threads)
+	}
+	if err := client.Sniff(ctx); err != nil {
+		t.Fatalf("Sniff() error = %v", err)
+	}
+	if !client.IsMultiNode() {
+		t.Error("IsMultiNode() = false after sniffing two nodes")
+	}
+}
+
+func TestIntegrationAuthAndPermissions(t *testing.T) {
+	f := testFixture()
+	f.Username = "elastic"
+	f.Password = "secret"
+	f.Failures = map[string]int{"DELETE /logs": 403}
+	srv := estest.NewServer(t, f)
+	ctx := context.Background()
+
+	anon := newTestClient(t, srv, config.Config{})
+	if err := anon.Ping(ctx); err == nil {
+		t.Error("Ping() without credentials should fail")
+	}
+
+	client := newTestClient(t, srv, config.Config{Username: "elastic", Password: "secret"})
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	err := client.DeleteIndex(ctx, "logs")
+	if err == nil || !strings.Contains(err.Error(), "security_exception") {
+		t.Errorf("DeleteIndex() error = %v, want security_exception", err)
+	}
+	if got := client.Request(ctx, "DELETE", "/logs", ""); got.StatusCode != 403 {
+		t.Errorf("Request() status = %d, want 403", got.StatusCode)
+	}
+}
+
+func TestIntegrationDistributions(t *testing.T) {
+	tests := []struct {
+		fixture      estest.Fixture
+		want         string
+		deprecations bool
+	}{
+		{estest.Fixture{Version: "8.15.0"}, "Elasticsearch 8.15.0", true},
+		{estest.Fixture{Version: "2.13.0", Distribution: "opensearch"}, "OpenSearch 2.13.0", false},
+		{estest.Fixture{Version: "8.11.0", BuildFlavor: "serverless"}, "Elasticsearch Serverless", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			srv := estest.NewServer(t, tt.fixture)
+			client := newTestClient(t, srv, config.Config{})
+			if err := client.Ping(context.Background()); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			if got := client.ServerInfo().String(); got != tt.want {
+				t.Errorf("ServerInfo() = %q, want %q", got, tt.want)
+			}
+			_, err := client.FetchDeprecations(context.Background())
+			if got := err == nil; got != tt.deprecations {
+				t.Errorf("FetchDeprecations() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestIntegrationRequestLog(t *testing.T) {
+	srv := estest.NewServer(t, estest.Fixture{})
+	client := newTestClient(t, srv, config.Config{})
+	for i := 0; i < 2; i++ {
+		client.Request(context.Background(), "GET", fmt.Sprintf("/_cat/indices?n=%d", i), "")
+	}
+	if got := srv.Requests(); len(got) != 2 || got[0] != "GET /_cat/indices" {
+		t.Errorf("Requests() = %v", got)
+	}
+}
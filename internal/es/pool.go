@@ -0,0 +1,242 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+// nodePool tracks the cluster addresses stoptail may talk to and which one
+// is currently serving requests.
+type nodePool struct {
+	mu         sync.Mutex
+	hosts      []string
+	current    int
+	roundRobin bool
+}
+
+func newNodePool(hosts []string, selection string) *nodePool {
+	return &nodePool{
+		hosts:      hosts,
+		roundRobin: selection == config.NodeSelectionRoundRobin,
+	}
+}
+
+// pick returns the host to use for the next request.
+func (p *nodePool) pick() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.roundRobin && len(p.hosts) > 1 {
+		p.current = (p.current + 1) % len(p.hosts)
+	}
+	return p.hosts[p.current]
+}
+
+// markFailed moves past host if it is still the current one and returns
+// the host to try next.
+func (p *nodePool) markFailed(host string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.hosts[p.current] == host {
+		p.current = (p.current + 1) % len(p.hosts)
+	}
+	return p.hosts[p.current]
+}
+
+func (p *nodePool) currentHost() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.hosts[p.current]
+}
+
+func (p *nodePool) size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.hosts)
+}
+
+func (p *nodePool) setHosts(hosts []string) {
+	if len(hosts) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current := p.hosts[p.current]
+	p.hosts = hosts
+	p.current = 0
+	for i, h := range hosts {
+		if h == current {
+			p.current = i
+			break
+		}
+	}
+}
+
+// poolTransport rewrites every request to the pool's current node and
+// retries on the next node when a connection cannot be made. It wraps the
+// signing transport so AWS signatures cover the rewritten host.
+//
+// GET and HEAD requests are retried after any transport error. Other
+// methods are retried only when the connection was never made, since a
+// POST _bulk the first node received but did not answer would otherwise
+// run twice.
+type poolTransport struct {
+	wrapped http.RoundTripper
+	pool    *nodePool
+}
+
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := t.pool.pick()
+	attempts := t.pool.size()
+
+	for attempt := 1; ; attempt++ {
+		r, err := rewriteHost(req, host)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.wrapped.RoundTrip(r)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= attempts || req.Context().Err() != nil || (req.Body != nil && req.GetBody == nil) || !retryable(req, err) {
+			return nil, err
+		}
+		host = t.pool.markFailed(host)
+	}
+}
+
+// retryable reports whether req may be sent again after err: always for
+// GET and HEAD, and for other methods only if dialing failed, so nothing
+// reached the node.
+func retryable(req *http.Request, err error) bool {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func rewriteHost(req *http.Request, host string) (*http.Request, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node address %q: %w", host, err)
+	}
+
+	r := req.Clone(req.Context())
+	r.URL.Scheme = u.Scheme
+	r.URL.Host = u.Host
+	r.Host = u.Host
+	if req.GetBody != nil && req.Body != nil && req.Body != http.NoBody {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewinding request body: %w", err)
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// CurrentNode returns the host:port of the node currently serving requests.
+func (c *Client) CurrentNode() string {
+	u, err := url.Parse(c.pool.currentHost())
+	if err != nil {
+		return c.pool.currentHost()
+	}
+	return u.Host
+}
+
+// IsMultiNode reports whether requests may be spread across several nodes.
+func (c *Client) IsMultiNode() bool {
+	return c.pool.size() > 1 || c.cfg.Sniff
+}
+
+// Sniff replaces the node pool with the HTTP publish addresses reported by
+// _nodes/http, keeping the configured scheme.
+func (c *Client) Sniff(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.cfg.Host+"/_nodes/http", nil)
+	if err != nil {
+		return fmt.Errorf("creating sniff request: %w", err)
+	}
+	c.setAuth(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sniffing nodes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading sniff response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("ES error %s: %s", resp.Status, string(body))
+	}
+
+	scheme := "http"
+	if u, err := url.Parse(c.cfg.Host); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+
+	hosts, err := parseSniffResponse(body, scheme)
+	if err != nil {
+		return err
+	}
+	c.pool.setHosts(hosts)
+	return nil
+}
+
+func parseSniffResponse(data []byte, scheme string) ([]string, error) {
+	var response struct {
+		Nodes map[string]struct {
+			Roles []string `json:"roles"`
+			HTTP  struct {
+				PublishAddress string `json:"publish_address"`
+			} `json:"http"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("parsing sniff response: %w", err)
+	}
+
+	var hosts []string
+	for _, node := range response.Nodes {
+		if isMasterOnly(node.Roles) || node.HTTP.PublishAddress == "" {
+			continue
+		}
+		hosts = append(hosts, scheme+"://"+publishHost(node.HTTP.PublishAddress))
+	}
+	sort.Strings(hosts)
+	return hosts, nil
+}
+
+// publishHost converts "hostname/1.2.3.4:9200" into "hostname:9200" so TLS
+// verification sees the node's name; bare "1.2.3.4:9200" is returned as is.
+func publishHost(addr string) string {
+	name, ipPort, ok := strings.Cut(addr, "/")
+	if !ok {
+		return addr
+	}
+	if name == "" {
+		return ipPort
+	}
+	if i := strings.LastIndex(ipPort, ":"); i >= 0 {
+		return name + ipPort[i:]
+	}
+	return name
+}
+
+func isMasterOnly(roles []string) bool {
+	return len(roles) == 1 && roles[0] == "master"
+}
@@ -0,0 +1,208 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestNodePool_Failover(t *testing.T) {
+	var hits int
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer live.Close()
+
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	deadURL := dead.URL
+	dead.Close()
+
+	cfg, err := config.ParseURLs([]string{deadURL, live.URL})
+	if err != nil {
+		t.Fatalf("ParseURLs() error = %v", err)
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	result := client.Request(context.Background(), "POST", "/_search", `{"query":{"match_all":{}}}`)
+	if result.Error != nil {
+		t.Fatalf("Request() error = %v", result.Error)
+	}
+	if hits != 1 {
+		t.Errorf("live node hits = %d, want 1", hits)
+	}
+	if got, want := client.CurrentNode(), live.Listener.Addr().String(); got != want {
+		t.Errorf("CurrentNode() = %q, want %q", got, want)
+	}
+
+	client.Request(context.Background(), "GET", "/", "")
+	if hits != 2 {
+		t.Errorf("failover should stick to the live node, hits = %d", hits)
+	}
+}
+
+func TestNodePool_NoRetryAfterSend(t *testing.T) {
+	var received, retried atomic.Int32
+	// The first node reads the request and drops the connection without
+	// answering, so it may well have run it.
+	first := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer first.Close()
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retried.Add(1)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer second.Close()
+
+	cfg, _ := config.ParseURLs([]string{first.URL, second.URL})
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	result := client.Request(context.Background(), "POST", "/logs/_bulk", "{\"index\":{}}\n{\"n\":1}\n")
+	if result.Error == nil {
+		t.Error("POST should fail when the node drops the connection")
+	}
+	if received.Load() != 1 || retried.Load() != 0 {
+		t.Errorf("POST sent %d times to the first node and %d to the second, want 1 and 0", received.Load(), retried.Load())
+	}
+
+	if result := client.Request(context.Background(), "GET", "/_cluster/health", ""); result.Error != nil {
+		t.Fatalf("GET error = %v, want it retried on the next node", result.Error)
+	}
+	if retried.Load() != 1 {
+		t.Errorf("GET reached the second node %d times, want 1", retried.Load())
+	}
+}
+
+func TestNodePool_TriesEachNodeOnce(t *testing.T) {
+	var hits atomic.Int32
+	drop := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	})
+	first := httptest.NewServer(drop)
+	defer first.Close()
+	second := httptest.NewServer(drop)
+	defer second.Close()
+
+	cfg, _ := config.ParseURLs([]string{first.URL, second.URL})
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	// The typed API goes through the go-elasticsearch transport, which
+	// must not retry on top of the pool.
+	if _, err := client.es.Info(); err == nil {
+		t.Fatal("GET should fail when every node drops the connection")
+	}
+	if hits.Load() != 2 {
+		t.Errorf("GET was sent %d times, want once per node", hits.Load())
+	}
+}
+
+func TestNodePool_RoundRobin(t *testing.T) {
+	pool := newNodePool([]string{"http://a:9200", "http://b:9200", "http://c:9200"}, config.NodeSelectionRoundRobin)
+	var got []string
+	for range 4 {
+		got = append(got, pool.pick())
+	}
+	want := []string{"http://b:9200", "http://c:9200", "http://a:9200", "http://b:9200"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pick() #%d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNodePool_SetHostsKeepsCurrent(t *testing.T) {
+	pool := newNodePool([]string{"http://a:9200", "http://b:9200"}, config.NodeSelectionFailover)
+	pool.markFailed("http://a:9200")
+	pool.setHosts([]string{"http://b:9200", "http://c:9200", "http://d:9200"})
+	if got := pool.currentHost(); got != "http://b:9200" {
+		t.Errorf("currentHost() = %q, want %q", got, "http://b:9200")
+	}
+	pool.setHosts(nil)
+	if got := pool.size(); got != 3 {
+		t.Errorf("size() after empty setHosts = %d, want 3", got)
+	}
+}
+
+func TestParseSniffResponse(t *testing.T) {
+	raw := `{"nodes":{
+		"a":{"roles":["data","ingest"],"http":{"publish_address":"es-data-1/10.0.0.1:9200"}},
+		"b":{"roles":["master"],"http":{"publish_address":"10.0.0.2:9200"}},
+		"c":{"roles":["data"],"http":{"publish_address":"10.0.0.3:9200"}},
+		"d":{"roles":["data"],"http":{}}
+	}}`
+
+	hosts, err := parseSniffResponse([]byte(raw), "https")
+	if err != nil {
+		t.Fatalf("parseSniffResponse() error = %v", err)
+	}
+	want := []string{"https://10.0.0.3:9200", "https://es-data-1:9200"}
+	if len(hosts) != len(want) {
+		t.Fatalf("got %v, want %v", hosts, want)
+	}
+	for i := range want {
+		if hosts[i] != want[i] {
+			t.Errorf("hosts[%d] = %q, want %q", i, hosts[i], want[i])
+		}
+	}
+}
+
+func TestPublishHost(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"10.0.0.1:9200", "10.0.0.1:9200"},
+		{"node-1/10.0.0.1:9200", "node-1:9200"},
+		{"/10.0.0.1:9200", "10.0.0.1:9200"},
+		{"node-1/[::1]:9200", "node-1:9200"},
+	}
+	for _, tt := range tests {
+		if got := publishHost(tt.in); got != tt.want {
+			t.Errorf("publishHost(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Sniff(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_nodes/http" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		addr := server.Listener.Addr().String()
+		w.Write([]byte(`{"nodes":{"a":{"roles":["data"],"http":{"publish_address":"` + addr + `"}}}}`))
+	}))
+	defer server.Close()
+
+	cfg, _ := config.ParseURLs([]string{server.URL, "http://127.0.0.1:1"})
+	cfg.Sniff = true
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := client.Sniff(context.Background()); err != nil {
+		t.Fatalf("Sniff() error = %v", err)
+	}
+	if got := client.pool.size(); got != 1 {
+		t.Errorf("pool size = %d, want 1", got)
+	}
+	if !client.IsMultiNode() {
+		t.Error("IsMultiNode() = false, want true when sniffing")
+	}
+}
@@ -0,0 +1,95 @@
+package es
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrReadOnly = errors.New("cluster is in read-only mode")
+
+// readEndpoints are path segments whose POST requests only read data, so they
+// stay available in read-only mode.
+var readEndpoints = map[string]bool{
+	"_search":       true,
+	"_msearch":      true,
+	"_async_search": true,
+	"_count":        true,
+	"_query":        true,
+	"_validate":     true,
+	"_analyze":      true,
+	"_field_caps":   true,
+	"_explain":      true,
+	"_mget":         true,
+	"_termvectors":  true,
+	"_mtermvectors": true,
+	"_terms_enum":   true,
+	"_knn_search":   true,
+	"_rank_eval":    true,
+	"_render":       true,
+	"_sql":          true,
+	"_eql":          true,
+	"_pit":          true,
+}
+
+// cleanupEndpoints release server-side search state; deleting them changes
+// no data.
+var cleanupEndpoints = map[string]bool{
+	"_search":       true,
+	"_async_search": true,
+	"_pit":          true,
+}
+
+// IsMutatingRequest reports whether a raw REST request may change cluster
+// state. GET and HEAD never do; POST is allowed for known read endpoints.
+func IsMutatingRequest(method, path string) bool {
+	method = strings.ToUpper(method)
+	if method == "GET" || method == "HEAD" {
+		return false
+	}
+
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch method {
+	case "POST":
+		if strings.Join(segments, "/") == "_cluster/allocation/explain" {
+			return false
+		}
+		if readEndpoints[apiEndpoint(segments)] {
+			return false
+		}
+	case "DELETE":
+		if cleanupEndpoints[segments[0]] {
+			return false
+		}
+	}
+	return true
+}
+
+// apiEndpoint returns the segment that names the API a path calls: the
+// first one in /_search, the one after the index in /logs/_search. Later
+// segments are ids chosen by the user, so a document stored as
+// /logs/_doc/_count must not pass for a count.
+func apiEndpoint(segments []string) string {
+	if segments[0] != "_all" && strings.HasPrefix(segments[0], "_") {
+		return segments[0]
+	}
+	if len(segments) > 1 {
+		return segments[1]
+	}
+	return ""
+}
+
+func (c *Client) ReadOnly() bool {
+	return c.cfg.ReadOnly
+}
+
+// CheckWritable returns an error wrapping ErrReadOnly when the client is in
+// read-only mode.
+func (c *Client) CheckWritable(action string) error {
+	if c.cfg.ReadOnly {
+		return fmt.Errorf("%w: %s refused", ErrReadOnly, action)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestIsMutatingRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{"GET", "/_cluster/health", false},
+		{"HEAD", "/logs", false},
+		{"get", "/logs/_doc/1", false},
+		{"POST", "/logs/_search", false},
+		{"POST", "/_search/scroll", false},
+		{"POST", "/logs/_count?q=x", false},
+		{"POST", "/_query", false},
+		{"POST", "/logs/_pit?keep_alive=1m", false},
+		{"POST", "/_cluster/allocation/explain", false},
+		{"POST", "/_all/_search", false},
+		{"POST", "/logs/_search/template", false},
+		{"POST", "/_render/template", false},
+		{"DELETE", "/_search/scroll", false},
+		{"DELETE", "/_pit", false},
+		{"POST", "/logs/_doc", true},
+		{"POST", "/logs/_doc/_count", true},
+		{"POST", "/logs/_doc/_search", true},
+		{"PUT", "/logs/_create/_explain", true},
+		{"POST", "/_scripts/_search", true},
+		{"POST", "/_scripts/_render", true},
+		{"POST", "/_ingest/pipeline/_cluster/allocation/explain", true},
+		{"POST", "/logs/_update_by_query", true},
+		{"POST", "/logs/_delete_by_query", true},
+		{"POST", "/_bulk", true},
+		{"PUT", "/logs", true},
+		{"DELETE", "/logs", true},
+		{"POST", "/logs/_close", true},
+		{"PUT", "/_cluster/settings", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if got := IsMutatingRequest(tt.method, tt.path); got != tt.want {
+				t.Errorf("IsMutatingRequest(%q, %q) = %v, want %v", tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadOnlyRefusesWrites(t *testing.T) {
+	var hits int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{Host: server.URL, ReadOnly: true})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	result := client.Request(context.Background(), "PUT", "/logs", "")
+	if !errors.Is(result.Error, ErrReadOnly) {
+		t.Errorf("Request(PUT) error = %v, want ErrReadOnly", result.Error)
+	}
+	if err := client.DeleteIndex(context.Background(), "logs"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DeleteIndex() error = %v, want ErrReadOnly", err)
+	}
+	if hits != 0 {
+		t.Errorf("server received %d requests, want 0", hits)
+	}
+
+	result = client.Request(context.Background(), "POST", "/logs/_search", `{}`)
+	if result.Error != nil {
+		t.Errorf("Request(POST _search) error = %v, want nil", result.Error)
+	}
+	if hits != 1 {
+		t.Errorf("server received %d requests, want 1", hits)
+	}
+}
@@ -0,0 +1,205 @@
+package es
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// exchange is one recorded request and response. Request headers are never
+// stored, so recordings carry no credentials.
+type exchange struct {
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Body     string      `json:"body,omitempty"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header,omitempty"`
+	Response string      `json:"response"`
+}
+
+// recordedHeaders are the response headers worth replaying; everything
+// else (cookies, auth challenges) is dropped.
+var recordedHeaders = []string{"Content-Type", "X-Elastic-Product", "Warning"}
+
+// recordTransport writes every exchange to dir as NNNN.json.
+type recordTransport struct {
+	wrapped http.RoundTripper
+	dir     string
+	mu      sync.Mutex
+	seq     int
+}
+
+func newRecordTransport(dir string, wrapped http.RoundTripper) (*recordTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating record dir: %w", err)
+	}
+	existing, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	return &recordTransport{wrapped: wrapped, dir: dir, seq: len(existing)}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(reqBody)), nil
+		}
+	}
+
+	resp, err := t.wrapped.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	ex := exchange{
+		Method:   req.Method,
+		Path:     req.URL.RequestURI(),
+		Body:     string(reqBody),
+		Status:   resp.StatusCode,
+		Header:   http.Header{},
+		Response: string(respBody),
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Values(h); len(v) > 0 {
+			ex.Header[h] = v
+		}
+	}
+
+	if err := t.write(ex); err != nil {
+		return nil, fmt.Errorf("recording exchange: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) write(ex exchange) error {
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	return os.WriteFile(filepath.Join(t.dir, fmt.Sprintf("%04d.json", t.seq)), data, 0644)
+}
+
+// replayTransport answers requests from a recording without touching the
+// network. Requests match on method, path and body, falling back to method
+// and path; repeated matches are served in recorded order and the last one
+// is reused once they run out.
+type replayTransport struct {
+	mu        sync.Mutex
+	exchanges map[string][]exchange
+	served    map[string]int
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading replay dir: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges in %s", dir)
+	}
+	sort.Strings(files)
+
+	t := &replayTransport{exchanges: map[string][]exchange{}, served: map[string]int{}}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f, err)
+		}
+		var ex exchange
+		if err := json.Unmarshal(data, &ex); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f, err)
+		}
+		for _, key := range replayKeys(ex.Method, ex.Path, ex.Body) {
+			t.exchanges[key] = append(t.exchanges[key], ex)
+		}
+	}
+	return t, nil
+}
+
+func replayKeys(method, path, body string) []string {
+	base := strings.ToUpper(method) + " " + path
+	return []string{base + "\n" + body, base}
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ex, ok := t.lookup(req.Method, req.URL.RequestURI(), string(body))
+	if !ok {
+		var msg bytes.Buffer
+		enc := json.NewEncoder(&msg)
+		enc.SetEscapeHTML(false)
+		enc.Encode(map[string]any{
+			"error":  fmt.Sprintf("no recorded response for %s %s", req.Method, req.URL.RequestURI()),
+			"status": http.StatusNotFound,
+		})
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Status:     "404 Not Found",
+			Header:     http.Header{"Content-Type": {"application/json"}, "X-Elastic-Product": {"Elasticsearch"}},
+			Body:       io.NopCloser(&msg),
+			Request:    req,
+		}, nil
+	}
+
+	header := http.Header{}
+	for k, v := range ex.Header {
+		header[k] = v
+	}
+	return &http.Response{
+		StatusCode:    ex.Status,
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(ex.Response)),
+		ContentLength: int64(len(ex.Response)),
+		Request:       req,
+	}, nil
+}
+
+func (t *replayTransport) lookup(method, path, body string) (exchange, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, key := range replayKeys(method, path, body) {
+		list := t.exchanges[key]
+		if len(list) == 0 {
+			continue
+		}
+		i := min(t.served[key], len(list)-1)
+		t.served[key]++
+		return list[i], true
+	}
+	return exchange{}, false
+}
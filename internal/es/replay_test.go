@@ -0,0 +1,95 @@
+package es
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	searches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"cluster_name":"rec","version":{"number":"8.15.0"}}`))
+		case "/logs/_search":
+			searches++
+			if strings.Contains(r.URL.RawQuery, "size=1") {
+				w.Write([]byte(`{"hits":{"total":{"value":1}}}`))
+				return
+			}
+			w.Write([]byte(`{"hits":{"total":{"value":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "session")
+	recorder, err := NewClient(&config.Config{Host: server.URL, Username: "elastic", Password: "hunter2", RecordDir: dir})
+	if err != nil {
+		t.Fatalf("NewClient(record) error = %v", err)
+	}
+	if err := recorder.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	recorded := recorder.Request(context.Background(), "POST", "/logs/_search", `{"query":{"match_all":{}}}`)
+	if recorded.Error != nil {
+		t.Fatalf("Request() error = %v", recorded.Error)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("recorded %d exchanges, want 2", len(files))
+	}
+	for _, f := range files {
+		data, _ := os.ReadFile(f)
+		if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "Basic ") || strings.Contains(string(data), "secret") {
+			t.Errorf("%s contains credentials: %s", f, data)
+		}
+	}
+
+	server.Close()
+
+	replayer, err := NewClient(&config.Config{Host: "http://localhost:1", ReplayDir: dir})
+	if err != nil {
+		t.Fatalf("NewClient(replay) error = %v", err)
+	}
+	if err := replayer.Ping(context.Background()); err != nil {
+		t.Fatalf("replayed Ping() error = %v", err)
+	}
+	if got := replayer.ServerInfo().Version; got != "8.15.0" {
+		t.Errorf("replayed version = %q, want 8.15.0", got)
+	}
+
+	replayed := replayer.Request(context.Background(), "POST", "/logs/_search", `{"query":{"match_all":{}}}`)
+	if replayed.Error != nil || replayed.Body != recorded.Body {
+		t.Errorf("replayed = %q (%v), want %q", replayed.Body, replayed.Error, recorded.Body)
+	}
+
+	// A different body still matches on method and path.
+	if r := replayer.Request(context.Background(), "POST", "/logs/_search", `{"size":0}`); r.StatusCode != 200 {
+		t.Errorf("fallback match status = %d, want 200", r.StatusCode)
+	}
+
+	if r := replayer.Request(context.Background(), "GET", "/_cat/nodes", ""); r.StatusCode != http.StatusNotFound {
+		t.Errorf("unrecorded request status = %d, want 404", r.StatusCode)
+	}
+	if searches != 1 {
+		t.Errorf("server saw %d searches, want 1", searches)
+	}
+}
+
+func TestReplayEmptyDir(t *testing.T) {
+	if _, err := NewClient(&config.Config{Host: "http://localhost:9200", ReplayDir: t.TempDir()}); err == nil {
+		t.Fatal("expected error for a replay dir without recordings")
+	}
+}
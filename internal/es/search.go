@@ -5,6 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+)
+
+// Field value suggestions run while the user types, so the aggregation
+// behind them only looks at the first fieldValueSample documents of each
+// shard and gives up after fieldValueTimeout.
+const (
+	fieldValueSample  = 10000
+	fieldValueTimeout = 5 * time.Second
 )
 
 func (c *Client) SearchDocuments(ctx context.Context, index string, after []interface{}, size int) (*SearchResult, error) {
@@ -73,3 +82,65 @@ func (c *Client) SearchDocuments(ctx context.Context, index string, after []inte
 
 	return result, nil
 }
+
+// FetchFieldValues returns the most common values of a field, most
+// frequent first, from a terms aggregation over a sample of the index.
+func (c *Client) FetchFieldValues(ctx context.Context, index, field string, size int) ([]FieldValue, error) {
+	ctx, cancel := context.WithTimeout(ctx, fieldValueTimeout)
+	defer cancel()
+
+	query := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"values": map[string]interface{}{
+				"terms": map[string]interface{}{"field": field, "size": size},
+			},
+		},
+	}
+	queryBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling query: %w", err)
+	}
+
+	res, err := c.es.Search(
+		c.es.Search.WithContext(ctx),
+		c.es.Search.WithIndex(index),
+		c.es.Search.WithBody(bytes.NewReader(queryBytes)),
+		c.es.Search.WithTerminateAfter(fieldValueSample),
+		c.es.Search.WithTimeout(fieldValueTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching field values: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := readBody(res, "field values")
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Aggregations struct {
+			Values struct {
+				Buckets []struct {
+					Key         interface{} `json:"key"`
+					KeyAsString string      `json:"key_as_string"`
+					DocCount    int64       `json:"doc_count"`
+				} `json:"buckets"`
+			} `json:"values"`
+		} `json:"aggregations"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("parsing field values: %w", err)
+	}
+
+	values := make([]FieldValue, 0, len(response.Aggregations.Values.Buckets))
+	for _, b := range response.Aggregations.Values.Buckets {
+		value := b.KeyAsString
+		if value == "" {
+			value = fmt.Sprint(b.Key)
+		}
+		values = append(values, FieldValue{Value: value, Count: b.DocCount})
+	}
+	return values, nil
+}
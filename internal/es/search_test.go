@@ -0,0 +1,41 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestFetchFieldValuesIsBounded(t *testing.T) {
+	var query, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		query, body = r.URL.RawQuery, string(data)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Write([]byte(`{"aggregations": {"values": {"buckets": [{"key": "ann", "doc_count": 3}, {"key": 7, "doc_count": 1}]}}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := client.FetchFieldValues(context.Background(), "logs", "user", 5)
+	if err != nil {
+		t.Fatalf("FetchFieldValues() error = %v", err)
+	}
+	if fmt.Sprint(values) != "[{ann 3} {7 1}]" {
+		t.Errorf("values = %v", values)
+	}
+	if want := "terminate_after=10000&timeout=5000ms"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if want := `{"aggs":{"values":{"terms":{"field":"user","size":5}}},"size":0}`; body != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+}
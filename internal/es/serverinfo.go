@@ -0,0 +1,116 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned by operations the connected server does not
+// offer, so callers can hide the feature instead of showing a raw ES error.
+var ErrUnsupported = errors.New("not supported by this server")
+
+type Flavor string
+
+const (
+	FlavorElasticsearch Flavor = "Elasticsearch"
+	FlavorOpenSearch    Flavor = "OpenSearch"
+	FlavorServerless    Flavor = "Elasticsearch Serverless"
+)
+
+// ServerInfo describes the server stoptail is connected to, as reported by
+// GET /.
+type ServerInfo struct {
+	Flavor      Flavor
+	Version     string
+	BuildFlavor string
+	ClusterName string
+	Major       int
+	Minor       int
+}
+
+func parseServerInfo(data []byte) (*ServerInfo, error) {
+	var response struct {
+		ClusterName string `json:"cluster_name"`
+		Version     struct {
+			Number       string `json:"number"`
+			Distribution string `json:"distribution"`
+			BuildFlavor  string `json:"build_flavor"`
+		} `json:"version"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("parsing server info: %w", err)
+	}
+
+	info := &ServerInfo{
+		Flavor:      FlavorElasticsearch,
+		Version:     response.Version.Number,
+		BuildFlavor: response.Version.BuildFlavor,
+		ClusterName: response.ClusterName,
+	}
+	switch {
+	case response.Version.Distribution == "opensearch":
+		info.Flavor = FlavorOpenSearch
+	case response.Version.BuildFlavor == "serverless":
+		info.Flavor = FlavorServerless
+	}
+
+	// "8.15.0-SNAPSHOT" and "7.10.2" both yield major.minor.
+	parts := strings.SplitN(strings.SplitN(info.Version, "-", 2)[0], ".", 3)
+	info.Major, _ = strconv.Atoi(parts[0])
+	if len(parts) > 1 {
+		info.Minor, _ = strconv.Atoi(parts[1])
+	}
+	return info, nil
+}
+
+func (s *ServerInfo) String() string {
+	if s.Flavor == FlavorServerless || s.Version == "" {
+		return string(s.Flavor)
+	}
+	return string(s.Flavor) + " " + s.Version
+}
+
+// AtLeast reports whether the server version is major.minor or newer.
+func (s *ServerInfo) AtLeast(major, minor int) bool {
+	return s.Major > major || (s.Major == major && s.Minor >= minor)
+}
+
+// SupportsESQL reports whether the _query endpoint is available. A nil
+// ServerInfo (not yet connected) supports everything.
+func (s *ServerInfo) SupportsESQL() bool {
+	if s == nil {
+		return true
+	}
+	switch s.Flavor {
+	case FlavorOpenSearch:
+		return false
+	case FlavorServerless:
+		return true
+	}
+	return s.AtLeast(8, 11)
+}
+
+// SupportsDeprecations reports whether _migration/deprecations is available.
+func (s *ServerInfo) SupportsDeprecations() bool {
+	return s == nil || s.Flavor == FlavorElasticsearch
+}
+
+// SupportsComposableTemplates reports whether _index_template is available;
+// older clusters only have legacy _template.
+func (s *ServerInfo) SupportsComposableTemplates() bool {
+	if s == nil || s.Flavor != FlavorElasticsearch {
+		return true
+	}
+	return s.AtLeast(7, 8)
+}
+
+// ServerInfo returns what the server reported on connect, or nil before a
+// successful Ping.
+func (c *Client) ServerInfo() *ServerInfo {
+	c.infoMu.RLock()
+	defer c.infoMu.RUnlock()
+	return c.info
+}
@@ -0,0 +1,140 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+func TestParseServerInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		flavor     Flavor
+		major      int
+		minor      int
+		esql       bool
+		deprecate  bool
+		composable bool
+	}{
+		{
+			name:   "elasticsearch 8",
+			body:   `{"cluster_name":"prod","version":{"number":"8.15.0","build_flavor":"default"}}`,
+			flavor: FlavorElasticsearch, major: 8, minor: 15,
+			esql: true, deprecate: true, composable: true,
+		},
+		{
+			name:   "elasticsearch 7.6",
+			body:   `{"version":{"number":"7.6.2","build_flavor":"oss"}}`,
+			flavor: FlavorElasticsearch, major: 7, minor: 6,
+			esql: false, deprecate: true, composable: false,
+		},
+		{
+			name:   "elasticsearch 8.10 snapshot",
+			body:   `{"version":{"number":"8.10.0-SNAPSHOT"}}`,
+			flavor: FlavorElasticsearch, major: 8, minor: 10,
+			esql: false, deprecate: true, composable: true,
+		},
+		{
+			name:   "opensearch",
+			body:   `{"version":{"distribution":"opensearch","number":"2.11.0"}}`,
+			flavor: FlavorOpenSearch, major: 2, minor: 11,
+			esql: false, deprecate: false, composable: true,
+		},
+		{
+			name:   "serverless",
+			body:   `{"version":{"number":"8.11.0","build_flavor":"serverless"}}`,
+			flavor: FlavorServerless, major: 8, minor: 11,
+			esql: true, deprecate: false, composable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseServerInfo([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("parseServerInfo() error = %v", err)
+			}
+			if info.Flavor != tt.flavor || info.Major != tt.major || info.Minor != tt.minor {
+				t.Errorf("got %s %d.%d, want %s %d.%d", info.Flavor, info.Major, info.Minor, tt.flavor, tt.major, tt.minor)
+			}
+			if got := info.SupportsESQL(); got != tt.esql {
+				t.Errorf("SupportsESQL() = %v, want %v", got, tt.esql)
+			}
+			if got := info.SupportsDeprecations(); got != tt.deprecate {
+				t.Errorf("SupportsDeprecations() = %v, want %v", got, tt.deprecate)
+			}
+			if got := info.SupportsComposableTemplates(); got != tt.composable {
+				t.Errorf("SupportsComposableTemplates() = %v, want %v", got, tt.composable)
+			}
+		})
+	}
+}
+
+func TestServerInfoNil(t *testing.T) {
+	var info *ServerInfo
+	if !info.SupportsESQL() || !info.SupportsDeprecations() || !info.SupportsComposableTemplates() {
+		t.Error("nil ServerInfo should not hide features")
+	}
+}
+
+func TestPingDetectsOpenSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cluster_name":"os","version":{"distribution":"opensearch","number":"2.11.0"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.ServerInfo() != nil {
+		t.Error("ServerInfo() before Ping should be nil")
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+	if got := client.ServerInfo().String(); got != "OpenSearch 2.11.0" {
+		t.Errorf("ServerInfo() = %q, want %q", got, "OpenSearch 2.11.0")
+	}
+	if _, err := client.FetchDeprecations(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("FetchDeprecations() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestFetchIndexTemplatesLegacy(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`{"version":{"number":"7.6.2"}}`))
+		case "/_template":
+			w.Write([]byte(`{"logs":{"order":2,"index_patterns":["logs-*"],"settings":{"index":{"number_of_shards":"3"}}},"base":{"order":0,"index_patterns":["*"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if err := client.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	templates, err := client.FetchIndexTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("FetchIndexTemplates() error = %v (paths %v)", err, paths)
+	}
+	if len(templates) != 2 || templates[0].Name != "logs" || templates[0].Priority != 2 || templates[0].NumberOfShards != "3" {
+		t.Errorf("FetchIndexTemplates() = %+v", templates)
+	}
+}
@@ -28,6 +28,10 @@ func (c *Client) FetchTasks(ctx context.Context) ([]TaskInfo, error) {
 }
 
 func (c *Client) CancelTask(ctx context.Context, taskID string) error {
+	if err := c.CheckWritable("cancel task"); err != nil {
+		return err
+	}
+
 	res, err := c.es.Tasks.Cancel(
 		c.es.Tasks.Cancel.WithContext(ctx),
 		c.es.Tasks.Cancel.WithTaskID(taskID),
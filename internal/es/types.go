@@ -107,6 +107,12 @@ type MappingField struct {
 	Children   []MappingField
 }
 
+// FieldType is one field of a mapping, flattened to its dotted name.
+type FieldType struct {
+	Name string
+	Type string
+}
+
 type AnalyzerInfo struct {
 	Name     string
 	Kind     string
@@ -202,6 +208,12 @@ type SearchResult struct {
 	Total int64
 }
 
+// FieldValue is one bucket of a terms aggregation.
+type FieldValue struct {
+	Value string
+	Count int64
+}
+
 type ClusterHealth struct {
 	Status              string `json:"status"`
 	ActivePrimaryShards int    `json:"active_primary_shards"`
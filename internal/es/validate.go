@@ -32,9 +32,7 @@ func (c *Client) ValidateQuery(ctx context.Context, index string, query json.Raw
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	if c.cfg.Username != "" {
-		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
-	}
+	c.setAuth(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
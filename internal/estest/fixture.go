@@ -0,0 +1,243 @@
+// Package estest provides an in-memory Elasticsearch server for tests. A
+// Server is built from a declarative Fixture describing nodes, indices,
+// shard states, tasks and settings, and answers the REST endpoints stoptail
+// uses so es.Client and the UI tabs can be exercised end to end.
+package estest
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Fixture declares the cluster a Server emulates. Zero values get sensible
+// defaults: one node, version 8.15.0 and cluster name "estest".
+type Fixture struct {
+	ClusterName  string
+	Version      string
+	Distribution string // "opensearch" to emulate OpenSearch
+	BuildFlavor  string // "serverless" to emulate Elasticsearch Serverless
+
+	Nodes        []Node
+	Indices      []Index
+	Tasks        []Task
+	PendingTasks []PendingTask
+	Templates    []Template
+
+	// ClusterSettings are returned as persistent cluster settings.
+	ClusterSettings map[string]string
+
+	// Username and Password, or APIKey, make the server reject requests
+	// without matching credentials with 401.
+	Username string
+	Password string
+	APIKey   string
+
+	// Failures forces an error status for "METHOD /path" (path without the
+	// query string). 401 and 403 answer with a security_exception.
+	Failures map[string]int
+}
+
+type Node struct {
+	Name        string
+	IP          string
+	Roles       string // cat-style role letters, "dim" when empty
+	Master      bool
+	HeapPercent int
+	DiskPercent int
+}
+
+type Index struct {
+	Name     string
+	Status   string // "open" (default) or "close"
+	Shards   int    // primaries, 1 when zero
+	Replicas int
+	Aliases  []string
+
+	// Mappings maps dotted field names to their type, e.g. "user.name": "keyword".
+	Mappings map[string]string
+	Settings map[string]string
+	Docs     []map[string]any
+
+	// States overrides individual shard copies, keyed "<shard>p" for the
+	// primary or "<shard>r" for the first replica, e.g. {"0r": "UNASSIGNED"}.
+	States map[string]string
+}
+
+type Task struct {
+	Node        string
+	ID          int64
+	Action      string
+	Description string
+	RunningMs   int64
+	Cancellable bool
+	Parent      string
+}
+
+type PendingTask struct {
+	Priority string
+	Source   string
+}
+
+type Template struct {
+	Name          string
+	IndexPatterns []string
+	Priority      int
+	ComposedOf    []string
+	DataStream    bool
+}
+
+// ShardCopy is one row of _cat/shards as derived from the fixture.
+type ShardCopy struct {
+	Index   string
+	Shard   int
+	Primary bool
+	State   string
+	Node    string
+}
+
+func (f *Fixture) applyDefaults() {
+	if f.ClusterName == "" {
+		f.ClusterName = "estest"
+	}
+	if f.Version == "" {
+		f.Version = "8.15.0"
+	}
+	if len(f.Nodes) == 0 {
+		f.Nodes = []Node{{Name: "node-1", Master: true}}
+	}
+	hasMaster := false
+	for i := range f.Nodes {
+		n := &f.Nodes[i]
+		if n.IP == "" {
+			n.IP = fmt.Sprintf("10.0.0.%d", i+1)
+		}
+		if n.Roles == "" {
+			n.Roles = "dim"
+		}
+		hasMaster = hasMaster || n.Master
+	}
+	if !hasMaster {
+		f.Nodes[0].Master = true
+	}
+	for i := range f.Indices {
+		f.Indices[i].applyDefaults()
+	}
+}
+
+func (idx *Index) applyDefaults() {
+	if idx.Status == "" {
+		idx.Status = "open"
+	}
+	if idx.Shards <= 0 {
+		idx.Shards = 1
+	}
+}
+
+// ShardCopies lays out every shard copy: primary n goes to node n mod N and
+// replica r of shard n to node (n+r+1) mod N. Replicas that would share a
+// node with their primary stay unassigned, like a real cluster.
+func (f *Fixture) ShardCopies() []ShardCopy {
+	var copies []ShardCopy
+	for _, idx := range f.Indices {
+		if idx.Status == "close" {
+			continue
+		}
+		for shard := 0; shard < idx.Shards; shard++ {
+			primaryNode := f.Nodes[shard%len(f.Nodes)].Name
+			copies = append(copies, f.shardCopy(idx, shard, true, primaryNode))
+			for r := 0; r < idx.Replicas; r++ {
+				node := f.Nodes[(shard+r+1)%len(f.Nodes)].Name
+				if node == primaryNode {
+					node = ""
+				}
+				copies = append(copies, f.shardCopy(idx, shard, false, node))
+			}
+		}
+	}
+	return copies
+}
+
+func (f *Fixture) shardCopy(idx Index, shard int, primary bool, node string) ShardCopy {
+	c := ShardCopy{Index: idx.Name, Shard: shard, Primary: primary, Node: node, State: "STARTED"}
+	key := fmt.Sprintf("%dr", shard)
+	if primary {
+		key = fmt.Sprintf("%dp", shard)
+	}
+	if state, ok := idx.States[key]; ok {
+		c.State = state
+	}
+	if node == "" {
+		c.State = "UNASSIGNED"
+	}
+	if c.State == "UNASSIGNED" {
+		c.Node = ""
+	}
+	return c
+}
+
+// IndexHealth derives an index's health from its shard copies.
+func (f *Fixture) IndexHealth(name string) string {
+	health := "green"
+	for _, c := range f.ShardCopies() {
+		if c.Index != name || c.State == "STARTED" || c.State == "RELOCATING" {
+			continue
+		}
+		if c.Primary {
+			return "red"
+		}
+		health = "yellow"
+	}
+	return health
+}
+
+// ClusterHealth is the worst index health, green for an empty cluster.
+func (f *Fixture) ClusterHealth() string {
+	rank := map[string]int{"green": 0, "yellow": 1, "red": 2}
+	health := "green"
+	for _, idx := range f.Indices {
+		if idx.Status == "close" {
+			continue
+		}
+		if h := f.IndexHealth(idx.Name); rank[h] > rank[health] {
+			health = h
+		}
+	}
+	return health
+}
+
+func (f *Fixture) index(name string) (*Index, bool) {
+	for i := range f.Indices {
+		if f.Indices[i].Name == name {
+			return &f.Indices[i], true
+		}
+	}
+	return nil, false
+}
+
+// resolve expands an index expression (name, alias, "*" or comma list)
+// to concrete index names.
+func (f *Fixture) resolve(expr string) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, part := range splitComma(expr) {
+		for _, idx := range f.Indices {
+			if part == "*" || part == "_all" || matchWildcard(part, idx.Name) {
+				add(idx.Name)
+				continue
+			}
+			for _, alias := range idx.Aliases {
+				if alias == part {
+					add(idx.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package estest
+
+import "testing"
+
+func TestMatchWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"logs", "logs", true},
+		{"logs", "logs-1", false},
+		{"logs-*", "logs-2024", true},
+		{"*-2024", "logs-2024", true},
+		{"l*-*4", "logs-2024", true},
+		{"l*-*5", "logs-2024", false},
+		{"index.version.*", "index.version.created", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchWildcard(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchWildcard(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestShardCopiesAndHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture Fixture
+		health  string
+	}{
+		{"single node replica unassigned", Fixture{Indices: []Index{{Name: "a", Replicas: 1}}}, "yellow"},
+		{"two nodes green", Fixture{Nodes: []Node{{Name: "n1"}, {Name: "n2"}}, Indices: []Index{{Name: "a", Shards: 3, Replicas: 1}}}, "green"},
+		{"primary override", Fixture{Indices: []Index{{Name: "a", States: map[string]string{"0p": "UNASSIGNED"}}}}, "red"},
+		{"initializing replica", Fixture{Nodes: []Node{{Name: "n1"}, {Name: "n2"}}, Indices: []Index{{Name: "a", Replicas: 1, States: map[string]string{"0r": "INITIALIZING"}}}}, "yellow"},
+		{"closed index ignored", Fixture{Indices: []Index{{Name: "a", Replicas: 1, Status: "close"}}}, "green"},
+		{"empty cluster", Fixture{}, "green"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fixture.applyDefaults()
+			if got := tt.fixture.ClusterHealth(); got != tt.health {
+				t.Errorf("ClusterHealth() = %q, want %q", got, tt.health)
+			}
+			for _, c := range tt.fixture.ShardCopies() {
+				if (c.State == "UNASSIGNED") != (c.Node == "") {
+					t.Errorf("shard copy %+v: node must be empty exactly when unassigned", c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,956 @@
+package estest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Server is an httptest.Server answering like a cluster built from a
+// Fixture. Write operations (create/delete/open/close index, aliases, task
+// cancellation, indexing documents) update the fixture, so follow-up reads
+// see the change.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	fixture  Fixture
+	requests []string
+}
+
+// NewServer starts a server for f and closes it when the test ends.
+func NewServer(t testing.TB, f Fixture) *Server {
+	t.Helper()
+	f.applyDefaults()
+	s := &Server{fixture: f}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Requests returns every "METHOD /path" received so far, in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// Fixture returns a copy of the current cluster state.
+func (s *Server) Fixture() Fixture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fixture
+}
+
+// Update changes the fixture while the server is running, e.g. to fail a
+// node or add a task between two refreshes.
+func (s *Server) Update(fn func(f *Fixture)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.fixture)
+	s.fixture.applyDefaults()
+}
+
+type request struct {
+	*http.Request
+	segments []string
+	body     []byte
+}
+
+func (r request) flat() bool {
+	return r.URL.Query().Get("flat_settings") == "true"
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimSuffix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.fixture.Distribution != "opensearch" {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "security_exception", "missing authentication credentials for REST request ["+r.URL.Path+"]")
+		return
+	}
+	if status, ok := s.fixture.Failures[r.Method+" "+r.URL.Path]; ok {
+		errType := "exception"
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			errType = "security_exception"
+		}
+		writeError(w, status, errType, fmt.Sprintf("action [%s %s] is unauthorized or failed", r.Method, r.URL.Path))
+		return
+	}
+
+	var segments []string
+	if path != "" {
+		segments = strings.Split(strings.TrimPrefix(path, "/"), "/")
+	}
+	s.route(w, request{Request: r, segments: segments, body: body})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	f := s.fixture
+	auth := r.Header.Get("Authorization")
+	switch {
+	case f.APIKey != "":
+		return auth == "ApiKey "+f.APIKey
+	case f.Username != "":
+		want := "Basic " + base64.StdEncoding.EncodeToString([]byte(f.Username+":"+f.Password))
+		return auth == want
+	}
+	return true
+}
+
+func (s *Server) route(w http.ResponseWriter, r request) {
+	seg := r.segments
+	if len(seg) == 0 {
+		s.info(w)
+		return
+	}
+
+	switch seg[0] {
+	case "_cat":
+		if len(seg) == 2 {
+			s.cat(w, r, seg[1])
+			return
+		}
+	case "_cluster":
+		s.cluster(w, r)
+		return
+	case "_nodes":
+		s.nodes(w, r)
+		return
+	case "_tasks":
+		s.tasks(w, r)
+		return
+	case "_settings":
+		s.settings(w, r, "*")
+		return
+	case "_mapping":
+		s.mapping(w, r, "*")
+		return
+	case "_migration":
+		writeJSON(w, map[string]any{"cluster_settings": []any{}, "node_settings": []any{}, "index_settings": map[string]any{}, "ml_settings": []any{}})
+		return
+	case "_index_template":
+		s.indexTemplates(w)
+		return
+	case "_template":
+		s.legacyTemplates(w)
+		return
+	case "_aliases":
+		if r.Method == http.MethodPost {
+			s.updateAliases(w, r)
+			return
+		}
+	case "_search":
+		s.search(w, r, "*")
+		return
+	case "_count":
+		s.count(w, "*")
+		return
+	case "_validate":
+		s.validate(w, r)
+		return
+	}
+
+	if !strings.HasPrefix(seg[0], "_") {
+		s.indexRoute(w, r)
+		return
+	}
+	writeError(w, http.StatusBadRequest, "illegal_argument_exception", "no handler found for uri ["+r.URL.Path+"] and method ["+r.Method+"]")
+}
+
+func (s *Server) indexRoute(w http.ResponseWriter, r request) {
+	seg := r.segments
+	expr := seg[0]
+
+	if len(seg) == 1 {
+		switch r.Method {
+		case http.MethodPut:
+			s.createIndex(w, r, expr)
+		case http.MethodDelete:
+			s.deleteIndex(w, expr)
+		case http.MethodGet, http.MethodHead:
+			if len(s.fixture.resolve(expr)) == 0 {
+				writeIndexNotFound(w, expr)
+				return
+			}
+			writeJSON(w, map[string]any{})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "exception", "method not allowed")
+		}
+		return
+	}
+
+	if !strings.Contains(expr, "*") && !strings.Contains(expr, ",") && len(s.fixture.resolve(expr)) == 0 && seg[1] != "_doc" {
+		writeIndexNotFound(w, expr)
+		return
+	}
+
+	switch seg[1] {
+	case "_search":
+		s.search(w, r, expr)
+	case "_count":
+		s.count(w, expr)
+	case "_mapping":
+		s.mapping(w, r, expr)
+	case "_settings":
+		s.settings(w, r, expr)
+	case "_validate":
+		s.validate(w, r)
+	case "_open", "_close":
+		s.setIndexStatus(w, expr, seg[1] == "_open")
+	case "_doc":
+		id := ""
+		if len(seg) > 2 {
+			id = seg[2]
+		}
+		s.indexDocument(w, r, expr, id)
+	default:
+		writeError(w, http.StatusBadRequest, "illegal_argument_exception", "no handler found for uri ["+r.URL.Path+"] and method ["+r.Method+"]")
+	}
+}
+
+func (s *Server) info(w http.ResponseWriter) {
+	f := s.fixture
+	version := map[string]any{"number": f.Version, "build_flavor": "default"}
+	if f.BuildFlavor != "" {
+		version["build_flavor"] = f.BuildFlavor
+	}
+	if f.Distribution != "" {
+		version["distribution"] = f.Distribution
+		delete(version, "build_flavor")
+	}
+	writeJSON(w, map[string]any{
+		"name":         f.Nodes[0].Name,
+		"cluster_name": f.ClusterName,
+		"cluster_uuid": "estest-uuid",
+		"version":      version,
+		"tagline":      "You Know, for Search",
+	})
+}
+
+func (s *Server) cat(w http.ResponseWriter, r request, what string) {
+	f := &s.fixture
+	rows := []map[string]any{}
+
+	switch what {
+	case "indices":
+		for _, idx := range f.Indices {
+			row := map[string]any{
+				"index": idx.Name, "status": idx.Status,
+				"pri": strconv.Itoa(idx.Shards), "rep": strconv.Itoa(idx.Replicas),
+			}
+			if idx.Status == "open" {
+				size := docsSize(idx.Docs)
+				row["health"] = f.IndexHealth(idx.Name)
+				row["docs.count"] = strconv.Itoa(len(idx.Docs))
+				row["docs.deleted"] = "0"
+				row["store.size"] = fmt.Sprintf("%db", size*(1+idx.Replicas))
+				row["pri.store.size"] = fmt.Sprintf("%db", size)
+			}
+			rows = append(rows, row)
+		}
+	case "nodes":
+		shardsPerNode := map[string]int{}
+		for _, c := range f.ShardCopies() {
+			shardsPerNode[c.Node]++
+		}
+		for _, n := range f.Nodes {
+			master := "-"
+			if n.Master {
+				master = "*"
+			}
+			rows = append(rows, map[string]any{
+				"name": n.Name, "ip": n.IP, "node.role": n.Roles, "version": f.Version, "master": master,
+				"heap.percent": strconv.Itoa(n.HeapPercent), "heap.current": "512mb", "heap.max": "1gb",
+				"fielddata.memory_size": "0b", "query_cache.memory_size": "0b", "segments.count": "0",
+				"disk.used_percent": strconv.Itoa(n.DiskPercent), "disk.avail": fmt.Sprintf("%dgb", 100-n.DiskPercent),
+				"disk.total": "100gb", "disk.used": fmt.Sprintf("%dgb", n.DiskPercent),
+				"shard_stats.total_count": strconv.Itoa(shardsPerNode[n.Name]),
+			})
+		}
+	case "shards":
+		for _, c := range f.ShardCopies() {
+			prirep := "r"
+			if c.Primary {
+				prirep = "p"
+			}
+			var node any
+			if c.Node != "" {
+				node = c.Node
+			}
+			rows = append(rows, map[string]any{
+				"index": c.Index, "shard": strconv.Itoa(c.Shard), "prirep": prirep, "state": c.State, "node": node,
+			})
+		}
+	case "aliases":
+		for _, idx := range f.Indices {
+			for _, alias := range idx.Aliases {
+				rows = append(rows, map[string]any{"alias": alias, "index": idx.Name})
+			}
+		}
+	case "thread_pool":
+		for _, n := range f.Nodes {
+			for _, pool := range []string{"get", "search", "write"} {
+				rows = append(rows, map[string]any{
+					"node_name": n.Name, "name": pool, "active": "0", "queue": "0",
+					"rejected": "0", "completed": "0", "pool_size": "0", "type": "fixed",
+				})
+			}
+		}
+	case "recovery":
+	default:
+		writeError(w, http.StatusBadRequest, "illegal_argument_exception", "unknown cat endpoint ["+what+"]")
+		return
+	}
+	writeJSON(w, rows)
+}
+
+func (s *Server) cluster(w http.ResponseWriter, r request) {
+	f := &s.fixture
+	switch strings.Join(r.segments[1:], "/") {
+	case "health":
+		primaries, active, unassigned := 0, 0, 0
+		for _, c := range f.ShardCopies() {
+			switch {
+			case c.State == "UNASSIGNED":
+				unassigned++
+			case c.State == "STARTED" || c.State == "RELOCATING":
+				active++
+				if c.Primary {
+					primaries++
+				}
+			}
+		}
+		writeJSON(w, map[string]any{
+			"cluster_name":          f.ClusterName,
+			"status":                f.ClusterHealth(),
+			"number_of_nodes":       len(f.Nodes),
+			"active_primary_shards": primaries,
+			"active_shards":         active,
+			"unassigned_shards":     unassigned,
+		})
+	case "settings":
+		persistent := map[string]any{}
+		for k, v := range f.ClusterSettings {
+			persistent[k] = v
+		}
+		defaults := map[string]any{}
+		if r.URL.Query().Get("include_defaults") == "true" {
+			defaults["cluster.routing.allocation.enable"] = "all"
+		}
+		if !r.flat() {
+			persistent, defaults = nest(persistent), nest(defaults)
+		}
+		writeJSON(w, map[string]any{"persistent": persistent, "transient": map[string]any{}, "defaults": defaults})
+	case "pending_tasks":
+		tasks := []map[string]any{}
+		for i, t := range f.PendingTasks {
+			tasks = append(tasks, map[string]any{
+				"insert_order": i + 1, "priority": t.Priority, "source": t.Source,
+				"executing": false, "time_in_queue_millis": 0, "time_in_queue": "0s",
+			})
+		}
+		writeJSON(w, map[string]any{"tasks": tasks})
+	case "allocation/explain":
+		s.allocationExplain(w, r)
+	default:
+		writeError(w, http.StatusBadRequest, "illegal_argument_exception", "no handler found for uri ["+r.URL.Path+"]")
+	}
+}
+
+func (s *Server) allocationExplain(w http.ResponseWriter, r request) {
+	var req struct {
+		Index   string `json:"index"`
+		Shard   int    `json:"shard"`
+		Primary bool   `json:"primary"`
+	}
+	json.Unmarshal(r.body, &req)
+
+	for _, c := range s.fixture.ShardCopies() {
+		if c.Index != req.Index || c.Shard != req.Shard || c.Primary != req.Primary {
+			continue
+		}
+		resp := map[string]any{
+			"index": c.Index, "shard": c.Shard, "primary": c.Primary,
+			"current_state": strings.ToLower(c.State),
+		}
+		if c.State == "UNASSIGNED" {
+			resp["unassigned_info"] = map[string]any{"reason": "NODE_LEFT", "at": "2024-01-01T00:00:00.000Z"}
+			resp["can_allocate"] = "no"
+			resp["allocate_explanation"] = "cannot allocate because allocation is not permitted to any of the nodes"
+		} else {
+			resp["can_move_to_other_node"] = "no"
+			resp["move_explanation"] = "cannot move shard to another node, even though it is not allowed to remain on its current node"
+		}
+		writeJSON(w, resp)
+		return
+	}
+	writeError(w, http.StatusBadRequest, "illegal_argument_exception", "unable to find any shards to explain")
+}
+
+func (s *Server) nodes(w http.ResponseWriter, r request) {
+	f := &s.fixture
+	rest := strings.Join(r.segments[1:], "/")
+	switch {
+	case rest == "http":
+		nodes := map[string]any{}
+		for _, n := range f.Nodes {
+			nodes[n.Name] = map[string]any{
+				"name":  n.Name,
+				"roles": roleNames(n.Roles),
+				"http":  map[string]any{"publish_address": n.IP + ":9200"},
+			}
+		}
+		writeJSON(w, map[string]any{"nodes": nodes})
+	case rest == "hot_threads":
+		w.Header().Set("Content-Type", "text/plain")
+		for _, n := range f.Nodes {
+			fmt.Fprintf(w, "::: {%s}{%s}{%s}\n   Hot threads at 2024-01-01T00:00:00Z, interval=500ms, busiestThreads=3:\n\n", n.Name, n.Name, n.IP)
+		}
+	case strings.HasPrefix(rest, "stats"):
+		nodes := map[string]any{}
+		for _, n := range f.Nodes {
+			nodes[n.Name] = map[string]any{"name": n.Name, "indices": map[string]any{"indices": map[string]any{}}}
+		}
+		writeJSON(w, map[string]any{"nodes": nodes})
+	default:
+		writeError(w, http.StatusBadRequest, "illegal_argument_exception", "no handler found for uri ["+r.URL.Path+"]")
+	}
+}
+
+func (s *Server) tasks(w http.ResponseWriter, r request) {
+	f := &s.fixture
+	if len(r.segments) == 3 && r.segments[2] == "_cancel" && r.Method == http.MethodPost {
+		id := r.segments[1]
+		for i, t := range f.Tasks {
+			if taskID(t) != id {
+				continue
+			}
+			if !t.Cancellable {
+				writeError(w, http.StatusBadRequest, "illegal_argument_exception", "task ["+id+"] doesn't support cancellation")
+				return
+			}
+			f.Tasks = append(f.Tasks[:i], f.Tasks[i+1:]...)
+			writeJSON(w, map[string]any{"nodes": map[string]any{}})
+			return
+		}
+		writeError(w, http.StatusNotFound, "resource_not_found_exception", "task ["+id+"] is missing")
+		return
+	}
+
+	nodes := map[string]any{}
+	for _, t := range f.Tasks {
+		entry, ok := nodes[t.Node].(map[string]any)
+		if !ok {
+			entry = map[string]any{"name": t.Node, "tasks": map[string]any{}}
+			nodes[t.Node] = entry
+		}
+		task := map[string]any{
+			"node": t.Node, "id": t.ID, "action": t.Action, "description": t.Description,
+			"running_time_in_nanos": t.RunningMs * 1_000_000, "cancellable": t.Cancellable,
+		}
+		if t.Parent != "" {
+			task["parent_task_id"] = t.Parent
+		}
+		entry["tasks"].(map[string]any)[taskID(t)] = task
+	}
+	writeJSON(w, map[string]any{"nodes": nodes})
+}
+
+func taskID(t Task) string {
+	return fmt.Sprintf("%s:%d", t.Node, t.ID)
+}
+
+func (s *Server) settings(w http.ResponseWriter, r request, expr string) {
+	f := &s.fixture
+	names := f.resolve(expr)
+	if len(names) == 0 && expr != "*" {
+		writeIndexNotFound(w, expr)
+		return
+	}
+	filter := r.URL.Query().Get("name")
+
+	resp := map[string]any{}
+	for _, name := range names {
+		idx, _ := f.index(name)
+		settings := map[string]any{}
+		for k, v := range s.indexSettings(*idx) {
+			if filter == "" || matchWildcard(filter, k) {
+				settings[k] = v
+			}
+		}
+		if !r.flat() {
+			settings = nest(settings)
+		}
+		resp[name] = map[string]any{"settings": settings}
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) indexSettings(idx Index) map[string]string {
+	settings := map[string]string{
+		"index.number_of_shards":   strconv.Itoa(idx.Shards),
+		"index.number_of_replicas": strconv.Itoa(idx.Replicas),
+		"index.uuid":               idx.Name + "-uuid",
+		"index.creation_date":      "1704067200000",
+		"index.provided_name":      idx.Name,
+		"index.version.created":    versionID(s.fixture.Version),
+	}
+	for k, v := range idx.Settings {
+		settings[k] = v
+	}
+	return settings
+}
+
+func (s *Server) mapping(w http.ResponseWriter, r request, expr string) {
+	resp := map[string]any{}
+	for _, name := range s.fixture.resolve(expr) {
+		idx, _ := s.fixture.index(name)
+		resp[name] = map[string]any{"mappings": map[string]any{"properties": mappingProperties(idx.Mappings)}}
+	}
+	if len(resp) == 0 && expr != "*" {
+		writeIndexNotFound(w, expr)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) indexTemplates(w http.ResponseWriter) {
+	f := &s.fixture
+	if f.Distribution == "" && !versionAtLeast(f.Version, 7, 8) {
+		writeError(w, http.StatusBadRequest, "illegal_argument_exception", "no handler found for uri [/_index_template]")
+		return
+	}
+	templates := []map[string]any{}
+	for _, t := range f.Templates {
+		body := map[string]any{
+			"index_patterns": t.IndexPatterns, "composed_of": t.ComposedOf, "priority": t.Priority,
+			"template": map[string]any{},
+		}
+		if t.DataStream {
+			body["data_stream"] = map[string]any{}
+		}
+		templates = append(templates, map[string]any{"name": t.Name, "index_template": body})
+	}
+	writeJSON(w, map[string]any{"index_templates": templates})
+}
+
+func (s *Server) legacyTemplates(w http.ResponseWriter) {
+	resp := map[string]any{}
+	for _, t := range s.fixture.Templates {
+		resp[t.Name] = map[string]any{"order": t.Priority, "index_patterns": t.IndexPatterns, "settings": map[string]any{}}
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) createIndex(w http.ResponseWriter, r request, name string) {
+	if _, ok := s.fixture.index(name); ok {
+		writeError(w, http.StatusBadRequest, "resource_already_exists_exception", "index ["+name+"] already exists")
+		return
+	}
+	var req struct {
+		Settings map[string]any `json:"settings"`
+	}
+	json.Unmarshal(r.body, &req)
+
+	idx := Index{Name: name}
+	flat := flatten(req.Settings, "")
+	for _, prefix := range []string{"", "index."} {
+		if v, ok := flat[prefix+"number_of_shards"]; ok {
+			idx.Shards, _ = strconv.Atoi(v)
+		}
+		if v, ok := flat[prefix+"number_of_replicas"]; ok {
+			idx.Replicas, _ = strconv.Atoi(v)
+		}
+	}
+	idx.applyDefaults()
+	s.fixture.Indices = append(s.fixture.Indices, idx)
+	sort.Slice(s.fixture.Indices, func(i, j int) bool { return s.fixture.Indices[i].Name < s.fixture.Indices[j].Name })
+	writeJSON(w, map[string]any{"acknowledged": true, "shards_acknowledged": true, "index": name})
+}
+
+func (s *Server) deleteIndex(w http.ResponseWriter, name string) {
+	for i, idx := range s.fixture.Indices {
+		if idx.Name == name {
+			s.fixture.Indices = append(s.fixture.Indices[:i], s.fixture.Indices[i+1:]...)
+			writeJSON(w, map[string]any{"acknowledged": true})
+			return
+		}
+	}
+	writeIndexNotFound(w, name)
+}
+
+func (s *Server) setIndexStatus(w http.ResponseWriter, expr string, open bool) {
+	for _, name := range s.fixture.resolve(expr) {
+		idx, _ := s.fixture.index(name)
+		idx.Status = "close"
+		if open {
+			idx.Status = "open"
+		}
+	}
+	writeJSON(w, map[string]any{"acknowledged": true, "shards_acknowledged": true})
+}
+
+func (s *Server) updateAliases(w http.ResponseWriter, r request) {
+	var req struct {
+		Actions []map[string]struct {
+			Index string `json:"index"`
+			Alias string `json:"alias"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal(r.body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "parse_exception", err.Error())
+		return
+	}
+
+	for _, action := range req.Actions {
+		for kind, a := range action {
+			idx, ok := s.fixture.index(a.Index)
+			if !ok {
+				writeIndexNotFound(w, a.Index)
+				return
+			}
+			switch kind {
+			case "add":
+				idx.Aliases = append(idx.Aliases, a.Alias)
+			case "remove":
+				kept := idx.Aliases[:0]
+				found := false
+				for _, existing := range idx.Aliases {
+					if existing == a.Alias {
+						found = true
+						continue
+					}
+					kept = append(kept, existing)
+				}
+				if !found {
+					writeError(w, http.StatusNotFound, "aliases_not_found_exception", "aliases ["+a.Alias+"] missing")
+					return
+				}
+				idx.Aliases = kept
+			}
+		}
+	}
+	writeJSON(w, map[string]any{"acknowledged": true})
+}
+
+func (s *Server) indexDocument(w http.ResponseWriter, r request, name, id string) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "exception", "method not allowed")
+		return
+	}
+	idx, ok := s.fixture.index(name)
+	if !ok {
+		s.fixture.Indices = append(s.fixture.Indices, Index{Name: name})
+		s.fixture.Indices[len(s.fixture.Indices)-1].applyDefaults()
+		idx = &s.fixture.Indices[len(s.fixture.Indices)-1]
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(r.body, &doc); err != nil {
+		writeError(w, http.StatusBadRequest, "mapper_parsing_exception", "failed to parse")
+		return
+	}
+	if id == "" {
+		id = strconv.Itoa(len(idx.Docs) + 1)
+	}
+	doc["_id"] = id
+	idx.Docs = append(idx.Docs, doc)
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, map[string]any{"_index": name, "_id": id, "result": "created"})
+}
+
+type hit struct {
+	index string
+	id    string
+	doc   map[string]any
+}
+
+func (s *Server) hits(expr string) []hit {
+	var hits []hit
+	for _, name := range s.fixture.resolve(expr) {
+		idx, _ := s.fixture.index(name)
+		if idx.Status != "open" {
+			continue
+		}
+		for i, doc := range idx.Docs {
+			id := strconv.Itoa(i + 1)
+			if v, ok := doc["_id"].(string); ok {
+				id = v
+			}
+			source := map[string]any{}
+			for k, v := range doc {
+				if k != "_id" {
+					source[k] = v
+				}
+			}
+			hits = append(hits, hit{index: name, id: id, doc: source})
+		}
+	}
+	return hits
+}
+
+// search supports match_all with size and search_after over a _doc sort,
+// which is what the Browser tab pages with, and top-level terms
+// aggregations.
+func (s *Server) search(w http.ResponseWriter, r request, expr string) {
+	var req struct {
+		Size        *int  `json:"size"`
+		SearchAfter []any `json:"search_after"`
+		Aggs        map[string]struct {
+			Terms *struct {
+				Field string `json:"field"`
+				Size  int    `json:"size"`
+			} `json:"terms"`
+		} `json:"aggs"`
+	}
+	if len(r.body) > 0 {
+		if err := json.Unmarshal(r.body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, "parsing_exception", "failed to parse search source")
+			return
+		}
+	}
+	size := 10
+	if req.Size != nil {
+		size = *req.Size
+	}
+	start := 0
+	if len(req.SearchAfter) > 0 {
+		if after, ok := req.SearchAfter[0].(float64); ok {
+			start = int(after) + 1
+		}
+	}
+
+	all := s.hits(expr)
+	out := []map[string]any{}
+	for i := start; i < len(all) && len(out) < size; i++ {
+		out = append(out, map[string]any{
+			"_index": all[i].index, "_id": all[i].id, "_score": nil,
+			"_source": all[i].doc, "sort": []any{i},
+		})
+	}
+	response := map[string]any{
+		"took":      1,
+		"timed_out": false,
+		"hits": map[string]any{
+			"total":     map[string]any{"value": len(all), "relation": "eq"},
+			"max_score": nil,
+			"hits":      out,
+		},
+	}
+	if len(req.Aggs) > 0 {
+		aggs := map[string]any{}
+		for name, agg := range req.Aggs {
+			if agg.Terms != nil {
+				aggs[name] = map[string]any{"buckets": termsBuckets(all, agg.Terms.Field, agg.Terms.Size)}
+			}
+		}
+		response["aggregations"] = aggs
+	}
+	writeJSON(w, response)
+}
+
+// termsBuckets counts the values of a field, most frequent first and then
+// by value, like a terms aggregation. size defaults to 10.
+func termsBuckets(hits []hit, field string, size int) []map[string]any {
+	counts := map[string]int{}
+	for _, h := range hits {
+		if value, ok := flatten(h.doc, "")[field]; ok {
+			counts[value]++
+		}
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if size == 0 {
+		size = 10
+	}
+	buckets := []map[string]any{}
+	for _, key := range keys[:min(size, len(keys))] {
+		buckets = append(buckets, map[string]any{"key": key, "doc_count": counts[key]})
+	}
+	return buckets
+}
+
+func (s *Server) count(w http.ResponseWriter, expr string) {
+	writeJSON(w, map[string]any{"count": len(s.hits(expr))})
+}
+
+func (s *Server) validate(w http.ResponseWriter, r request) {
+	var req struct {
+		Query map[string]any `json:"query"`
+	}
+	if err := json.Unmarshal(r.body, &req); err != nil || len(req.Query) == 0 {
+		writeJSON(w, map[string]any{"valid": false, "error": "request does not support [query]"})
+		return
+	}
+	writeJSON(w, map[string]any{"valid": true})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errType, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	cause := map[string]any{"type": errType, "reason": reason}
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":  map[string]any{"root_cause": []any{cause}, "type": errType, "reason": reason},
+		"status": status,
+	})
+}
+
+func writeIndexNotFound(w http.ResponseWriter, name string) {
+	writeError(w, http.StatusNotFound, "index_not_found_exception", "no such index ["+name+"]")
+}
+
+func splitComma(s string) []string {
+	var parts []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// matchWildcard matches name against a pattern where "*" matches any run of
+// characters, as in index expressions and setting filters.
+func matchWildcard(pattern, name string) bool {
+	if !strings.Contains(pattern, "*") {
+		return pattern == name
+	}
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, parts[len(parts)-1])
+}
+
+// nest turns dotted keys into nested objects, the way ES renders settings
+// without flat_settings.
+func nest(flat map[string]any) map[string]any {
+	out := map[string]any{}
+	for key, v := range flat {
+		parts := strings.Split(key, ".")
+		m := out
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := m[p].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				m[p] = child
+			}
+			m = child
+		}
+		m[parts[len(parts)-1]] = v
+	}
+	return out
+}
+
+func flatten(m map[string]any, prefix string) map[string]string {
+	out := map[string]string{}
+	for k, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			for fk, fv := range flatten(v, prefix+k+".") {
+				out[fk] = fv
+			}
+		default:
+			out[prefix+k] = fmt.Sprint(v)
+		}
+	}
+	return out
+}
+
+func mappingProperties(fields map[string]string) map[string]any {
+	props := map[string]any{}
+	for field, typ := range fields {
+		parts := strings.Split(field, ".")
+		m := props
+		for _, p := range parts[:len(parts)-1] {
+			obj, ok := m[p].(map[string]any)
+			if !ok {
+				obj = map[string]any{"properties": map[string]any{}}
+				m[p] = obj
+			}
+			m = obj["properties"].(map[string]any)
+		}
+		m[parts[len(parts)-1]] = map[string]any{"type": typ}
+	}
+	return props
+}
+
+func roleNames(letters string) []string {
+	names := map[rune]string{'d': "data", 'i': "ingest", 'm': "master", 'l': "ml", 'r': "remote_cluster_client", 't': "transform"}
+	var roles []string
+	for _, c := range letters {
+		if name, ok := names[c]; ok {
+			roles = append(roles, name)
+		}
+	}
+	return roles
+}
+
+func parseVersion(v string) (major, minor, patch int) {
+	parts := strings.SplitN(v, ".", 3)
+	nums := make([]int, 3)
+	for i, p := range parts {
+		nums[i], _ = strconv.Atoi(strings.SplitN(p, "-", 2)[0])
+	}
+	return nums[0], nums[1], nums[2]
+}
+
+// versionID encodes a version the way index.version.created does,
+// e.g. 8.15.0 -> 8150099.
+func versionID(v string) string {
+	major, minor, patch := parseVersion(v)
+	return strconv.Itoa(major*1000000 + minor*10000 + patch*100 + 99)
+}
+
+func versionAtLeast(v string, major, minor int) bool {
+	ma, mi, _ := parseVersion(v)
+	return ma > major || (ma == major && mi >= minor)
+}
+
+func docsSize(docs []map[string]any) int {
+	size := 0
+	for _, doc := range docs {
+		data, _ := json.Marshal(doc)
+		size += len(data)
+	}
+	return size
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AuditEntry is one request stoptail sent to a cluster. Bodies are recorded
+// as a SHA-256 hash so the log never holds document contents.
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	Session    string    `json:"session"`
+	Cluster    string    `json:"cluster"`
+	User       string    `json:"user"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	BodySHA256 string    `json:"body_sha256,omitempty"`
+	BodyBytes  int       `json:"body_bytes,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditKeep is how many of the current session's entries AuditLog keeps
+// in memory for the viewer; the file has all of them.
+const auditKeep = 1000
+
+// AuditLog appends entries to ~/.stoptail/audit.log as JSON lines and keeps
+// the current session's latest entries in memory for the viewer.
+type AuditLog struct {
+	mu       sync.Mutex
+	file     *os.File
+	session  string
+	cluster  string
+	user     string
+	entries  []AuditEntry // ring of the last auditKeep entries
+	next     int          // where the next entry goes once the ring is full
+	recorded int
+}
+
+func auditPath() (string, error) {
+	dir, err := StoptailDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.log"), nil
+}
+
+// OpenAuditLog opens the audit log for appending, creating it if needed.
+func OpenAuditLog(cluster string) (*AuditLog, error) {
+	if err := ensureDir(); err != nil {
+		return nil, err
+	}
+	path, err := auditPath()
+	if err != nil {
+		return nil, err
+	}
+	return openAuditLogAt(path, cluster)
+}
+
+func openAuditLogAt(path, cluster string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{
+		file:    f,
+		session: newSessionID(),
+		cluster: cluster,
+		user:    currentUser(),
+	}, nil
+}
+
+// Path returns the file the log is written to.
+func (l *AuditLog) Path() string {
+	return l.file.Name()
+}
+
+// Record stamps entry with the session, cluster, user and time, and appends
+// it to the log.
+func (l *AuditLog) Record(entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Session = l.session
+	entry.Cluster = l.cluster
+	entry.User = l.user
+	if len(l.entries) < auditKeep {
+		l.entries = append(l.entries, entry)
+	} else {
+		l.entries[l.next] = entry
+		l.next = (l.next + 1) % auditKeep
+	}
+	l.recorded++
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = l.file.Write(append(data, '\n'))
+	return err
+}
+
+// Entries returns a copy of the latest entries recorded in this session,
+// oldest first.
+func (l *AuditLog) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := append([]AuditEntry(nil), l.entries[l.next:]...)
+	return append(entries, l.entries[:l.next]...)
+}
+
+// Recorded returns how many entries this session has recorded, including
+// those Entries no longer holds.
+func (l *AuditLog) Recorded() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.recorded
+}
+
+func (l *AuditLog) Close() error {
+	return l.file.Close()
+}
+
+// HashBody returns the hex SHA-256 of body, or "" when it is empty.
+func HashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func newSessionID() string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102T150405")
+	}
+	return hex.EncodeToString(b)
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLogRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	log, err := openAuditLogAt(path, "production")
+	if err != nil {
+		t.Fatalf("openAuditLogAt() error = %v", err)
+	}
+	if err := log.Record(AuditEntry{Method: "DELETE", Path: "/logs", Status: 200}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := log.Record(AuditEntry{Method: "POST", Path: "/_search", BodySHA256: HashBody([]byte(`{}`)), Status: 200}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	log.Close()
+
+	// A second session appends rather than truncating.
+	log2, err := openAuditLogAt(path, "production")
+	if err != nil {
+		t.Fatalf("openAuditLogAt() error = %v", err)
+	}
+	log2.Record(AuditEntry{Method: "GET", Path: "/"})
+	log2.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].Cluster != "production" || entries[0].Session == "" || entries[0].Time.IsZero() {
+		t.Errorf("entry not stamped: %+v", entries[0])
+	}
+	if entries[0].Session != entries[1].Session || entries[1].Session == entries[2].Session {
+		t.Error("entries should share a session ID within one log and differ across logs")
+	}
+	if len(log.Entries()) != 2 || len(log2.Entries()) != 1 {
+		t.Errorf("Entries() should only hold the current session")
+	}
+}
+
+func TestAuditLogKeepsLatestEntries(t *testing.T) {
+	log, err := openAuditLogAt(filepath.Join(t.TempDir(), "audit.log"), "production")
+	if err != nil {
+		t.Fatalf("openAuditLogAt() error = %v", err)
+	}
+	defer log.Close()
+
+	for i := range auditKeep + 5 {
+		log.Record(AuditEntry{Method: "GET", Status: i})
+	}
+	entries := log.Entries()
+	if len(entries) != auditKeep || log.Recorded() != auditKeep+5 {
+		t.Fatalf("got %d entries of %d recorded, want %d of %d", len(entries), log.Recorded(), auditKeep, auditKeep+5)
+	}
+	for i, e := range entries {
+		if e.Status != i+5 {
+			t.Fatalf("entries[%d].Status = %d, want %d: the oldest should go first", i, e.Status, i+5)
+		}
+	}
+}
+
+func TestHashBody(t *testing.T) {
+	if got := HashBody(nil); got != "" {
+		t.Errorf("HashBody(nil) = %q, want empty", got)
+	}
+	want := "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	if got := HashBody([]byte(`{}`)); got != want {
+		t.Errorf("HashBody({}) = %q, want %q", got, want)
+	}
+}
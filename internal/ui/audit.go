@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/labtiva/stoptail/internal/storage"
+)
+
+// AuditViewModel lists the requests recorded to the audit log during the
+// current session, newest first.
+type AuditViewModel struct {
+	entries  []storage.AuditEntry
+	recorded int
+	path     string
+	nav      ListNav
+	width    int
+	height   int
+}
+
+func NewAuditView() AuditViewModel {
+	return AuditViewModel{nav: NewScrollNav()}
+}
+
+func (m *AuditViewModel) SetLog(log *storage.AuditLog) {
+	m.entries = nil
+	m.recorded = 0
+	m.path = ""
+	m.nav.Reset()
+	if log == nil {
+		return
+	}
+	entries := log.Entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		m.entries = append(m.entries, entries[i])
+	}
+	m.recorded = log.Recorded()
+	m.path = log.Path()
+}
+
+func (m *AuditViewModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m AuditViewModel) visibleRows() int {
+	return max(1, m.height-10)
+}
+
+func (m AuditViewModel) Update(msg tea.Msg) (AuditViewModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		m.nav.HandleKey(msg.String(), len(m.entries), m.visibleRows())
+	case tea.MouseWheelMsg:
+		m.nav.HandleWheel(msg.Button == tea.MouseWheelDown, len(m.entries), m.visibleRows())
+	}
+	return m, nil
+}
+
+func (m AuditViewModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorBlue)
+	grayStyle := lipgloss.NewStyle().Foreground(ColorGray)
+
+	modalWidth := max(40, m.width-8)
+	pathWidth := max(10, modalWidth-44)
+
+	var b strings.Builder
+	title := fmt.Sprintf("Audit Log (%d requests this session)", m.recorded)
+	if m.recorded > len(m.entries) {
+		title = fmt.Sprintf("Audit Log (%d requests this session, latest %d shown)", m.recorded, len(m.entries))
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+	if m.path != "" {
+		b.WriteString(grayStyle.Render(m.path))
+	}
+	b.WriteString("\n\n")
+
+	header := fmt.Sprintf("%-8s  %-6s  %-6s  %8s  %s", "TIME", "METHOD", "STATUS", "DURATION", "PATH")
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(header))
+	b.WriteString("\n")
+
+	if len(m.entries) == 0 {
+		b.WriteString(grayStyle.Render("No requests recorded yet"))
+		b.WriteString("\n")
+	}
+
+	end := min(len(m.entries), m.nav.Scroll+m.visibleRows())
+	for _, e := range m.entries[m.nav.Scroll:end] {
+		status := fmt.Sprintf("%d", e.Status)
+		statusColor := ColorGreen
+		if e.Error != "" {
+			status = "ERR"
+			statusColor = ColorRed
+		} else if e.Status >= 400 {
+			statusColor = ColorRed
+		}
+		line := fmt.Sprintf("%-8s  %-6s  %s  %8s  %s",
+			e.Time.Format("15:04:05"),
+			e.Method,
+			lipgloss.NewStyle().Foreground(statusColor).Render(fmt.Sprintf("%-6s", status)),
+			fmt.Sprintf("%dms", e.DurationMs),
+			Truncate(e.Path, pathWidth))
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(grayStyle.Render("↑↓: scroll | Esc: close"))
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBlue).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(b.String())
+
+	return OverlayModal("", content, m.width, m.height)
+}
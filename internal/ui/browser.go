@@ -42,8 +42,12 @@ type BrowserModel struct {
 	detailLines       []string
 	detailNav         ListNav
 	detailHeight      int
+	docFilter         ResponseFilter
 	activePane    BrowserPane
 	clipboard     Clipboard
+	modal         *Modal
+	exportFile    string
+	notice        string
 
 	width  int
 	height int
@@ -62,6 +66,7 @@ func NewBrowser() BrowserModel {
 		docNav:     NewCursorNav(),
 		detailNav:  NewScrollNav(),
 		clipboard:  NewClipboard(),
+		docFilter:  NewResponseFilter(),
 		hasMore:    true,
 	}
 }
@@ -139,14 +144,21 @@ func (m BrowserModel) selectedIndexName() string {
 }
 
 func (m BrowserModel) HasActiveInput() bool {
-	return m.filterActive
+	return m.filterActive || m.docFilter.Editing() || m.modal != nil
 }
 
 func (m BrowserModel) ClipboardMessage() string {
-	return m.clipboard.Message()
+	if msg := m.clipboard.Message(); msg != "" {
+		return msg
+	}
+	return m.notice
 }
 
 func (m BrowserModel) Update(msg tea.Msg) (BrowserModel, tea.Cmd) {
+	if _, ok := msg.(browserSearchMsg); !ok && m.modal != nil {
+		return m.updateModal(msg)
+	}
+
 	switch msg := msg.(type) {
 	case browserSearchMsg:
 		m.loading = false
@@ -169,10 +181,16 @@ func (m BrowserModel) Update(msg tea.Msg) (BrowserModel, tea.Cmd) {
 
 	case tea.KeyPressMsg:
 		m.clipboard.ClearMessage()
+		m.notice = ""
 
 		if m.filterActive {
 			return m.handleFilterInput(msg)
 		}
+		if m.docFilter.Editing() {
+			m.docFilter.HandleKey(msg)
+			m.updateDetailPane()
+			return m, nil
+		}
 
 		switch msg.String() {
 		case "/":
@@ -180,6 +198,9 @@ func (m BrowserModel) Update(msg tea.Msg) (BrowserModel, tea.Cmd) {
 				m.filterActive = true
 				m.filterText = ""
 			}
+			if m.activePane == BrowserPaneDetail {
+				return m, m.docFilter.Open()
+			}
 		case "left", "h":
 			if m.activePane > BrowserPaneIndices {
 				m.activePane--
@@ -204,8 +225,20 @@ func (m BrowserModel) Update(msg tea.Msg) (BrowserModel, tea.Cmd) {
 			}
 		case "ctrl+y":
 			if m.activePane == BrowserPaneDetail && len(m.documents) > 0 {
+				if m.docFilter.Applied() {
+					return m, m.clipboard.Copy(m.docFilter.Result())
+				}
 				return m, m.clipboard.Copy(m.selectedDocSource())
 			}
+		case "alt+e":
+			if m.activePane != BrowserPaneIndices && len(m.documents) > 0 {
+				title := "Save documents"
+				if m.activePane == BrowserPaneDetail {
+					title = "Save document"
+				}
+				m.modal = NewFileModal(ModalExport, title, exportHint, m.exportFile)
+				return m, func() tea.Msg { return ModalInitMsg{} }
+			}
 		}
 
 	case tea.MouseWheelMsg:
@@ -272,7 +305,10 @@ func (m *BrowserModel) updateDetailPane() {
 	doc := m.documents[m.docNav.Selected]
 	var sourceLines []string
 	var obj any
-	if err := json.Unmarshal([]byte(doc.Source), &obj); err == nil {
+	m.docFilter.Run(doc.Source)
+	if m.docFilter.Applied() {
+		sourceLines = strings.Split(highlightJSON(m.docFilter.Result()), "\n")
+	} else if err := json.Unmarshal([]byte(doc.Source), &obj); err == nil {
 		if pretty, err := json.MarshalIndent(obj, "", "  "); err == nil {
 			sanitized := SanitizeForTerminal(string(pretty))
 			highlighted := highlightJSON(sanitized)
@@ -319,6 +355,86 @@ func (m BrowserModel) selectedDocSource() string {
 	return doc.Source
 }
 
+// updateModal passes msg to the save modal and exports once a path is
+// submitted.
+func (m BrowserModel) updateModal(msg tea.Msg) (BrowserModel, tea.Cmd) {
+	if _, ok := msg.(ModalInitMsg); ok {
+		return m, m.modal.Init()
+	}
+	cmd := m.modal.Update(msg)
+	if m.modal.Cancelled() {
+		m.modal = nil
+		return m, nil
+	}
+	if !m.modal.Done() {
+		return m, cmd
+	}
+	modal := m.modal
+	m.modal = nil
+	switch modal.Type() {
+	case ModalOverwrite:
+		if !modal.Confirmed() {
+			return m, nil
+		}
+	case ModalExport:
+		if fileExists(modal.Path()) {
+			m.modal = NewOverwriteModal(ModalExport, modal.Path())
+			return m, func() tea.Msg { return ModalInitMsg{} }
+		}
+	default:
+		return m, nil
+	}
+	if err := m.export(modal.Path()); err != nil {
+		m.modal = NewErrorModal(err.Error())
+		return m, func() tea.Msg { return ModalInitMsg{} }
+	}
+	return m, nil
+}
+
+// export saves the selected document (as filtered) from the detail pane,
+// or every loaded document from the list.
+func (m *BrowserModel) export(path string) error {
+	text := m.documentsJSON()
+	if m.activePane == BrowserPaneDetail {
+		text = m.selectedDocSource()
+		if m.docFilter.Applied() {
+			text = m.docFilter.Result()
+		}
+	}
+	table, _ := NewResultTable(text)
+	what, err := exportResponse(path, text, table)
+	if err != nil {
+		return err
+	}
+	m.exportFile = path
+	m.notice = fmt.Sprintf("Saved %s to %s", what, path)
+	return nil
+}
+
+// documentsJSON renders the loaded documents as an array of hits.
+func (m BrowserModel) documentsJSON() string {
+	var b strings.Builder
+	b.WriteString("[")
+	for i, doc := range m.documents {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		index, _ := json.Marshal(doc.Index)
+		id, _ := json.Marshal(doc.ID)
+		source := doc.Source
+		if strings.TrimSpace(source) == "" {
+			source = "null"
+		}
+		fmt.Fprintf(&b, `{"_index":%s,"_id":%s,"_source":%s}`, index, id, source)
+	}
+	b.WriteString("]")
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(b.String()), "", "  "); err != nil {
+		return b.String()
+	}
+	return pretty.String()
+}
+
 func (m *BrowserModel) startFetchDocuments(appendDocs bool) tea.Cmd {
 	index := m.selectedIndexName()
 	if index == "" || m.client == nil {
@@ -366,6 +482,10 @@ func (m BrowserModel) View() string {
 		return ""
 	}
 
+	if m.modal != nil {
+		return m.modal.View(m.width, m.height)
+	}
+
 	leftWidth, middleWidth, rightWidth := m.paneWidths()
 
 	leftPane := m.renderIndexList(leftWidth)
@@ -489,10 +609,19 @@ func (m BrowserModel) renderDetailPane(width int) string {
 
 	boxInnerHeight := m.height - 4
 	visibleLines := max(0, boxInnerHeight-3)
+	if m.docFilter.Visible() {
+		visibleLines = max(0, visibleLines-1)
+	}
 	for i := m.detailNav.Scroll; i < len(m.detailLines) && i-m.detailNav.Scroll < visibleLines; i++ {
 		content.WriteString(m.detailLines[i])
 		content.WriteString("\n")
 	}
+	if m.docFilter.Visible() {
+		for i := len(m.detailLines) - m.detailNav.Scroll; i < visibleLines; i++ {
+			content.WriteString("\n")
+		}
+		content.WriteString(m.docFilter.View(width - 4))
+	}
 
 	return lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+// ClusterOpener builds the client for a resolved cluster. main supplies it
+// so a switched-to cluster gets exactly the same client setup (flags, audit
+// log, recording) as the one chosen at startup.
+type ClusterOpener func(resolved *config.ResolvedCluster, awsProfile string) (*es.Client, *config.Config, error)
+
+type clusterSelectedMsg struct {
+	name       string
+	newSession bool
+}
+
+type clusterSwitchedMsg struct {
+	name       string
+	seq        int
+	newSession bool
+	client     *es.Client
+	cfg        *config.Config
+	err        error
+}
+
+// ClusterSwitcherModel picks one of the configured clusters and shows a
+// spinner while its url_command or credentials_command runs.
+type ClusterSwitcherModel struct {
+	clusters  *config.ClustersConfig
+	names     []string
+	current   string
+	nav       ListNav
+	resolving string
+	message   string
+	err       error
+	seq       int
+	width     int
+	height    int
+}
+
+func NewClusterSwitcher() ClusterSwitcherModel {
+	return ClusterSwitcherModel{nav: NewCursorNav()}
+}
+
+func (m *ClusterSwitcherModel) SetClusters(clusters *config.ClustersConfig) {
+	m.clusters = clusters
+	m.names = clusters.ClusterNames()
+	sort.Strings(m.names)
+}
+
+func (m ClusterSwitcherModel) Available() bool {
+	return len(m.names) > 0
+}
+
+// Open shows the list with the current cluster preselected.
+func (m *ClusterSwitcherModel) Open(current string) {
+	m.current = current
+	m.resolving = ""
+	m.err = nil
+	m.nav.Reset()
+	for i, name := range m.names {
+		if name == current {
+			m.nav.Selected = i
+		}
+	}
+	m.nav.Scroll = max(0, m.nav.Selected-m.visibleRows()+1)
+}
+
+func (m *ClusterSwitcherModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m ClusterSwitcherModel) Resolving() bool {
+	return m.resolving != ""
+}
+
+func (m ClusterSwitcherModel) visibleRows() int {
+	return max(1, m.height-12)
+}
+
+// startResolve marks name as being resolved and returns the command that
+// resolves it and opens a client. The sequence number lets the caller drop
+// a result that arrives after the switch was cancelled.
+func (m *ClusterSwitcherModel) startResolve(name string, newSession bool, open ClusterOpener) tea.Cmd {
+	m.seq++
+	m.resolving = name
+	m.err = nil
+	m.message = "Fetching cluster URL..."
+	entry := m.clusters.Clusters[name]
+	if entry.CredentialsCommand != "" || entry.APIKeyCommand != "" {
+		m.message = "Fetching cluster credentials..."
+	} else if !entry.NeedsCommand() {
+		m.message = "Connecting..."
+	}
+
+	clusters, seq := m.clusters, m.seq
+	return func() tea.Msg {
+		resolved, err := clusters.Resolve(name)
+		if err != nil {
+			return clusterSwitchedMsg{name: name, seq: seq, newSession: newSession, err: err}
+		}
+		client, cfg, err := open(resolved, entry.AWSProfile)
+		return clusterSwitchedMsg{name: name, seq: seq, newSession: newSession, client: client, cfg: cfg, err: err}
+	}
+}
+
+func (m *ClusterSwitcherModel) cancelResolve() {
+	m.resolving = ""
+	m.seq++
+}
+
+func (m *ClusterSwitcherModel) fail(err error) {
+	m.resolving = ""
+	m.err = err
+}
+
+func (m ClusterSwitcherModel) Update(msg tea.Msg) (ClusterSwitcherModel, tea.Cmd) {
+	if m.Resolving() {
+		return m, nil
+	}
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		key := msg.String()
+		if (key == "enter" || key == "n") && m.nav.Selected < len(m.names) {
+			selected := clusterSelectedMsg{name: m.names[m.nav.Selected], newSession: key == "n"}
+			return m, func() tea.Msg { return selected }
+		}
+		m.nav.HandleKey(msg.String(), len(m.names), m.visibleRows())
+	case tea.MouseWheelMsg:
+		m.nav.HandleWheel(msg.Button == tea.MouseWheelDown, len(m.names), m.visibleRows())
+	}
+	return m, nil
+}
+
+func (m ClusterSwitcherModel) View(spinner string) string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorBlue)
+	grayStyle := lipgloss.NewStyle().Foreground(ColorGray)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Switch Cluster"))
+	b.WriteString("\n\n")
+
+	if m.resolving != "" {
+		b.WriteString(fmt.Sprintf("%s %s", spinner, grayStyle.Render(m.message)))
+		b.WriteString("\n")
+		b.WriteString(grayStyle.Render(m.resolving))
+		b.WriteString("\n\n")
+		b.WriteString(grayStyle.Render("Esc: cancel"))
+	} else {
+		end := min(len(m.names), m.nav.Scroll+m.visibleRows())
+		for i := m.nav.Scroll; i < end; i++ {
+			name := m.names[i]
+			line := "  " + name
+			if i == m.nav.Selected {
+				line = lipgloss.NewStyle().Foreground(ColorBlue).Bold(true).Render("> " + name)
+			}
+			if name == m.current {
+				line += grayStyle.Render(" (current)")
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+		if m.err != nil {
+			b.WriteString("\n")
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorRed).Width(46).Render(m.err.Error()))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(grayStyle.Render("↑↓: select | Enter: switch | n: open in new session | Esc: close"))
+	}
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBlue).
+		Padding(1, 2).
+		Width(64).
+		Render(b.String())
+
+	return OverlayModal("", content, m.width, m.height)
+}
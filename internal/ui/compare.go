@@ -0,0 +1,293 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+type compareLoadedMsg struct {
+	id    int
+	state *es.ClusterState
+	nodes []es.NodeStats
+	err   error
+}
+
+type compareSide struct {
+	id      int
+	name    string
+	state   *es.ClusterState
+	nodes   []es.NodeStats
+	err     error
+	loading bool
+}
+
+// CompareModel shows two sessions' cluster health, indices and nodes side
+// by side, e.g. to check whether a new cluster has caught up with the old
+// one during a blue/green migration.
+type CompareModel struct {
+	left   compareSide
+	right  compareSide
+	nav    ListNav
+	width  int
+	height int
+}
+
+func NewCompare() CompareModel {
+	return CompareModel{nav: NewScrollNav()}
+}
+
+func (m *CompareModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Open compares the sessions with the given IDs and returns the commands
+// that load both clusters.
+func (m *CompareModel) Open(left, right *Model, leftID, rightID int) tea.Cmd {
+	m.left = compareSide{id: leftID, name: left.clusterKey(), loading: true}
+	m.right = compareSide{id: rightID, name: right.clusterKey(), loading: true}
+	m.nav.Reset()
+	return tea.Batch(loadCompareSide(leftID, left.client), loadCompareSide(rightID, right.client))
+}
+
+func loadCompareSide(id int, client *es.Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		state, err := client.FetchClusterState(ctx)
+		if err != nil {
+			return compareLoadedMsg{id: id, err: err}
+		}
+		nodes, err := client.FetchNodeStats(ctx)
+		return compareLoadedMsg{id: id, state: state, nodes: nodes, err: err}
+	}
+}
+
+func (m *CompareModel) setLoaded(msg compareLoadedMsg) {
+	for _, side := range []*compareSide{&m.left, &m.right} {
+		if side.id == msg.id && side.loading {
+			side.loading = false
+			side.state = msg.state
+			side.nodes = msg.nodes
+			side.err = msg.err
+		}
+	}
+}
+
+func (m CompareModel) visibleRows() int {
+	return max(1, m.height-8)
+}
+
+func (m CompareModel) Update(msg tea.Msg) (CompareModel, tea.Cmd) {
+	total := len(m.lines())
+	switch msg := msg.(type) {
+	case tea.KeyPressMsg:
+		m.nav.HandleKey(msg.String(), total, m.visibleRows())
+	case tea.MouseWheelMsg:
+		m.nav.HandleWheel(msg.Button == tea.MouseWheelDown, total, m.visibleRows())
+	}
+	return m, nil
+}
+
+func (m CompareModel) columnWidth() int {
+	return max(20, (m.width-8)/2)
+}
+
+// lines renders everything below the title so it can be scrolled as one.
+func (m CompareModel) lines() []string {
+	grayStyle := lipgloss.NewStyle().Foreground(ColorGray)
+	boldStyle := lipgloss.NewStyle().Bold(true)
+	colWidth := m.columnWidth()
+
+	var lines []string
+	left, right := m.left.summary(), m.right.summary()
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		lines = append(lines, padRight(l, colWidth)+"  "+r)
+	}
+	if m.left.state == nil || m.right.state == nil {
+		return lines
+	}
+
+	lines = append(lines, "", boldStyle.Render("Indices"))
+	nameWidth := max(10, m.width-68)
+	lines = append(lines, grayStyle.Render(fmt.Sprintf("%-*s  %-7s %12s  %-7s %12s  %s",
+		nameWidth, "INDEX", "HEALTH", "DOCS", "HEALTH", "DOCS", "DIFF")))
+	for _, row := range compareIndices(m.left.state.Indices, m.right.state.Indices) {
+		lines = append(lines, row.render(nameWidth))
+	}
+
+	lines = append(lines, "", boldStyle.Render("Nodes"))
+	leftNodes, rightNodes := renderCompareNodes(m.left.nodes), renderCompareNodes(m.right.nodes)
+	for i := 0; i < max(len(leftNodes), len(rightNodes)); i++ {
+		var l, r string
+		if i < len(leftNodes) {
+			l = leftNodes[i]
+		}
+		if i < len(rightNodes) {
+			r = rightNodes[i]
+		}
+		lines = append(lines, padRight(l, colWidth)+"  "+r)
+	}
+	return lines
+}
+
+func (s compareSide) summary() []string {
+	title := lipgloss.NewStyle().Bold(true).Foreground(ColorBlue).Render(s.name)
+	switch {
+	case s.loading:
+		return []string{title, lipgloss.NewStyle().Foreground(ColorGray).Render("Loading...")}
+	case s.state == nil:
+		msg := "No data"
+		if s.err != nil {
+			msg = Truncate(s.err.Error(), 60)
+		}
+		return []string{title, lipgloss.NewStyle().Foreground(ColorRed).Render(msg)}
+	}
+
+	health := s.state.Health
+	docs := int64(0)
+	for _, idx := range s.state.Indices {
+		n, _ := strconv.ParseInt(idx.DocsCount, 10, 64)
+		docs += n
+	}
+	status := lipgloss.NewStyle().Foreground(HealthColor(health.Status)).Bold(true).Render(strings.ToUpper(health.Status))
+	return []string{
+		title + "  " + status,
+		fmt.Sprintf("%d nodes  %d indices  %s docs", len(s.state.Nodes), len(s.state.Indices), FormatNumber(strconv.FormatInt(docs, 10))),
+		fmt.Sprintf("%d active shards  %d primaries  %d unassigned", health.ActiveShards, health.ActivePrimaryShards, health.UnassignedShards),
+	}
+}
+
+type compareRow struct {
+	name        string
+	leftHealth  string
+	rightHealth string
+	leftDocs    string
+	rightDocs   string
+	inLeft      bool
+	inRight     bool
+}
+
+func compareIndices(left, right []es.IndexInfo) []compareRow {
+	rows := map[string]*compareRow{}
+	get := func(name string) *compareRow {
+		if rows[name] == nil {
+			rows[name] = &compareRow{name: name}
+		}
+		return rows[name]
+	}
+	for _, idx := range left {
+		r := get(idx.Name)
+		r.inLeft, r.leftHealth, r.leftDocs = true, idx.Health, idx.DocsCount
+	}
+	for _, idx := range right {
+		r := get(idx.Name)
+		r.inRight, r.rightHealth, r.rightDocs = true, idx.Health, idx.DocsCount
+	}
+
+	names := make([]string, 0, len(rows))
+	for name := range rows {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]compareRow, len(names))
+	for i, name := range names {
+		result[i] = *rows[name]
+	}
+	return result
+}
+
+// diff describes how far the right cluster is from the left one.
+func (r compareRow) diff() (string, bool) {
+	switch {
+	case !r.inRight:
+		return "missing on right", false
+	case !r.inLeft:
+		return "missing on left", false
+	}
+	l, lerr := strconv.ParseInt(r.leftDocs, 10, 64)
+	rt, rerr := strconv.ParseInt(r.rightDocs, 10, 64)
+	if lerr != nil || rerr != nil {
+		return "", true
+	}
+	if l == rt {
+		return "✓", true
+	}
+	return fmt.Sprintf("%+d", rt-l), false
+}
+
+func (r compareRow) render(nameWidth int) string {
+	healthCell := func(h string) string {
+		return lipgloss.NewStyle().Foreground(HealthColor(h)).Render(fmt.Sprintf("%-7s", h))
+	}
+	diff, same := r.diff()
+	diffStyle := lipgloss.NewStyle().Foreground(ColorGreen)
+	if !same {
+		diffStyle = diffStyle.Foreground(ColorYellow)
+	}
+	return fmt.Sprintf("%-*s  %s %12s  %s %12s  %s",
+		nameWidth, Truncate(r.name, nameWidth),
+		healthCell(r.leftHealth), FormatNumber(r.leftDocs),
+		healthCell(r.rightHealth), FormatNumber(r.rightDocs),
+		diffStyle.Render(diff))
+}
+
+func renderCompareNodes(nodes []es.NodeStats) []string {
+	lines := []string{lipgloss.NewStyle().Foreground(ColorGray).Render(fmt.Sprintf("%-20s %5s %5s %7s", "NODE", "HEAP", "DISK", "SHARDS"))}
+	for _, n := range nodes {
+		name := n.Name
+		if n.Master == "*" {
+			name += " *"
+		}
+		lines = append(lines, fmt.Sprintf("%-20s %4s%% %4s%% %7s", Truncate(name, 20), n.HeapPercent, n.DiskPercent, n.Shards))
+	}
+	return lines
+}
+
+func padRight(s string, width int) string {
+	if w := lipgloss.Width(s); w < width {
+		return s + strings.Repeat(" ", width-w)
+	}
+	return s
+}
+
+func (m CompareModel) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorBlue)
+	grayStyle := lipgloss.NewStyle().Foreground(ColorGray)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Compare: %s ↔ %s", m.left.name, m.right.name)))
+	b.WriteString("\n\n")
+
+	lines := m.lines()
+	end := min(len(lines), m.nav.Scroll+m.visibleRows())
+	for _, line := range lines[min(m.nav.Scroll, end):end] {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(grayStyle.Render("↑↓: scroll | Tab: compare with next session | r: refresh | Esc: close"))
+
+	content := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorBlue).
+		Padding(0, 1).
+		Width(max(40, m.width-2)).
+		Render(b.String())
+
+	return OverlayModal("", content, m.width, m.height)
+}
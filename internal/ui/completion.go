@@ -243,6 +243,7 @@ type CompletionState struct {
 	SelectedIdx int
 	TriggerCol  int
 	Query       string
+	Values      bool // field values, accepted as a quoted value instead of a key
 }
 
 func (c *CompletionState) Filter(query string) {
@@ -285,4 +286,5 @@ func (c *CompletionState) Close() {
 	c.Filtered = nil
 	c.SelectedIdx = 0
 	c.Query = ""
+	c.Values = false
 }
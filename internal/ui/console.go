@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+// ConsoleRequest is one request in a Kibana Dev Tools style buffer:
+//
+//	GET /logs/_search
+//	{
+//	  "query": { "match_all": {} }
+//	}
+//
+// StartLine and EndLine are the zero-based buffer lines of the request line
+// and of the last line of its body.
+type ConsoleRequest struct {
+	Method    string
+	Path      string
+	Body      string
+	StartLine int
+	EndLine   int
+}
+
+var consoleRequestLine = regexp.MustCompile(`^(?i)(GET|POST|PUT|DELETE|HEAD|PATCH)\s+(\S+)\s*$`)
+
+var tripleQuoted = regexp.MustCompile(`(?s)"""(.*?)"""`)
+
+// ParseConsole splits a console buffer into requests. Lines before the
+// first request line, and "#" or "//" comments between bodies, are ignored.
+func ParseConsole(text string) []ConsoleRequest {
+	var (
+		requests []ConsoleRequest
+		current  *ConsoleRequest
+		body     []string
+		depth    int
+	)
+	finish := func() {
+		if current == nil {
+			return
+		}
+		current.Body = convertTripleQuotes(strings.TrimSpace(strings.Join(body, "\n")))
+		requests = append(requests, *current)
+	}
+
+	for i, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		// An unindented request line starts a new request even inside an
+		// unbalanced body, so one typo doesn't swallow the rest of the buffer.
+		if depth == 0 || !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			if match := consoleRequestLine.FindStringSubmatch(trimmed); match != nil {
+				finish()
+				path := match[2]
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+				current = &ConsoleRequest{Method: strings.ToUpper(match[1]), Path: path, StartLine: i, EndLine: i}
+				body, depth = nil, 0
+				continue
+			}
+		}
+		if depth == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//")) {
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		body = append(body, line)
+		current.EndLine = i
+		depth = max(0, depth+bracketDepth(line))
+	}
+	finish()
+	return requests
+}
+
+// bracketDepth returns how much line changes the JSON nesting depth,
+// ignoring brackets inside strings.
+func bracketDepth(line string) int {
+	depth := 0
+	inString, escaped := false, false
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && r == '\\':
+			escaped = true
+		case r == '"':
+			inString = !inString
+		case inString:
+		case r == '{' || r == '[':
+			depth++
+		case r == '}' || r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// convertTripleQuotes turns Kibana's """multi-line strings""" into JSON
+// strings so the body can be sent as is.
+func convertTripleQuotes(body string) string {
+	return tripleQuoted.ReplaceAllStringFunc(body, func(s string) string {
+		quoted, _ := json.Marshal(strings.TrimSuffix(strings.TrimPrefix(s, `"""`), `"""`))
+		return string(quoted)
+	})
+}
+
+// ConsoleRequestAt returns the index of the request that contains line, or
+// of the closest request above it. It returns -1 when line is above the
+// first request.
+func ConsoleRequestAt(requests []ConsoleRequest, line int) int {
+	found := -1
+	for i, r := range requests {
+		if r.StartLine > line {
+			break
+		}
+		found = i
+	}
+	return found
+}
+
+// FormatConsoleRequest renders a request as a console block.
+func FormatConsoleRequest(method, path, body string) string {
+	block := method + " " + path
+	if body = strings.TrimSpace(body); body != "" {
+		block += "\n" + body
+	}
+	return block + "\n"
+}
+
+func loadConsoleFile(path string) (string, error) {
+	data, err := os.ReadFile(config.ExpandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(string(data), "\r\n", "\n"), nil
+}
+
+// fileExists reports whether saving to path would replace a file.
+func fileExists(path string) bool {
+	_, err := os.Stat(config.ExpandHome(path))
+	return err == nil
+}
+
+func saveConsoleFile(path, content string) error {
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return os.WriteFile(config.ExpandHome(path), []byte(content), 0o644)
+}
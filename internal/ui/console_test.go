@@ -0,0 +1,324 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
+	"github.com/labtiva/stoptail/internal/estest"
+)
+
+const runbook = `# Check the cluster first
+GET _cluster/health
+
+// Then look at the logs
+POST /logs/_search
+{
+  "query": {
+    "match": { "message": "}{ not a bracket" }
+  }
+}
+
+PUT /logs/_settings
+{"index": {"number_of_replicas": 0}}
+get /_cat/indices?v
+POST /_bulk
+{"index": {"_index": "logs"}}
+{"message": "one"}
+`
+
+func TestParseConsole(t *testing.T) {
+	got := ParseConsole(runbook)
+	want := []ConsoleRequest{
+		{Method: "GET", Path: "/_cluster/health", StartLine: 1, EndLine: 1},
+		{Method: "POST", Path: "/logs/_search", StartLine: 4, EndLine: 9,
+			Body: "{\n  \"query\": {\n    \"match\": { \"message\": \"}{ not a bracket\" }\n  }\n}"},
+		{Method: "PUT", Path: "/logs/_settings", StartLine: 11, EndLine: 12, Body: `{"index": {"number_of_replicas": 0}}`},
+		{Method: "GET", Path: "/_cat/indices?v", StartLine: 13, EndLine: 13},
+		{Method: "POST", Path: "/_bulk", StartLine: 14, EndLine: 16,
+			Body: "{\"index\": {\"_index\": \"logs\"}}\n{\"message\": \"one\"}"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d requests, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseConsoleRecoversFromUnbalancedBody(t *testing.T) {
+	got := ParseConsole("POST /a/_search\n{\n  \"query\": {\n\nGET /b/_count\n")
+	if len(got) != 2 || got[1].Path != "/b/_count" {
+		t.Errorf("an unindented request line should start a new request, got %+v", got)
+	}
+}
+
+func TestParseConsoleTripleQuotes(t *testing.T) {
+	got := ParseConsole("POST /_scripts/x\n{\"script\": {\"source\": \"\"\"\n  ctx._source.n += 1\n\"\"\"}}")
+	if len(got) != 1 || got[0].Body != `{"script": {"source": "\n  ctx._source.n += 1\n"}}` {
+		t.Errorf("triple-quoted strings should become JSON strings, got %q", got[0].Body)
+	}
+}
+
+func TestConsoleRequestAt(t *testing.T) {
+	requests := ParseConsole(runbook)
+	tests := []struct {
+		line int
+		want int
+	}{
+		{0, -1},
+		{1, 0},
+		{3, 0},
+		{4, 1},
+		{7, 1},
+		{12, 2},
+		{20, 4},
+	}
+	for _, tt := range tests {
+		if got := ConsoleRequestAt(requests, tt.line); got != tt.want {
+			t.Errorf("ConsoleRequestAt(line %d) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func moveEditorTo(w *WorkbenchModel, line int) {
+	w.editor.Focus()
+	defer w.editor.Blur()
+	for w.editor.Line() > line {
+		w.editor.Update(tea.KeyPressMsg{Code: tea.KeyUp})
+	}
+	for w.editor.Line() < line {
+		w.editor.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	}
+}
+
+// pump sends msg to m and feeds back the messages its commands return, the
+// way the program loop would, so a huh form in a modal can take focus and
+// submit. Commands that don't return quickly, such as cursor blinks, are
+// dropped.
+func pump[M any](m M, update func(M, tea.Msg) (M, tea.Cmd), msg tea.Msg) M {
+	queue := []tea.Msg{msg}
+	for n := 0; len(queue) > 0 && n < 100; n++ {
+		var cmd tea.Cmd
+		m, cmd = update(m, queue[0])
+		queue = append(queue[1:], runQuick(cmd)...)
+	}
+	return m
+}
+
+func runQuick(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	result := make(chan tea.Msg, 1)
+	go func() { result <- cmd() }()
+	var msg tea.Msg
+	select {
+	case msg = <-result:
+	case <-time.After(20 * time.Millisecond):
+		return nil
+	}
+	// tea.BatchMsg and the sequence huh starts with are both lists of commands.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice && v.Type().Elem() == reflect.TypeFor[tea.Cmd]() {
+		var msgs []tea.Msg
+		for i := range v.Len() {
+			msgs = append(msgs, runQuick(v.Index(i).Interface().(tea.Cmd))...)
+		}
+		return msgs
+	}
+	if msg == nil {
+		return nil
+	}
+	return []tea.Msg{msg}
+}
+
+func updateWorkbench(w WorkbenchModel, msg tea.Msg) (WorkbenchModel, tea.Cmd) {
+	return w.Update(msg)
+}
+
+// runWorkbench feeds the results of cmd back into w until no request is left.
+func runWorkbench(w WorkbenchModel, cmd tea.Cmd) WorkbenchModel {
+	for cmd != nil {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			cmd = nil
+			for _, c := range batch {
+				if result, ok := c().(executeResultMsg); ok {
+					w, cmd = w.Update(result)
+				}
+			}
+			continue
+		}
+		result, ok := msg.(executeResultMsg)
+		if !ok {
+			break
+		}
+		w, cmd = w.Update(result)
+	}
+	return w
+}
+
+func TestConsoleRunsBlocks(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	srv := estest.NewServer(t, estest.Fixture{Indices: []estest.Index{
+		{Name: "logs", Docs: []map[string]any{{"message": "one"}, {"message": "two"}}},
+	}})
+	client, err := es.NewClient(&config.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := NewWorkbench()
+	w.SetClient(client)
+	w.SetSize(120, 40)
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl})
+	if w.queryMode != ModeConsole {
+		t.Fatal("ctrl+k should switch to console mode")
+	}
+	w.editor.SetContent("GET /logs/_count\n\nGET /missing/_search\n\nGET /logs/_search\n{\"size\": 1}\n")
+
+	moveEditorTo(&w, 5)
+	w, cmd := w.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl})
+	w = runWorkbench(w, cmd)
+	if w.statusCode != 200 || !strings.Contains(w.responseRawText, `"hits"`) {
+		t.Errorf("ctrl+r should run the block under the cursor, got %d %q", w.statusCode, w.responseRawText)
+	}
+
+	w, cmd = w.Update(tea.KeyPressMsg{Code: 'r', Mod: tea.ModAlt})
+	w = runWorkbench(w, cmd)
+	if w.executing {
+		t.Fatal("run all should finish")
+	}
+	for _, want := range []string{"# GET /logs/_count  200", `"count": 2`, "# GET /missing/_search  404", "# GET /logs/_search  200"} {
+		if !strings.Contains(w.responseRawText, want) {
+			t.Errorf("run all output should contain %q:\n%s", want, w.responseRawText)
+		}
+	}
+	if !strings.HasPrefix(w.duration, "3 requests, 1 failed") {
+		t.Errorf("duration = %q, want a run summary", w.duration)
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl})
+	if w.queryMode != ModeREST || strings.Contains(w.editor.Content(), "_count") {
+		t.Error("ctrl+k should switch back to the REST editor")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'k', Mod: tea.ModCtrl})
+	if !strings.Contains(w.editor.Content(), "GET /logs/_count") {
+		t.Error("the console buffer should survive switching modes")
+	}
+}
+
+func TestConsoleFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "runbook.txt")
+
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.toggleConsole()
+	w.editor.SetContent("GET /_cluster/health")
+
+	type key = tea.KeyPressMsg
+	w = pump(w, updateWorkbench, key{Code: 's', Mod: tea.ModAlt})
+	if w.modal == nil || w.modal.Type() != ModalSaveFile {
+		t.Fatal("alt+s in console mode should ask for a file")
+	}
+	w = pump(w, updateWorkbench, tea.PasteMsg{Content: path})
+	w = pump(w, updateWorkbench, key{Code: tea.KeyEnter})
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "GET /_cluster/health\n" {
+		t.Fatalf("saved file = %q, %v", data, err)
+	}
+	if w.modal != nil || w.ClipboardMessage() != "Saved "+path {
+		t.Errorf("modal should close and report the save, message %q", w.ClipboardMessage())
+	}
+
+	os.WriteFile(path, []byte("GET /a\r\nGET /b\r\n"), 0o644)
+	w.editor.SetContent("")
+	w = pump(w, updateWorkbench, key{Code: 'o', Mod: tea.ModCtrl})
+	w = pump(w, updateWorkbench, key{Code: tea.KeyEnter})
+	if w.editor.Content() != "GET /a\nGET /b\n" {
+		t.Errorf("loaded content = %q", w.editor.Content())
+	}
+
+	w = pump(w, updateWorkbench, key{Code: 'o', Mod: tea.ModCtrl})
+	w = pump(w, updateWorkbench, tea.PasteMsg{Content: "-missing"})
+	w = pump(w, updateWorkbench, key{Code: tea.KeyEnter})
+	if w.modal == nil || w.modal.Type() != ModalError || !strings.Contains(w.modal.err, "no such file") {
+		t.Error("a failed load should show the error")
+	}
+}
+
+func TestConsoleSaveAsksBeforeOverwriting(t *testing.T) {
+	dir := t.TempDir()
+	mine := filepath.Join(dir, "mine.txt")
+	other := filepath.Join(dir, "other.txt")
+	os.WriteFile(mine, []byte("GET /mine\n"), 0o644)
+	os.WriteFile(other, []byte("GET /other\n"), 0o644)
+
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.toggleConsole()
+
+	type key = tea.KeyPressMsg
+	// enterPath replaces the path the file modal starts from.
+	enterPath := func(w WorkbenchModel, path string) WorkbenchModel {
+		w = pump(w, updateWorkbench, key{Code: 'u', Mod: tea.ModCtrl})
+		w = pump(w, updateWorkbench, tea.PasteMsg{Content: path})
+		return pump(w, updateWorkbench, key{Code: tea.KeyEnter})
+	}
+	save := func(w WorkbenchModel, path string) WorkbenchModel {
+		return enterPath(pump(w, updateWorkbench, key{Code: 's', Mod: tea.ModAlt}), path)
+	}
+
+	w.editor.SetContent("GET /new")
+	w = save(w, other)
+	if w.modal == nil || w.modal.Type() != ModalOverwrite {
+		t.Fatal("saving over an existing file should ask first")
+	}
+	w = pump(w, updateWorkbench, key{Code: 'n', Text: "n"})
+	if data, _ := os.ReadFile(other); string(data) != "GET /other\n" || w.modal != nil {
+		t.Fatalf("declining should leave the file alone, got %q", data)
+	}
+	w = save(w, other)
+	w = pump(w, updateWorkbench, key{Code: 'y', Text: "y"})
+	if data, _ := os.ReadFile(other); string(data) != "GET /new\n" || w.ClipboardMessage() != "Saved "+other {
+		t.Fatalf("confirming should save, got %q", data)
+	}
+
+	w = enterPath(pump(w, updateWorkbench, key{Code: 'o', Mod: tea.ModCtrl}), mine)
+	w.editor.SetContent("GET /edited")
+	w = save(w, mine)
+	if data, _ := os.ReadFile(mine); string(data) != "GET /edited\n" || w.modal != nil {
+		t.Errorf("saving back to the opened file should not ask, got %q", data)
+	}
+}
+
+func TestConsoleBookmarkSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.toggleConsole()
+	w.editor.SetContent(runbook)
+	moveEditorTo(&w, 4)
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	if !w.bookmarkUI.Active() {
+		t.Fatal("ctrl+s in console mode should save a bookmark")
+	}
+	for _, r := range "logs" {
+		w, _ = w.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	b := w.bookmarks.Get("logs")
+	if b == nil || b.Method != "POST" || b.Path != "/logs/_search" || strings.Contains(b.Body, "_cluster") {
+		t.Errorf("bookmark = %+v, want the request under the cursor", b)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"charm.land/bubbles/v2/textarea"
+	"github.com/charmbracelet/x/ansi"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/labtiva/stoptail/internal/es"
@@ -418,6 +419,23 @@ func (e Editor) Line() int {
 	return e.textarea.Line()
 }
 
+// ViewRow returns the row of View where line (0-based) starts, or -1 when
+// it is scrolled out of sight. Long lines wrap onto several rows.
+func (e Editor) ViewRow(line int) int {
+	lines := strings.Split(e.textarea.Value(), "\n")
+	if line < 0 || line >= len(lines) {
+		return -1
+	}
+	row := -e.textarea.ScrollYOffset()
+	for _, l := range lines[:line] {
+		row += strings.Count(ansi.Wordwrap(l, e.textarea.Width(), ""), "\n") + 1
+	}
+	if row < 0 || row >= e.textarea.Height() {
+		return -1
+	}
+	return row
+}
+
 func (e Editor) LineInfo() textarea.LineInfo {
 	return e.textarea.LineInfo()
 }
@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/labtiva/stoptail/internal/config"
+)
+
+// exportHint tells the save modal which extension picks which format.
+const exportHint = ".json, .ndjson (hits) or .csv (table)"
+
+// exportResponse writes a JSON response to path in the format named by the
+// extension: .json for the text as shown, .ndjson (or .jsonl) for one hit,
+// array element or filter result per line, and .csv for the table view.
+// It returns what was written, for the status line.
+func exportResponse(path, text string, table *ResultTable) (string, error) {
+	var data, what string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		data, what = text, "JSON"
+	case ".ndjson", ".jsonl":
+		lines, err := ndjsonLines(text)
+		if err != nil {
+			return "", err
+		}
+		data, what = strings.Join(lines, "\n"), fmt.Sprintf("%d lines", len(lines))
+	case ".csv":
+		if table == nil {
+			return "", fmt.Errorf("CSV needs search hits, aggregations or ES|QL results")
+		}
+		data, what = table.CSV(), fmt.Sprintf("%d rows", len(table.src().order))
+	default:
+		return "", fmt.Errorf("unknown file type %q: use .json, .ndjson or .csv", ext)
+	}
+	if !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	if err := os.WriteFile(config.ExpandHome(path), []byte(data), 0o644); err != nil {
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return what, nil
+}
+
+// ndjsonLines returns the hits of a search response, the elements of an
+// array or each of several values, one compact JSON document each.
+func ndjsonLines(text string) ([]string, error) {
+	values, err := decodeStream(text)
+	if err != nil {
+		return nil, fmt.Errorf("NDJSON needs a JSON response: %w", err)
+	}
+	items := values
+	if len(values) == 1 {
+		switch v := values[0].(type) {
+		case []any:
+			items = v
+		case orderedObject:
+			if hits, ok := searchHits(v); ok {
+				items = hits
+			}
+		}
+	}
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = compactJSON(item)
+	}
+	return lines, nil
+}
@@ -0,0 +1,166 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+func TestExportResponse(t *testing.T) {
+	dir := t.TempDir()
+	table, err := NewResultTable(searchResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		file     string
+		text     string
+		table    *ResultTable
+		wantWhat string
+		want     string // prefix of the file
+		wantErr  bool
+	}{
+		{"json", "out.json", "{\n  \"a\": 1\n}", nil, "JSON", "{\n  \"a\": 1\n}\n", false},
+		{"ndjson hits", "hits.ndjson", searchResult, table, "2 lines",
+			`{"_index":"logs","_id":"1","_score":1.0,"_source":{"user":{"name":"ann","age":31}`, false},
+		{"ndjson array", "rows.jsonl", `[{"a": 1}, [2]]`, nil, "2 lines", "{\"a\":1}\n[2]\n", false},
+		{"ndjson filter results", "ids.ndjson", "\"1\"\n\"2\"", nil, "2 lines", "\"1\"\n\"2\"\n", false},
+		{"csv", "hits.csv", searchResult, table, "2 rows", "_id,user.name,user.age,tags,msg,extra\n1,ann,31,", false},
+		{"csv without a table", "x.csv", `{"acknowledged": true}`, nil, "", "", true},
+		{"ndjson of text", "x.ndjson", "Error: boom", nil, "", "", true},
+		{"unknown extension", "x.txt", "{}", nil, "", "", true},
+		{"missing directory", "nope/x.json", "{}", nil, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			what, err := exportResponse(path, tt.text, tt.table)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("exportResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, _ := os.ReadFile(path)
+			if what != tt.wantWhat || !strings.HasPrefix(string(data), tt.want) {
+				t.Errorf("exportResponse() = %q, file\n%s\nwant %q, file starting\n%s", what, data, tt.wantWhat, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkbenchExport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "hits.csv")
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.focus = FocusResponse
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: 'e', Mod: tea.ModAlt})
+	if w.modal != nil || w.ClipboardMessage() != "Nothing to save yet" {
+		t.Error("there is nothing to save before the first response")
+	}
+
+	w = showResult(w, searchResult)
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'T', Text: "T"})
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeySpace, Text: " "})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 's', Mod: tea.ModCtrl})
+	if !w.bookmarkUI.Active() {
+		t.Fatal("ctrl+s in the response should still save a bookmark")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: 'e', Mod: tea.ModAlt})
+	if w.modal == nil || w.modal.Type() != ModalExport || !strings.Contains(w.View(), "Save response") {
+		t.Fatal("alt+e in the response should ask for a file")
+	}
+	w = pump(w, updateWorkbench, tea.PasteMsg{Content: path})
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: tea.KeyEnter})
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.HasPrefix(string(data), "user.name,user.age,") {
+		t.Fatalf("saved file = %q, %v; CSV should follow the table's columns", data, err)
+	}
+	if w.modal != nil || w.ClipboardMessage() != "Saved 2 rows to "+path {
+		t.Errorf("message = %q", w.ClipboardMessage())
+	}
+
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: 'e', Mod: tea.ModAlt})
+	for range len("csv") {
+		w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+	w = pump(w, updateWorkbench, tea.PasteMsg{Content: "xml"})
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if w.modal == nil || w.modal.Type() != ModalError || !strings.Contains(w.modal.err, "unknown file type") {
+		t.Error("an unknown extension should show the error")
+	}
+
+	os.WriteFile(path, []byte("keep me\n"), 0o644)
+	w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: tea.KeyEscape})
+	for _, answer := range []rune{'n', 'y'} {
+		w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: 'e', Mod: tea.ModAlt})
+		w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+		w = pump(w, updateWorkbench, tea.PasteMsg{Content: path})
+		w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: tea.KeyEnter})
+		if w.modal == nil || w.modal.Type() != ModalOverwrite || !strings.Contains(w.View(), "Overwrite") {
+			t.Fatal("saving over an existing file should ask first")
+		}
+		w = pump(w, updateWorkbench, tea.KeyPressMsg{Code: answer, Text: string(answer)})
+		data, _ := os.ReadFile(path)
+		if kept := string(data) == "keep me\n"; kept != (answer == 'n') {
+			t.Errorf("after %q the file is %q", answer, data)
+		}
+	}
+}
+
+func TestBrowserExport(t *testing.T) {
+	dir := t.TempDir()
+	b := NewBrowser()
+	b.SetSize(160, 40)
+	b.documents = []es.DocumentHit{
+		{Index: "logs", ID: "1", Source: `{"user": {"name": "ann"}}`},
+		{Index: "logs", ID: "2", Source: `{"user": {"name": "bob"}}`},
+	}
+	b.activePane = BrowserPaneDocs
+	b.updateDetailPane()
+
+	update := func(b BrowserModel, msg tea.Msg) (BrowserModel, tea.Cmd) { return b.Update(msg) }
+	save := func(file string) string {
+		b = pump(b, update, tea.KeyPressMsg{Code: 'e', Mod: tea.ModAlt})
+		if !b.HasActiveInput() {
+			t.Fatal("alt+e should open the save modal")
+		}
+		b = pump(b, update, tea.KeyPressMsg{Code: 'u', Mod: tea.ModCtrl})
+		b = pump(b, update, tea.PasteMsg{Content: filepath.Join(dir, file)})
+		b = pump(b, update, tea.KeyPressMsg{Code: tea.KeyEnter})
+		data, _ := os.ReadFile(filepath.Join(dir, file))
+		return string(data)
+	}
+	if got := save("docs.ndjson"); got != "{\"_index\":\"logs\",\"_id\":\"1\",\"_source\":{\"user\":{\"name\":\"ann\"}}}\n{\"_index\":\"logs\",\"_id\":\"2\",\"_source\":{\"user\":{\"name\":\"bob\"}}}\n" {
+		t.Errorf("docs.ndjson = %q", got)
+	}
+	if got := save("docs.csv"); got != "_id,user.name\n1,ann\n2,bob\n" {
+		t.Errorf("docs.csv = %q", got)
+	}
+
+	b.activePane = BrowserPaneDetail
+	if got := save("doc.json"); got != "{\n  \"user\": {\n    \"name\": \"ann\"\n  }\n}\n" {
+		t.Errorf("doc.json = %q", got)
+	}
+	if b.ClipboardMessage() != "Saved JSON to "+filepath.Join(dir, "doc.json") {
+		t.Errorf("message = %q", b.ClipboardMessage())
+	}
+	if got := save("docs.ndjson"); !strings.Contains(got, `"_id":"2"`) || b.modal == nil || b.modal.Type() != ModalOverwrite {
+		t.Fatalf("saving over docs.ndjson should ask first, file = %q", got)
+	}
+	b = pump(b, update, tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if data, _ := os.ReadFile(filepath.Join(dir, "docs.ndjson")); string(data) != "{\"user\":{\"name\":\"ann\"}}\n" {
+		t.Errorf("docs.ndjson after confirming = %q", data)
+	}
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+// fieldValueCount is how many of the most common values are offered.
+const fieldValueCount = 20
+
+// valueQueries take field values, either directly ("status": "ok") or
+// under the named key of their long form ("status": {"value": "ok"}).
+// terms takes an array.
+var valueQueries = map[string]string{"term": "value", "terms": "", "match": "query"}
+
+// fieldValueContext reports the field whose value is being typed at the
+// end of text, and the partial value when the cursor is inside its quotes.
+func fieldValueContext(text string) (field, partial string, ok bool) {
+	ctx := ParseJSONContext(text)
+	bracket, quote := openBracketAndString(text)
+	path := ctx.Path
+	n := len(path)
+	if n < 2 {
+		return "", "", false
+	}
+
+	_, direct := valueQueries[path[n-2]]
+	switch {
+	case path[n-2] == "terms":
+		if bracket == '[' {
+			field = path[n-1]
+		}
+	case direct && ctx.InValue && bracket == '{':
+		field = path[n-1]
+	case n >= 3 && path[n-1] != "" && valueQueries[path[n-3]] == path[n-1] && ctx.InValue && bracket == '{':
+		field = path[n-2]
+	}
+	if field == "" {
+		return "", "", false
+	}
+	if quote >= 0 {
+		partial = text[quote+1:]
+	} else if strings.TrimSpace(text[strings.LastIndexAny(text, ":[,")+1:]) != "" {
+		return "", "", false
+	}
+	return field, partial, true
+}
+
+// openBracketAndString returns the innermost open bracket at the end of
+// text and the offset of the quote opening an unterminated string, or -1.
+func openBracketAndString(text string) (bracket byte, quote int) {
+	var stack []byte
+	quote = -1
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if quote >= 0 {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = -1
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			quote = i
+		case '{', '[':
+			stack = append(stack, c)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if len(stack) > 0 {
+		bracket = stack[len(stack)-1]
+	}
+	return bracket, quote
+}
+
+// fieldValueItems turns the buckets of a terms aggregation into
+// completions.
+func fieldValueItems(values []es.FieldValue) []CompletionItem {
+	items := make([]CompletionItem, 0, len(values))
+	for _, v := range values {
+		items = append(items, CompletionItem{Text: v.Value, Kind: fmt.Sprintf("%d docs", v.Count)})
+	}
+	return items
+}
+
+// quoteJSON renders value as a JSON string without its surrounding quotes.
+func quoteJSON(value string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+	return strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(buf.String()), `"`), `"`)
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+func TestFieldValueContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		wantField   string
+		wantPartial string
+		wantOK      bool
+	}{
+		{"term value", `{"query": {"term": {"status": "`, "status", "", true},
+		{"term partial", `{"query": {"term": {"status": "act`, "status", "act", true},
+		{"term before quote", `{"query": {"term": {"status": `, "status", "", true},
+		{"term long form", `{"query": {"term": {"status": {"value": "o`, "status", "o", true},
+		{"match", `{"query": {"bool": {"filter": [{"match": {"level": "`, "level", "", true},
+		{"match long form", `{"query": {"match": {"level": {"query": "`, "level", "", true},
+		{"terms array", `{"query": {"terms": {"status": ["active", "`, "status", "", true},
+		{"terms without array", `{"query": {"terms": {"status": "`, "", "", false},
+		{"key position", `{"query": {"term": {"`, "", "", false},
+		{"number typed", `{"query": {"term": {"age": 4`, "", "", false},
+		{"other query", `{"query": {"prefix": {"status": "`, "", "", false},
+		{"match option", `{"query": {"match": {"level": {"operator": "`, "", "", false},
+		{"closed value", `{"query": {"term": {"status": "a"}}, "size": `, "", "", false},
+		{"empty", ``, "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, partial, ok := fieldValueContext(tt.text)
+			if field != tt.wantField || partial != tt.wantPartial || ok != tt.wantOK {
+				t.Errorf("fieldValueContext(%q) = %q, %q, %v; want %q, %q, %v",
+					tt.text, field, partial, ok, tt.wantField, tt.wantPartial, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWorkbenchFieldValueCompletion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 30)
+	w.path.SetValue("/logs/_search")
+	w.checkIndexChange()
+	w, _ = w.Update(mappingResultMsg{index: "logs", fields: []es.FieldType{
+		{Name: "message", Type: "text"}, {Name: "status", Type: "keyword"},
+	}})
+	w.focus = FocusBody
+	w.editor.Focus()
+
+	w.editor.SetContent(`{"query": {"term": {"message": `)
+	if _, ok := w.valueCompletionField(); ok {
+		t.Error("text fields should not get value suggestions")
+	}
+
+	w.editor.SetContent(`{"query": {"term": {"status": }}}`)
+	w.editor.SetCursor(strings.Index(w.editor.Content(), "}"))
+	w, _ = w.Update(fieldValuesMsg{index: "logs", field: "status"})
+	if w.completion.Active {
+		t.Error("values arriving without a request should not open a list")
+	}
+	w.valueCache["logs"] = map[string][]CompletionItem{}
+	w.valueCache["logs"]["status"] = nil // fetch in flight
+	w, _ = w.Update(fieldValuesMsg{index: "logs", field: "status", err: errors.New("timed out")})
+	if _, cached := w.valueCache["logs"]["status"]; cached || w.ClipboardMessage() != "Values of status: timed out" {
+		t.Errorf("a failed fetch should be reported and not cached, message %q", w.ClipboardMessage())
+	}
+	w.valueCache["logs"]["status"] = nil
+	w, _ = w.Update(tea.KeyPressMsg{Code: '"', Text: "\""})
+	if w.completion.Active {
+		t.Error("no list should open before the values arrive")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	w, _ = w.Update(fieldValuesMsg{index: "logs", field: "status", values: []es.FieldValue{
+		{Value: "active", Count: 12}, {Value: "archived", Count: 3}, {Value: `say "hi"`, Count: 1},
+	}})
+	if !w.completion.Active || !w.completion.Values || len(w.completion.Filtered) != 2 {
+		t.Fatalf("completion = %+v, want the two values starting with a", w.completion)
+	}
+	if w.completion.Filtered[0].Kind != "12 docs" {
+		t.Errorf("Kind = %q, want the doc count", w.completion.Filtered[0].Kind)
+	}
+	if view := w.View(); !strings.Contains(view, "archived (3 docs)") {
+		t.Error("the dropdown should list the values")
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := w.editor.Content(); got != `{"query": {"term": {"status": "archived"}}}` {
+		t.Errorf("content = %s", got)
+	}
+	if w.completion.Active {
+		t.Error("completion should close after accepting")
+	}
+
+	w.editor.SetContent(`{"query": {"terms": {"status": [`)
+	w.openValueCompletion("status")
+	w.completion.Filter("say")
+	w.acceptCompletion()
+	if got := w.editor.Content(); got != `{"query": {"terms": {"status": ["say \"hi\""` {
+		t.Errorf("content = %s, want the value quoted and escaped", got)
+	}
+}
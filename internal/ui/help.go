@@ -14,6 +14,11 @@ var helpGlobal = `## Global
 | ? / Esc | Toggle help |
 | r | Refresh |
 | S | Shard calculator |
+| C | Switch cluster |
+| ] / [ | Next/prev session |
+| V | Compare sessions |
+| X | Close session |
+| L | Audit log (from help) |
 `
 
 var helpOverview = `## Overview
@@ -41,13 +46,34 @@ var helpWorkbench = `## Workbench
 | Enter | Edit |
 | Tab | Autocomplete / next |
 | Ctrl+R | Execute |
+| Esc/Ctrl+C | Cancel running request |
 | Ctrl+E | Toggle REST/ES|QL |
+| Ctrl+K | Toggle console |
+| Alt+R | Run all (console) |
+| Ctrl+O | Open file (console) |
+| Alt+S | Save file (console) |
 | Alt+F | Format JSON |
+| Alt+C | Copy as curl |
+| Alt+V | Show request with {{variables}} filled in |
 | Ctrl+S | Save bookmark |
+| Alt+E | Save response to file |
 | Ctrl+B | Load bookmark |
 | Ctrl+F | Search response |
 | Enter/n | Next match |
 | Shift+Enter/N | Prev match |
+| t | Tree view (response) |
+| Enter/Left/Right | Fold/unfold (tree) |
+| -/+ | Collapse/expand all (tree) |
+| y | Copy value (tree) |
+| T | Table view (response) |
+| s | Sort by column (table) |
+| </> | Resize column (table) |
+| c | Choose columns (table) |
+| [/] | Hits / aggregations (table) |
+| P | Profile view (response) |
+| Enter, -/+ | Timing breakdown (profile) |
+| s | Next slowest (profile) |
+| / | jq filter (response) |
 | Ctrl+Y | Copy body/response |
 | Ctrl+A | Select all (body) |
 | Ctrl+C | Copy selection |
@@ -68,6 +94,8 @@ var helpBrowser = `## Browser
 | left/right | Switch panes |
 | up/down | Scroll / select |
 | Enter | Load documents |
+| / | jq filter (document) |
+| Alt+E | Save documents to file |
 | Ctrl+Y | Copy document |
 `
 
@@ -136,7 +164,7 @@ func renderHelp(width, height, activeTab int) string {
 		tabHelp = helpTasks
 	}
 
-	content, _ := r.Render(helpGlobal + tabHelp + "\n*Press ? or Esc to close*")
+	content, _ := r.Render(helpGlobal + tabHelp + "\n*Press L for this session's audit log, ? or Esc to close*")
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"strings"
 	"testing"
 	tea "charm.land/bubbletea/v2"
 )
@@ -40,3 +41,24 @@ func TestHelpToggle(t *testing.T) {
 		t.Fatal("expected showHelp to be false after pressing ? to close")
 	}
 }
+
+func TestHelpOpensAuditLog(t *testing.T) {
+	m := Model{auditView: NewAuditView()}
+	m.width = 120
+	m.height = 40
+
+	newM, _ := m.Update(tea.KeyPressMsg{Code: '?', Text: "?"})
+	newM, _ = newM.(Model).Update(tea.KeyPressMsg{Code: 'L', Text: "L"})
+	m = newM.(Model)
+	if m.showHelp || !m.showAudit {
+		t.Fatal("expected L in help to open the audit log")
+	}
+	if view := m.View(); !strings.Contains(view.Content, "Audit Log") {
+		t.Error("audit log view should render its title")
+	}
+
+	newM, _ = m.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if newM.(Model).showAudit {
+		t.Fatal("expected esc to close the audit log")
+	}
+}
@@ -0,0 +1,865 @@
+package ui
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"charm.land/bubbles/v2/textinput"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+)
+
+// orderedObject is a decoded JSON object that keeps its key order, so
+// filtered output reads like the document it came from.
+type orderedObject []objectMember
+
+type objectMember struct {
+	key   string
+	value any
+}
+
+// get returns the value of key and whether the object has it.
+func (o orderedObject) get(key string) (any, bool) {
+	for _, m := range o {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+func decodeOrdered(text string) (any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	v, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+	return v, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := orderedObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, objectMember{key: key.(string), value: v})
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			v, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err := dec.Token()
+		return arr, err
+	}
+	return tok, nil
+}
+
+// compactJSON renders v on one line.
+func compactJSON(v any) string {
+	var b strings.Builder
+	writeOrdered(&b, v, "")
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(b.String())); err != nil {
+		return b.String()
+	}
+	return compact.String()
+}
+
+func writeOrdered(b *strings.Builder, v any, indent string) {
+	inner := indent + "  "
+	switch v := v.(type) {
+	case orderedObject:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		for i, m := range v {
+			b.WriteString(inner + jsonText(m.key) + ": ")
+			writeOrdered(b, m.value, inner)
+			if i < len(v)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "}")
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for i, e := range v {
+			b.WriteString(inner)
+			writeOrdered(b, e, inner)
+			if i < len(v)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+	default:
+		b.WriteString(jsonText(v))
+	}
+}
+
+// jqFilter maps one input value to zero or more outputs, like a jq filter.
+type jqFilter func(v any) ([]any, error)
+
+// compileFilter parses the subset of jq used to dig through responses:
+// paths (.a.b, ."a b", .[0], .[-1], .[2:5], .[], ..), pipes, keys, length,
+// map(f) and select(f), where select may compare f to a JSON literal with
+// ==, !=, <, <=, > or >=. A trailing ? ignores errors. Expressions starting
+// with $ are read as JSONPath ($.a[*].b, $..b, $['a']).
+func compileFilter(expr string) (jqFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "$"); ok {
+		switch {
+		case rest == "":
+			rest = "."
+		case strings.HasPrefix(rest, "["):
+			rest = "." + rest
+		}
+		expr = strings.NewReplacer("[*]", "[]", ".*", "[]").Replace(rest)
+	}
+	p := &filterParser{src: expr}
+	f, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:])
+	}
+	return f, nil
+}
+
+// applyFilter runs expr against doc and returns the outputs as indented
+// JSON, one after another.
+func applyFilter(expr string, doc any) (string, int, error) {
+	f, err := compileFilter(expr)
+	if err != nil {
+		return "", 0, err
+	}
+	outs, err := f(doc)
+	if err != nil {
+		return "", 0, err
+	}
+	var b strings.Builder
+	for i, v := range outs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		writeOrdered(&b, v, "")
+	}
+	return b.String(), len(outs), nil
+}
+
+type filterParser struct {
+	src string
+	pos int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("at %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) rest() string {
+	return p.src[p.pos:]
+}
+
+func (p *filterParser) skipSpace() {
+	for p.pos < len(p.src) && p.src[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *filterParser) consume(s string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.rest(), s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func isIdentByte(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && (c >= '0' && c <= '9' || c == '-')
+}
+
+func (p *filterParser) atName() bool {
+	return p.pos < len(p.src) && (isIdentByte(p.src[p.pos], true) || p.src[p.pos] == '"')
+}
+
+// name reads an identifier or a quoted key.
+func (p *filterParser) name() (string, error) {
+	if p.pos < len(p.src) && (p.src[p.pos] == '"' || p.src[p.pos] == '\'') {
+		return p.quoted()
+	}
+	start := p.pos
+	for p.pos < len(p.src) && isIdentByte(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", p.errorf("expected a key")
+	}
+	return p.src[start:p.pos], nil
+}
+
+func (p *filterParser) quoted() (string, error) {
+	quote := p.src[p.pos]
+	end := p.pos + 1
+	for end < len(p.src) && p.src[end] != quote {
+		if p.src[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end >= len(p.src) {
+		return "", p.errorf("unterminated string")
+	}
+	raw := p.src[p.pos : end+1]
+	p.pos = end + 1
+	if quote == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+	var s string
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return "", p.errorf("invalid string %s", raw)
+	}
+	return s, nil
+}
+
+func (p *filterParser) parsePipe() (jqFilter, error) {
+	f, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("|") {
+		g, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		f = pipeFilters(f, g)
+	}
+	return f, nil
+}
+
+func (p *filterParser) parseTerm() (jqFilter, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("expected an expression")
+	}
+	if p.src[p.pos] == '.' {
+		return p.parseSteps(identityFilter)
+	}
+	if p.consume("[") {
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("expected ]")
+		}
+		return p.parseSteps(collectFilter(inner))
+	}
+	if !isIdentByte(p.src[p.pos], true) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+	}
+	fn, _ := p.name()
+	var f jqFilter
+	switch fn {
+	case "keys":
+		f = keysFilter
+	case "length":
+		f = lengthFilter
+	case "map", "select":
+		if !p.consume("(") {
+			return nil, p.errorf("expected ( after %s", fn)
+		}
+		arg, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if fn == "map" {
+			f = mapFilter(arg)
+		} else {
+			op, lit, err := p.parseComparison()
+			if err != nil {
+				return nil, err
+			}
+			f = selectFilter(arg, op, lit)
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("expected )")
+		}
+	default:
+		return nil, fmt.Errorf("unknown function %s (supported: keys, length, map, select)", fn)
+	}
+	return p.parseSteps(f)
+}
+
+var comparisonOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseComparison reads an optional "op literal" after a select condition.
+func (p *filterParser) parseComparison() (string, any, error) {
+	p.skipSpace()
+	for _, op := range comparisonOps {
+		if !p.consume(op) {
+			continue
+		}
+		p.skipSpace()
+		dec := json.NewDecoder(strings.NewReader(p.rest()))
+		dec.UseNumber()
+		var lit any
+		if err := dec.Decode(&lit); err != nil {
+			return "", nil, p.errorf("expected a JSON value after %s", op)
+		}
+		p.pos += int(dec.InputOffset())
+		return op, lit, nil
+	}
+	return "", nil, nil
+}
+
+// parseSteps reads the path steps that follow a term.
+func (p *filterParser) parseSteps(f jqFilter) (jqFilter, error) {
+	for p.pos < len(p.src) {
+		rest := p.rest()
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			p.pos += 2
+			f = pipeFilters(f, recurseFilter)
+			if p.atName() {
+				// $..name: only the objects that have the key.
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				f = pipeFilters(f, fieldFilter(name, true))
+			}
+		case strings.HasPrefix(rest, ".["):
+			p.pos++
+		case rest[0] == '.':
+			p.pos++
+			if !p.atName() {
+				// A lone "." is the input itself.
+				if p.pos < len(p.src) && p.src[p.pos] != ' ' && p.src[p.pos] != '|' && p.src[p.pos] != ')' {
+					return nil, p.errorf("expected a key after .")
+				}
+				continue
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			f = pipeFilters(f, fieldFilter(name, false))
+		case rest[0] == '[':
+			step, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			f = pipeFilters(f, step)
+		case rest[0] == '?':
+			p.pos++
+			f = optionalFilter(f)
+		default:
+			return f, nil
+		}
+	}
+	return f, nil
+}
+
+func (p *filterParser) parseBracket() (jqFilter, error) {
+	p.pos++ // [
+	p.skipSpace()
+	rest := p.rest()
+	switch {
+	case strings.HasPrefix(rest, "]"):
+		p.pos++
+		return iterateFilter, nil
+	case strings.HasPrefix(rest, "?("):
+		return nil, p.errorf("JSONPath filters are not supported, use select()")
+	case strings.HasPrefix(rest, "\"") || strings.HasPrefix(rest, "'"):
+		name, err := p.quoted()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("expected ]")
+		}
+		return fieldFilter(name, false), nil
+	}
+
+	from, hasFrom, err := p.integer()
+	if err != nil {
+		return nil, err
+	}
+	if p.consume(":") {
+		to, hasTo, err := p.integer()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("expected ]")
+		}
+		return sliceFilter(from, hasFrom, to, hasTo), nil
+	}
+	if !hasFrom || !p.consume("]") {
+		return nil, p.errorf("expected an index, a slice or ]")
+	}
+	return indexFilter(from), nil
+}
+
+func (p *filterParser) integer() (int, bool, error) {
+	p.skipSpace()
+	start := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	if p.pos == start {
+		return 0, false, nil
+	}
+	n, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid index %q", p.src[start:p.pos])
+	}
+	return n, true, nil
+}
+
+func identityFilter(v any) ([]any, error) {
+	return []any{v}, nil
+}
+
+func pipeFilters(f, g jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		outs, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		var result []any
+		for _, o := range outs {
+			r, err := g(o)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, r...)
+		}
+		return result, nil
+	}
+}
+
+func optionalFilter(f jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		outs, err := f(v)
+		if err != nil {
+			return nil, nil
+		}
+		return outs, nil
+	}
+}
+
+// fieldFilter looks up key. Missing keys give null, except after .. where
+// only the objects that have the key produce output.
+func fieldFilter(key string, recursive bool) jqFilter {
+	return func(v any) ([]any, error) {
+		switch v := v.(type) {
+		case orderedObject:
+			for _, m := range v {
+				if m.key == key {
+					return []any{m.value}, nil
+				}
+			}
+			if recursive {
+				return nil, nil
+			}
+			return []any{nil}, nil
+		case nil:
+			if recursive {
+				return nil, nil
+			}
+			return []any{nil}, nil
+		}
+		if recursive {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with %q", jsonTypeName(v), key)
+	}
+}
+
+func indexFilter(i int) jqFilter {
+	return func(v any) ([]any, error) {
+		switch v := v.(type) {
+		case []any:
+			if i < 0 {
+				i += len(v)
+			}
+			if i < 0 || i >= len(v) {
+				return []any{nil}, nil
+			}
+			return []any{v[i]}, nil
+		case nil:
+			return []any{nil}, nil
+		}
+		return nil, fmt.Errorf("cannot index %s with a number", jsonTypeName(v))
+	}
+}
+
+func sliceFilter(from int, hasFrom bool, to int, hasTo bool) jqFilter {
+	return func(v any) ([]any, error) {
+		arr, ok := v.([]any)
+		if !ok {
+			if v == nil {
+				return []any{nil}, nil
+			}
+			return nil, fmt.Errorf("cannot slice %s", jsonTypeName(v))
+		}
+		start, end := 0, len(arr)
+		if hasFrom {
+			start = from
+		}
+		if hasTo {
+			end = to
+		}
+		if start < 0 {
+			start = max(0, start+len(arr))
+		}
+		if end < 0 {
+			end += len(arr)
+		}
+		start, end = min(start, len(arr)), min(end, len(arr))
+		if end < start {
+			end = start
+		}
+		return []any{slices.Clone(arr[start:end])}, nil
+	}
+}
+
+func iterateFilter(v any) ([]any, error) {
+	switch v := v.(type) {
+	case []any:
+		return v, nil
+	case orderedObject:
+		values := make([]any, len(v))
+		for i, m := range v {
+			values[i] = m.value
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("cannot iterate over %s", jsonTypeName(v))
+}
+
+// recurseFilter returns v and everything below it, like jq's "..".
+func recurseFilter(v any) ([]any, error) {
+	out := []any{v}
+	switch v := v.(type) {
+	case []any:
+		for _, e := range v {
+			r, _ := recurseFilter(e)
+			out = append(out, r...)
+		}
+	case orderedObject:
+		for _, m := range v {
+			r, _ := recurseFilter(m.value)
+			out = append(out, r...)
+		}
+	}
+	return out, nil
+}
+
+func keysFilter(v any) ([]any, error) {
+	switch v := v.(type) {
+	case orderedObject:
+		keys := make([]string, len(v))
+		for i, m := range v {
+			keys[i] = m.key
+		}
+		slices.Sort(keys)
+		out := make([]any, len(keys))
+		for i, k := range keys {
+			out[i] = k
+		}
+		return []any{out}, nil
+	case []any:
+		out := make([]any, len(v))
+		for i := range v {
+			out[i] = json.Number(strconv.Itoa(i))
+		}
+		return []any{out}, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", jsonTypeName(v))
+}
+
+func lengthFilter(v any) ([]any, error) {
+	n := 0
+	switch v := v.(type) {
+	case orderedObject:
+		n = len(v)
+	case []any:
+		n = len(v)
+	case string:
+		n = len([]rune(v))
+	case nil:
+	case json.Number:
+		return []any{json.Number(strings.TrimPrefix(v.String(), "-"))}, nil
+	default:
+		return nil, fmt.Errorf("%s has no length", jsonTypeName(v))
+	}
+	return []any{json.Number(strconv.Itoa(n))}, nil
+}
+
+func mapFilter(f jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		elems, err := iterateFilter(v)
+		if err != nil {
+			return nil, err
+		}
+		out := []any{}
+		for _, e := range elems {
+			r, err := f(e)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, r...)
+		}
+		return []any{out}, nil
+	}
+}
+
+// collectFilter gathers every output of f into one array, like [f] in jq.
+func collectFilter(f jqFilter) jqFilter {
+	return func(v any) ([]any, error) {
+		out, err := f(v)
+		if err != nil {
+			return nil, err
+		}
+		if out == nil {
+			out = []any{}
+		}
+		return []any{out}, nil
+	}
+}
+
+// selectFilter keeps v when cond produces a true value, or with an
+// operator, a value that compares to lit as asked.
+func selectFilter(cond jqFilter, op string, lit any) jqFilter {
+	return func(v any) ([]any, error) {
+		outs, err := cond(v)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range outs {
+			if op == "" && o != nil && o != false || op != "" && compareOp(o, op, lit) {
+				return []any{v}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func compareOp(a any, op string, b any) bool {
+	c, ok := compareJSON(a, b)
+	switch op {
+	case "==":
+		return ok && c == 0 || !ok && jsonText(a) == jsonText(b)
+	case "!=":
+		return !(ok && c == 0 || !ok && jsonText(a) == jsonText(b))
+	case "<":
+		return ok && c < 0
+	case "<=":
+		return ok && c <= 0
+	case ">":
+		return ok && c > 0
+	case ">=":
+		return ok && c >= 0
+	}
+	return false
+}
+
+// compareJSON orders two numbers or two strings; ok is false for anything
+// else.
+func compareJSON(a, b any) (int, bool) {
+	if an, ok := a.(json.Number); ok {
+		if bn, ok := b.(json.Number); ok {
+			af, err1 := an.Float64()
+			bf, err2 := bn.Float64()
+			return cmp.Compare(af, bf), err1 == nil && err2 == nil
+		}
+	}
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return strings.Compare(as, bs), true
+		}
+	}
+	return 0, false
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case orderedObject:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// ResponseFilter is the filter bar of the workbench response and the
+// Browser document pane. The expression is applied as it is typed; while it
+// doesn't parse, the last result stays on screen.
+type ResponseFilter struct {
+	input   textinput.Model
+	editing bool
+
+	source    string
+	decoded   bool
+	doc       any
+	docErr    error
+	expr      string
+	result    string
+	count     int
+	hasResult bool
+	err       string
+}
+
+func NewResponseFilter() ResponseFilter {
+	input := textinput.New()
+	input.Placeholder = ".hits.hits[]._source"
+	input.CharLimit = 200
+	return ResponseFilter{input: input}
+}
+
+func (f *ResponseFilter) Open() tea.Cmd {
+	f.editing = true
+	f.input.CursorEnd()
+	f.input.Focus()
+	return textinput.Blink
+}
+
+func (f ResponseFilter) Editing() bool {
+	return f.editing
+}
+
+// Visible reports whether the bar is shown: while editing or while an
+// expression is set.
+func (f ResponseFilter) Visible() bool {
+	return f.editing || f.Expr() != ""
+}
+
+func (f ResponseFilter) Expr() string {
+	return strings.TrimSpace(f.input.Value())
+}
+
+// Applied reports whether Result replaces the unfiltered text.
+func (f ResponseFilter) Applied() bool {
+	return f.Expr() != "" && f.hasResult
+}
+
+func (f ResponseFilter) Result() string {
+	return f.result
+}
+
+// HandleKey edits the expression. Enter keeps the filter and leaves the
+// input, Esc removes the filter.
+func (f *ResponseFilter) HandleKey(msg tea.KeyPressMsg) FilterAction {
+	switch msg.String() {
+	case "esc":
+		f.editing = false
+		f.input.Blur()
+		f.input.SetValue("")
+		return FilterClose
+	case "enter":
+		f.editing = false
+		f.input.Blur()
+		return FilterConfirm
+	}
+	f.input, _ = f.input.Update(msg)
+	return FilterNone
+}
+
+// Run applies the expression to source. It only does work when either
+// changed since the last call.
+func (f *ResponseFilter) Run(source string) {
+	expr := f.Expr()
+	if f.decoded && source == f.source && expr == f.expr {
+		return
+	}
+	if !f.decoded || source != f.source {
+		f.source, f.decoded = source, true
+		f.doc, f.docErr = decodeOrdered(source)
+		f.hasResult = false
+	}
+	f.expr = expr
+	f.err = ""
+	if expr == "" {
+		f.hasResult = false
+		return
+	}
+	if f.docErr != nil {
+		f.err = "not JSON"
+		f.hasResult = false
+		return
+	}
+	result, count, err := applyFilter(expr, f.doc)
+	if err != nil {
+		f.err = err.Error()
+		return
+	}
+	f.result, f.count, f.hasResult = SanitizeForTerminal(result), count, true
+}
+
+func (f ResponseFilter) View(width int) string {
+	// The input grows with the expression so the status has room beside it.
+	input := f.input
+	input.SetWidth(max(min(lipgloss.Width(input.Value())+1, width-8), len(input.Placeholder)))
+	bar := "jq " + input.View()
+	room := width - 2 - lipgloss.Width(bar)
+	switch {
+	case f.err != "" && room > 1:
+		bar += lipgloss.NewStyle().Foreground(ColorRed).Render(" " + Truncate(f.err, room-1))
+	case f.Applied() && f.count != 1 && room > 1:
+		bar += lipgloss.NewStyle().Foreground(ColorGray).Render(Truncate(fmt.Sprintf(" %d results", f.count), room))
+	}
+	return lipgloss.NewStyle().
+		Background(ActiveBg).
+		Padding(0, 1).
+		Width(width).
+		Render(bar)
+}
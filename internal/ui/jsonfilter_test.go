@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+const filterDoc = `{
+  "hits": {
+    "total": {"value": 3},
+    "hits": [
+      {"_id": "1", "_source": {"user": "ann", "age": 31, "tags": ["a", "b"]}},
+      {"_id": "2", "_source": {"user": "bob", "age": 25, "tags": []}},
+      {"_id": "3", "_source": {"user": "cy", "age": 40, "address": {"city": "Oslo"}}}
+    ]
+  },
+  "my key": true
+}`
+
+func TestApplyFilter(t *testing.T) {
+	doc, err := decodeOrdered(filterDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expr    string
+		want    string
+		wantErr bool
+	}{
+		{".", "", false},
+		{".hits.total.value", "3", false},
+		{".hits.hits[]._source.user", "\"ann\"\n\"bob\"\n\"cy\"", false},
+		{".hits.hits[-1]._id", `"3"`, false},
+		{".hits.hits[1:]  | length", "2", false},
+		{".hits.hits[0]._source", "{\n  \"user\": \"ann\",\n  \"age\": 31,\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}", false},
+		{`."my key"`, "true", false},
+		{`.["my key"]`, "true", false},
+		{".hits.hits[] | select(._source.age >= 31) | ._id", "\"1\"\n\"3\"", false},
+		{`.hits.hits[] | select(._source.user == "bob") | ._source.tags`, "[]", false},
+		{".hits.hits[] | select(._source.address) | ._id", `"3"`, false},
+		{".hits.hits | map(._id)", "[\n  \"1\",\n  \"2\",\n  \"3\"\n]", false},
+		{"[.hits.hits[] | select(._source.age < 35) | ._id]", "[\n  \"1\",\n  \"2\"\n]", false},
+		{"[.hits.hits[]._source.address.city?] | length", "3", false},
+		{"[.missing[]?]", "[]", false},
+		{".hits.hits[0]._source | keys", "[\n  \"age\",\n  \"tags\",\n  \"user\"\n]", false},
+		{".missing.deeper", "null", false},
+		{"$.hits.hits[*]._source.user", "\"ann\"\n\"bob\"\n\"cy\"", false},
+		{"$..city", `"Oslo"`, false},
+		{"$['my key']", "true", false},
+		{".hits.hits[]._source.user.first?", "", false},
+		{".hits.hits[]._source.user.first", "", true},
+		{".hits[", "", true},
+		{"sort_by(.x)", "", true},
+		{"$.hits.hits[?(@.age > 1)]", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, _, err := applyFilter(tt.expr, doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyFilter(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("applyFilter(%q) =\n%s\nwant\n%s", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	if got, _, _ := applyFilter(".", doc); !strings.HasPrefix(got, "{\n  \"hits\": {\n    \"total\"") {
+		t.Errorf("identity should keep key order:\n%s", got)
+	}
+}
+
+func TestWorkbenchFilterLive(t *testing.T) {
+	w := newWorkbenchWithResult(t, filterDoc)
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	if !w.filter.Editing() || !w.HasActiveInput() {
+		t.Fatal("/ should open the filter")
+	}
+	for _, r := range ".hits.total" {
+		w, _ = w.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	if w.displayedResponse() != "{\n  \"value\": 3\n}" {
+		t.Errorf("the view should update while typing, got %q", w.displayedResponse())
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: '[', Text: "["})
+	if w.displayedResponse() != "{\n  \"value\": 3\n}" || !strings.Contains(w.View(), "expected") {
+		t.Errorf("an incomplete expression should keep the last result and show the error")
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if w.filter.Editing() || !w.filter.Applied() {
+		t.Fatal("enter should keep the filter applied")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if !w.treeActive() || w.tree().Path(1) != ".value" {
+		t.Error("tree view should show the filter result")
+	}
+
+	w = showResult(w, `{"hits": {"total": {"value": 9}}}`)
+	if !strings.Contains(w.displayedResponse(), "9") {
+		t.Errorf("the filter should apply to new responses, got %q", w.displayedResponse())
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if w.filter.Applied() || w.displayedResponse() != w.responseRawText {
+		t.Error("esc should remove the filter")
+	}
+}
+
+func TestBrowserDocumentFilter(t *testing.T) {
+	b := NewBrowser()
+	b.SetSize(160, 40)
+	b.documents = []es.DocumentHit{{ID: "1", Source: `{"user": {"name": "ann"}}`}, {ID: "2", Source: `{"user": {"name": "bob"}}`}}
+	b.activePane = BrowserPaneDetail
+	b.updateDetailPane()
+
+	b, _ = b.Update(tea.KeyPressMsg{Code: '/', Text: "/"})
+	for _, r := range ".user.name" {
+		b, _ = b.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+	b, _ = b.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if got := ansiPattern.ReplaceAllString(strings.Join(b.detailLines, "\n"), ""); got != `"ann"` {
+		t.Errorf("detail = %q, want the filtered value", got)
+	}
+
+	b.activePane = BrowserPaneDocs
+	b, _ = b.Update(tea.KeyPressMsg{Code: tea.KeyDown})
+	if got := ansiPattern.ReplaceAllString(strings.Join(b.detailLines, "\n"), ""); got != `"bob"` {
+		t.Errorf("detail = %q, the filter should follow the selected document", got)
+	}
+}
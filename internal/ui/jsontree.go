@@ -0,0 +1,429 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type jsonKind int
+
+const (
+	jsonScalar jsonKind = iota
+	jsonObject
+	jsonArray
+)
+
+type jsonTreeNode struct {
+	key    string // member name; only meaningful when index < 0
+	index  int    // position in the parent array, -1 for object members and the root
+	kind   jsonKind
+	value  string // JSON text of a scalar
+	parent int
+	depth  int
+	end    int // index after the last descendant
+	size   int // number of children
+	last   bool
+}
+
+// treeRow is one visible line: a node, or the closing bracket of an
+// expanded object or array.
+type treeRow struct {
+	node    int
+	closing bool
+}
+
+// JSONTree is a foldable view of a JSON response. Nodes are kept in
+// document order, so a node's descendants follow it and searches see every
+// node whether it is folded or not.
+type JSONTree struct {
+	nodes     []jsonTreeNode
+	collapsed []bool
+	rows      []treeRow
+	nav       ListNav
+}
+
+func NewJSONTree(text string) (*JSONTree, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	t := &JSONTree{nav: NewCursorNav()}
+	if err := t.parse(dec, "", -1, -1, 0); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON document")
+	}
+	t.nodes[0].last = true
+	t.collapsed = make([]bool, len(t.nodes))
+	t.refresh()
+	return t, nil
+}
+
+func (t *JSONTree) parse(dec *json.Decoder, key string, index, parent, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	i := len(t.nodes)
+	t.nodes = append(t.nodes, jsonTreeNode{key: key, index: index, parent: parent, depth: depth})
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		t.nodes[i].value = jsonText(tok)
+		t.nodes[i].end = len(t.nodes)
+		return nil
+	}
+	kind := jsonObject
+	if delim == '[' {
+		kind = jsonArray
+	}
+	t.nodes[i].kind = kind
+	lastChild := -1
+	for dec.More() {
+		childKey, childIndex := "", t.nodes[i].size
+		if kind == jsonObject {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			childKey, childIndex = keyTok.(string), -1
+		}
+		lastChild = len(t.nodes)
+		if err := t.parse(dec, childKey, childIndex, i, depth+1); err != nil {
+			return err
+		}
+		t.nodes[i].size++
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if lastChild >= 0 {
+		t.nodes[lastChild].last = true
+	}
+	t.nodes[i].end = len(t.nodes)
+	return nil
+}
+
+// jsonText encodes v without the HTML escaping json.Marshal applies.
+func jsonText(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (t *JSONTree) isMember(i int) bool {
+	return t.nodes[i].index < 0 && t.nodes[i].parent >= 0
+}
+
+func (t *JSONTree) foldable(i int) bool {
+	return t.nodes[i].kind != jsonScalar && t.nodes[i].size > 0
+}
+
+// refresh rebuilds the visible rows after folds change.
+func (t *JSONTree) refresh() {
+	t.rows = t.rows[:0]
+	t.addRows(0)
+	t.nav.Clamp(len(t.rows))
+}
+
+func (t *JSONTree) addRows(i int) {
+	t.rows = append(t.rows, treeRow{node: i})
+	if !t.foldable(i) || t.collapsed[i] {
+		return
+	}
+	for c := i + 1; c < t.nodes[i].end; c = t.nodes[c].end {
+		t.addRows(c)
+	}
+	t.rows = append(t.rows, treeRow{node: i, closing: true})
+}
+
+func (t *JSONTree) rowOf(node int) int {
+	for r, row := range t.rows {
+		if row.node == node && !row.closing {
+			return r
+		}
+	}
+	return 0
+}
+
+// CursorNode returns the node under the cursor.
+func (t *JSONTree) CursorNode() int {
+	if len(t.rows) == 0 {
+		return 0
+	}
+	return t.rows[t.nav.Selected].node
+}
+
+// selectNode moves the cursor to node and scrolls it into view.
+func (t *JSONTree) selectNode(node, visible int) {
+	row := t.rowOf(node)
+	t.nav.Selected = row
+	if row < t.nav.Scroll {
+		t.nav.Scroll = row
+	} else if row >= t.nav.Scroll+visible {
+		t.nav.Scroll = row - visible + 1
+	}
+}
+
+// Toggle folds or unfolds the object or array under the cursor.
+func (t *JSONTree) Toggle(visible int) {
+	node := t.CursorNode()
+	if !t.foldable(node) {
+		return
+	}
+	t.collapsed[node] = !t.collapsed[node]
+	t.refresh()
+	t.selectNode(node, visible)
+}
+
+// Collapse folds the node under the cursor, or moves to its parent when
+// there is nothing to fold.
+func (t *JSONTree) Collapse(visible int) {
+	node := t.CursorNode()
+	if t.foldable(node) && !t.collapsed[node] {
+		t.collapsed[node] = true
+		t.refresh()
+	} else if parent := t.nodes[node].parent; parent >= 0 {
+		node = parent
+	}
+	t.selectNode(node, visible)
+}
+
+func (t *JSONTree) Expand(visible int) {
+	node := t.CursorNode()
+	if t.foldable(node) && t.collapsed[node] {
+		t.collapsed[node] = false
+		t.refresh()
+		t.selectNode(node, visible)
+	}
+}
+
+func (t *JSONTree) ExpandAll(visible int) {
+	node := t.CursorNode()
+	clear(t.collapsed)
+	t.refresh()
+	t.selectNode(node, visible)
+}
+
+// CollapseAll folds everything below the top level and moves the cursor to
+// the top-level member it was in.
+func (t *JSONTree) CollapseAll(visible int) {
+	node := t.CursorNode()
+	for t.nodes[node].depth > 1 {
+		node = t.nodes[node].parent
+	}
+	for i := 1; i < len(t.nodes); i++ {
+		t.collapsed[i] = t.foldable(i)
+	}
+	t.collapsed[0] = false
+	t.refresh()
+	t.selectNode(node, visible)
+}
+
+// Reveal unfolds the ancestors of node and moves the cursor to it.
+func (t *JSONTree) Reveal(node, visible int) {
+	if node < 0 || node >= len(t.nodes) {
+		return
+	}
+	for p := t.nodes[node].parent; p >= 0; p = t.nodes[p].parent {
+		t.collapsed[p] = false
+	}
+	t.refresh()
+	t.selectNode(node, visible)
+}
+
+func (t *JSONTree) HandleKey(key string, visible int) bool {
+	return t.nav.HandleKey(key, len(t.rows), visible)
+}
+
+func (t *JSONTree) HandleWheel(down bool, visible int) {
+	t.nav.HandleWheel(down, len(t.rows), visible)
+}
+
+var jqIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Path returns the jq-style path of a node, e.g.
+// .aggregations.hosts.buckets[3].key.
+func (t *JSONTree) Path(node int) string {
+	var segs []string
+	for i := node; i > 0; i = t.nodes[i].parent {
+		n := t.nodes[i]
+		switch {
+		case n.index >= 0:
+			segs = append(segs, fmt.Sprintf("[%d]", n.index))
+		case jqIdentifier.MatchString(n.key):
+			segs = append(segs, "."+n.key)
+		default:
+			segs = append(segs, "["+jsonText(n.key)+"]")
+		}
+	}
+	var b strings.Builder
+	for i := len(segs) - 1; i >= 0; i-- {
+		b.WriteString(segs[i])
+	}
+	path := b.String()
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return path
+}
+
+// Value returns a node as it would be copied: strings without quotes,
+// other scalars as JSON, objects and arrays as indented JSON.
+func (t *JSONTree) Value(node int) string {
+	n := t.nodes[node]
+	if n.kind == jsonScalar {
+		var s string
+		if json.Unmarshal([]byte(n.value), &s) == nil {
+			return s
+		}
+		return n.value
+	}
+	var b strings.Builder
+	t.writeJSON(&b, node, "")
+	return b.String()
+}
+
+func (t *JSONTree) writeJSON(b *strings.Builder, i int, indent string) {
+	n := t.nodes[i]
+	if n.kind == jsonScalar {
+		b.WriteString(n.value)
+		return
+	}
+	open, close := brackets(n.kind)
+	if n.size == 0 {
+		b.WriteString(open + close)
+		return
+	}
+	b.WriteString(open + "\n")
+	inner := indent + "  "
+	for c := i + 1; c < n.end; c = t.nodes[c].end {
+		b.WriteString(inner)
+		if n.kind == jsonObject {
+			b.WriteString(jsonText(t.nodes[c].key) + ": ")
+		}
+		t.writeJSON(b, c, inner)
+		if !t.nodes[c].last {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + close)
+}
+
+func brackets(kind jsonKind) (string, string) {
+	if kind == jsonArray {
+		return "[", "]"
+	}
+	return "{", "}"
+}
+
+// SearchLines returns one line of text per node, in node order, for the
+// search bar. Matches are node indices, so folded nodes are found too.
+func (t *JSONTree) SearchLines() []string {
+	lines := make([]string, len(t.nodes))
+	for i, n := range t.nodes {
+		var parts []string
+		if t.isMember(i) {
+			parts = append(parts, jsonText(n.key))
+		}
+		if n.kind == jsonScalar {
+			parts = append(parts, n.value)
+		}
+		lines[i] = strings.Join(parts, ": ")
+	}
+	return lines
+}
+
+type treeSpan struct {
+	text  string
+	color color.Color
+}
+
+func (t *JSONTree) rowSpans(r treeRow) []treeSpan {
+	n := t.nodes[r.node]
+	marker := "  "
+	if !r.closing && t.foldable(r.node) {
+		marker = "▾ "
+		if t.collapsed[r.node] {
+			marker = "▸ "
+		}
+	}
+	spans := []treeSpan{{text: strings.Repeat("  ", n.depth) + marker}}
+	open, close := brackets(n.kind)
+	if r.closing {
+		spans = append(spans, treeSpan{text: close})
+	} else {
+		if t.isMember(r.node) {
+			spans = append(spans, treeSpan{text: jsonText(n.key), color: ColorBlue}, treeSpan{text: ": "})
+		}
+		switch {
+		case n.kind == jsonScalar:
+			spans = append(spans, treeSpan{text: n.value, color: scalarColor(n.value)})
+		case n.size == 0:
+			spans = append(spans, treeSpan{text: open + close})
+		case t.collapsed[r.node]:
+			summary := fmt.Sprintf("… %d items", n.size)
+			if n.kind == jsonObject {
+				summary = fmt.Sprintf("… %d keys", n.size)
+			}
+			spans = append(spans, treeSpan{text: open}, treeSpan{text: summary, color: ColorGray}, treeSpan{text: close})
+		default:
+			return append(spans, treeSpan{text: open})
+		}
+	}
+	if !n.last {
+		spans = append(spans, treeSpan{text: ","})
+	}
+	return spans
+}
+
+func scalarColor(value string) color.Color {
+	switch value[0] {
+	case '"':
+		return ColorGreen
+	case 't', 'f', 'n':
+		return ColorYellow
+	}
+	return ColorPurple
+}
+
+// View renders height rows starting at the scroll position. The cursor row
+// is drawn without syntax colors on the selection background.
+func (t *JSONTree) View(width, height int) []string {
+	end := min(t.nav.Scroll+height, len(t.rows))
+	lines := make([]string, 0, end-t.nav.Scroll)
+	for r := t.nav.Scroll; r < end; r++ {
+		spans := t.rowSpans(t.rows[r])
+		if r == t.nav.Selected {
+			var plain strings.Builder
+			for _, s := range spans {
+				plain.WriteString(s.text)
+			}
+			line := ansi.Truncate(plain.String(), width, "…")
+			lines = append(lines, lipgloss.NewStyle().Background(ActiveBg).Width(width).Render(line))
+			continue
+		}
+		var b strings.Builder
+		for _, s := range spans {
+			if s.color == nil {
+				b.WriteString(s.text)
+			} else {
+				b.WriteString(lipgloss.NewStyle().Foreground(s.color).Render(s.text))
+			}
+		}
+		lines = append(lines, ansi.Truncate(b.String(), width, "…"))
+	}
+	return lines
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+const treeDoc = `{
+  "took": 3,
+  "hits": {"total": {"value": 2}, "hits": []},
+  "aggregations": {
+    "by host": {
+      "buckets": [
+        {"key": "web-1", "doc_count": 5},
+        {"key": "db-1", "doc_count": 2}
+      ]
+    }
+  }
+}`
+
+func treeRowText(tree *JSONTree) []string {
+	var lines []string
+	for _, r := range tree.rows {
+		var b strings.Builder
+		for _, s := range tree.rowSpans(r) {
+			b.WriteString(s.text)
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return lines
+}
+
+func TestJSONTreeFolding(t *testing.T) {
+	tree, err := NewJSONTree(treeDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(tree.rows); got != 23 {
+		t.Fatalf("expanded tree has %d rows, want 23:\n%s", got, strings.Join(treeRowText(tree), "\n"))
+	}
+
+	tree.CollapseAll(10)
+	want := []string{
+		"▾ {",
+		`    "took": 3,`,
+		`  ▸ "hits": {… 2 keys},`,
+		`  ▸ "aggregations": {… 1 keys}`,
+		"  }",
+	}
+	if got := treeRowText(tree); !slices.Equal(got, want) {
+		t.Errorf("collapsed rows:\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	tree.nav.Selected = 3
+	tree.Expand(10)
+	tree.HandleKey("down", 10)
+	tree.Toggle(10)
+	if got := treeRowText(tree)[4]; got != `    ▾ "by host": {` {
+		t.Errorf("row 4 = %q, want the unfolded bucket agg", got)
+	}
+	tree.Collapse(10)
+	tree.Collapse(10)
+	if tree.CursorNode() != 6 || !tree.collapsed[7] {
+		t.Errorf("left should fold, then move to the parent; cursor on node %d", tree.CursorNode())
+	}
+
+	tree.ExpandAll(10)
+	if len(tree.rows) != 23 || tree.CursorNode() != 6 {
+		t.Errorf("expand all should keep the cursor on its node, got %d", tree.CursorNode())
+	}
+}
+
+func TestJSONTreePathAndValue(t *testing.T) {
+	tree, err := NewJSONTree(treeDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		node  int
+		path  string
+		value string
+	}{
+		{0, ".", ""},
+		{1, ".took", "3"},
+		{3, ".hits.total", "{\n  \"value\": 2\n}"},
+		{5, ".hits.hits", "[]"},
+		{13, `.aggregations["by host"].buckets[1].key`, "db-1"},
+	}
+	for _, tt := range tests {
+		if got := tree.Path(tt.node); got != tt.path {
+			t.Errorf("Path(%d) = %q, want %q", tt.node, got, tt.path)
+		}
+		if tt.value != "" && tree.Value(tt.node) != tt.value {
+			t.Errorf("Value(%d) = %q, want %q", tt.node, tree.Value(tt.node), tt.value)
+		}
+	}
+	if got := tree.Value(0); !strings.HasPrefix(got, "{\n  \"took\": 3,\n  \"hits\"") {
+		t.Errorf("the root value should keep key order:\n%s", got)
+	}
+}
+
+func TestJSONTreeRejectsNonJSON(t *testing.T) {
+	for _, text := range []string{"green open logs", `{"a": 1} trailing`, `{"a": `} {
+		if _, err := NewJSONTree(text); err == nil {
+			t.Errorf("NewJSONTree(%q) should fail", text)
+		}
+	}
+}
+
+func TestWorkbenchTreeSearch(t *testing.T) {
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.responseRawText = treeDoc
+	w.responseTree, _ = NewJSONTree(treeDoc)
+	w.focus = FocusResponse
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if !w.treeActive() {
+		t.Fatal("t should switch the response to tree mode")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: '-', Text: "-"})
+	w.search.SetQuery("db-1")
+	w.updateSearchMatches()
+	if w.search.MatchCount() != 1 {
+		t.Fatalf("search should find folded nodes, got %d matches", w.search.MatchCount())
+	}
+	if got := w.responseTree.Path(w.responseTree.CursorNode()); got != `.aggregations["by host"].buckets[1].key` {
+		t.Errorf("cursor on %s, want the match unfolded and selected", got)
+	}
+	if !strings.Contains(w.View(), `.aggregations["by host"].buckets[1].key`) {
+		t.Error("the breadcrumb should show the cursor path")
+	}
+
+	w, cmd := w.Update(tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if cmd == nil || w.ClipboardMessage() != "Copied!" {
+		t.Error("y should copy the value at the cursor")
+	}
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strings"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/huh/v2"
@@ -18,6 +19,11 @@ const (
 	ModalAddAlias
 	ModalRemoveAlias
 	ModalError
+	ModalOpenFile
+	ModalSaveFile
+	ModalExport
+	ModalCloseSession
+	ModalOverwrite
 )
 
 type Modal struct {
@@ -31,6 +37,8 @@ type Modal struct {
 	shards    string
 	replicas  string
 	aliasName string
+	path      string
+	action    ModalType
 	confirmed bool
 	aliases   []string
 }
@@ -105,6 +113,23 @@ func NewCloseIndexModal(indexName string) *Modal {
 	return m
 }
 
+func NewCloseSessionModal(clusterName string) *Modal {
+	m := &Modal{modalType: ModalCloseSession}
+
+	m.form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Close the session on '%s'?", clusterName)).
+				Description("Its tabs, requests and responses are discarded.").
+				Affirmative("Close").
+				Negative("Cancel").
+				Value(&m.confirmed),
+		),
+	).WithShowHelp(false).WithShowErrors(true)
+
+	return m
+}
+
 func NewAddAliasModal(indexName string) *Modal {
 	m := &Modal{
 		modalType: ModalAddAlias,
@@ -158,6 +183,51 @@ func NewRemoveAliasModal(indexName string, aliases []string) *Modal {
 	return m
 }
 
+// NewFileModal asks for the path of a file to open or save, starting from
+// the last one used.
+func NewFileModal(modalType ModalType, title, description, path string) *Modal {
+	m := &Modal{
+		modalType: modalType,
+		path:      path,
+	}
+
+	m.form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewInput().
+				Title(title).
+				Description(description).
+				Placeholder("~/path/to/file").
+				Value(&m.path).
+				Validate(huh.ValidateNotEmpty()),
+		),
+	).WithShowHelp(false).WithShowErrors(true)
+
+	return m
+}
+
+// NewOverwriteModal asks before action, the file modal that picked path,
+// replaces a file that already exists.
+func NewOverwriteModal(action ModalType, path string) *Modal {
+	m := &Modal{
+		modalType: ModalOverwrite,
+		action:    action,
+		path:      path,
+	}
+
+	m.form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title(fmt.Sprintf("Overwrite %s?", path)).
+				Description("The file already exists.").
+				Affirmative("Overwrite").
+				Negative("Cancel").
+				Value(&m.confirmed),
+		),
+	).WithShowHelp(false).WithShowErrors(true)
+
+	return m
+}
+
 func NewErrorModal(errMsg string) *Modal {
 	m := &Modal{
 		modalType: ModalError,
@@ -206,6 +276,15 @@ func (m *Modal) AliasName() string {
 	return m.aliasName
 }
 
+func (m *Modal) Path() string {
+	return strings.TrimSpace(m.path)
+}
+
+// Action returns the file modal an overwrite confirmation continues.
+func (m *Modal) Action() ModalType {
+	return m.action
+}
+
 func (m *Modal) Confirmed() bool {
 	return m.confirmed
 }
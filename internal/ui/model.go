@@ -2,6 +2,7 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"charm.land/bubbles/v2/spinner"
@@ -31,6 +32,10 @@ type Model struct {
 	nodes        NodesModel
 	tasks        TasksModel
 	shardCalc    ShardCalcModel
+	auditView    AuditViewModel
+	switcher     ClusterSwitcherModel
+	openCluster  ClusterOpener
+	workbenches  map[string]WorkbenchModel
 	spinner      spinner.Model
 	activeTab    int
 	width        int
@@ -40,6 +45,9 @@ type Model struct {
 	err           error
 	showHelp      bool
 	showShardCalc bool
+	showAudit     bool
+	showSwitcher  bool
+	sessionLabel  string
 	startTab      int
 	startView     string
 }
@@ -50,7 +58,10 @@ type clusterSettingsMsg struct{ settings *es.ClusterSettings }
 type threadPoolsMsg struct{ pools []es.ThreadPoolInfo }
 type hotThreadsMsg struct{ threads string }
 type templatesMsg struct{ templates []es.IndexTemplate }
-type deprecationsMsg struct{ deprecations *es.DeprecationInfo }
+type deprecationsMsg struct {
+	deprecations *es.DeprecationInfo
+	unavailable  string
+}
 type tasksMsg struct{ tasks []es.TaskInfo }
 type pendingTasksMsg struct{ tasks []es.PendingTask }
 type taskCancelledMsg struct{ err error }
@@ -86,31 +97,113 @@ func (m *Model) SetStartTab(tab string, view string) {
 func New(client *es.Client, cfg *config.Config) Model {
 	wb := NewWorkbench()
 	wb.SetClient(client)
+	if cfg != nil {
+		wb.SetVariables(cfg.Variables)
+	}
+
+	m := Model{
+		client:      client,
+		cfg:         cfg,
+		workbench:   wb,
+		shardCalc:   NewShardCalc(),
+		auditView:   NewAuditView(),
+		switcher:    NewClusterSwitcher(),
+		workbenches: make(map[string]WorkbenchModel),
+		spinner:     newSpinner(),
+		activeTab:   TabOverview,
+		loading:     true,
+	}
+	m.resetTabs()
+	return m
+}
+
+// resetTabs recreates every tab except the workbench for the current client.
+func (m *Model) resetTabs() {
+	m.overview = NewOverview()
+	m.overview.SetClient(m.client)
+	m.browser = NewBrowser()
+	m.browser.SetClient(m.client)
+	m.mappings = NewMappings()
+	m.nodes = NewNodes()
+	m.tasks = NewTasks()
+	m.tasks.SetReadOnly(m.cfg != nil && m.cfg.ReadOnly)
+	m.resizeTabs()
+}
 
-	ov := NewOverview()
-	ov.SetClient(client)
+func (m *Model) resizeTabs() {
+	if m.width == 0 || m.height == 0 {
+		return
+	}
+	m.overview.SetSize(m.width, m.height-4)
+	m.workbench.SetSize(m.width, m.height-4)
+	m.browser.SetSize(m.width, m.height-4)
+	m.mappings.SetSize(m.width, m.height-4)
+	m.nodes.SetSize(m.width, m.height-4)
+	m.tasks.SetSize(m.width, m.height-4)
+	m.auditView.SetSize(m.width, m.height)
+	m.switcher.SetSize(m.width, m.height)
+}
+
+// SetClusterSwitcher enables switching between the clusters in config.yaml
+// without restarting. open builds the client for the chosen cluster.
+func (m *Model) SetClusterSwitcher(clusters *config.ClustersConfig, open ClusterOpener) {
+	m.switcher.SetClusters(clusters)
+	m.openCluster = open
+}
 
-	br := NewBrowser()
-	br.SetClient(client)
+// Client returns the client for the active cluster, which changes when the
+// user switches clusters.
+func (m Model) Client() *es.Client {
+	return m.client
+}
+
+func (m Model) clusterKey() string {
+	if m.cfg == nil {
+		return ""
+	}
+	return m.cfg.AuditName()
+}
+
+// switchCluster replaces the client and resets every tab. Workbench content
+// is kept per cluster and restored when switching back.
+func (m *Model) switchCluster(client *es.Client, cfg *config.Config) tea.Cmd {
+	if m.workbench.executing {
+		m.workbench.cancelExecution()
+	}
+	m.workbench.Blur()
+	m.workbenches[m.clusterKey()] = m.workbench
+	if m.client != nil {
+		m.client.CloseAuditLog()
+	}
 
-	return Model{
-		client:    client,
-		cfg:       cfg,
-		overview:  ov,
-		workbench: wb,
-		browser:   br,
-		mappings:  NewMappings(),
-		nodes:     NewNodes(),
-		tasks:     NewTasks(),
-		shardCalc: NewShardCalc(),
-		spinner:   newSpinner(),
-		activeTab: TabOverview,
-		loading:   true,
+	m.client = client
+	m.cfg = cfg
+	wb, ok := m.workbenches[m.clusterKey()]
+	if !ok {
+		wb = NewWorkbench()
 	}
+	wb.SetClient(client)
+	if cfg != nil {
+		wb.SetVariables(cfg.Variables)
+	}
+	// Keep sequence numbers increasing so a late result from the old
+	// cluster can never match a request on the new one.
+	wb.execSeq = max(wb.execSeq, m.workbench.execSeq)
+	m.workbench = wb
+	m.resetTabs()
+
+	m.cluster = nil
+	m.connected = false
+	m.err = nil
+	m.loading = true
+	if m.activeTab != TabWorkbench {
+		m.activeTab = TabOverview
+	}
+	return tea.Batch(m.spinner.Tick, m.connect())
 }
 
 func (m Model) hasActiveInput() bool {
-	if m.showShardCalc {
+	if m.showShardCalc || m.showAudit || m.showSwitcher {
 		return true
 	}
 	switch m.activeTab {
@@ -130,6 +223,13 @@ func (m Model) hasActiveInput() bool {
 	return false
 }
 
+// switchesTableSource reports whether [ and ] go to the Workbench response
+// table, to show its other sets of rows, rather than cycle sessions.
+func (m Model) switchesTableSource() bool {
+	return m.activeTab == TabWorkbench && m.workbench.focus == FocusResponse &&
+		m.workbench.tableActive() && len(m.workbench.table().sources) > 1
+}
+
 func (m *Model) switchTab(tab int) tea.Cmd {
 	m.activeTab = tab
 	return nil
@@ -223,10 +323,13 @@ func (m Model) fetchDeprecations() tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		deprecations, err := m.client.FetchDeprecations(ctx)
+		if errors.Is(err, es.ErrUnsupported) {
+			return deprecationsMsg{unavailable: fmt.Sprintf("Deprecation checks are not available on %s", m.client.ServerInfo())}
+		}
 		if err != nil {
 			return errMsg{err}
 		}
-		return deprecationsMsg{deprecations}
+		return deprecationsMsg{deprecations: deprecations}
 	}
 }
 
@@ -303,6 +406,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loading = false
 		m.cluster = msg.state
 		m.overview.SetCluster(msg.state)
+		m.workbench.SetSelectedIndex(m.overview.SelectedIndex())
+		m.workbench.SetIndexNames(IndexNameCompletions(msg.state))
 		m.nodes.SetShardHealth(msg.state.Indices)
 		if m.startTab != TabOverview {
 			m.activeTab = m.startTab
@@ -341,7 +446,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case templatesMsg:
 		m.nodes.SetTemplates(msg.templates)
 	case deprecationsMsg:
-		m.nodes.SetDeprecations(msg.deprecations)
+		if msg.unavailable != "" {
+			m.nodes.SetDeprecationsUnavailable(msg.unavailable)
+		} else {
+			m.nodes.SetDeprecations(msg.deprecations)
+		}
 	case tasksMsg:
 		m.loading = false
 		m.tasks.SetTasks(msg.tasks)
@@ -400,14 +509,67 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case executeResultMsg:
 		m.workbench, cmd = m.workbench.Update(msg)
 		return m, cmd
+	case clusterSelectedMsg:
+		return m, tea.Batch(m.spinner.Tick, m.switcher.startResolve(msg.name, msg.newSession, m.openCluster))
+	case clusterSwitchedMsg:
+		if msg.seq != m.switcher.seq {
+			// The switch was cancelled while resolving; drop the client.
+			if msg.client != nil {
+				msg.client.CloseAuditLog()
+			}
+			return m, nil
+		}
+		if msg.err != nil {
+			m.switcher.fail(msg.err)
+			return m, nil
+		}
+		m.switcher.resolving = ""
+		m.showSwitcher = false
+		if msg.newSession {
+			open := openSessionMsg{client: msg.client, cfg: msg.cfg}
+			return m, func() tea.Msg { return open }
+		}
+		return m, m.switchCluster(msg.client, msg.cfg)
+	case taskHandoffMsg:
+		m.workbench.Blur()
+		m.tasks.SelectWhenLoaded(msg.taskID)
+		m.loading = true
+		return m, tea.Batch(m.switchTab(TabTasks), m.fetchTasksTab())
 	case tea.KeyPressMsg:
 		if m.showHelp {
-			if msg.String() == "esc" || msg.String() == "?" {
+			switch msg.String() {
+			case "esc", "?":
 				m.showHelp = false
-				return m, nil
+			case "L":
+				m.showHelp = false
+				m.showAudit = true
+				if m.client != nil {
+					m.auditView.SetLog(m.client.AuditLog())
+				}
+				m.auditView.SetSize(m.width, m.height)
 			}
 			return m, nil
 		}
+		if m.showAudit {
+			if msg.String() == "esc" || msg.String() == "q" {
+				m.showAudit = false
+				return m, nil
+			}
+			m.auditView, cmd = m.auditView.Update(msg)
+			return m, cmd
+		}
+		if m.showSwitcher {
+			if msg.String() == "esc" {
+				if m.switcher.Resolving() {
+					m.switcher.cancelResolve()
+				} else {
+					m.showSwitcher = false
+				}
+				return m, nil
+			}
+			m.switcher, cmd = m.switcher.Update(msg)
+			return m, cmd
+		}
 		if m.showShardCalc {
 			if msg.String() == "esc" {
 				m.showShardCalc = false
@@ -427,6 +589,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.shardCalc.Reset()
 				m.shardCalc.SetSize(m.width, m.height)
 				return m, nil
+			case "C":
+				if m.switcher.Available() && m.openCluster != nil {
+					m.showSwitcher = true
+					m.switcher.Open(m.cfg.ClusterName)
+					m.switcher.SetSize(m.width, m.height)
+				}
+				return m, nil
 			case "q":
 				return m, tea.Quit
 			case "r":
@@ -497,6 +666,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.activeTab == TabWorkbench && m.workbench.focus == FocusBody && m.workbench.editor.selection.Active {
 				break
 			}
+			if m.activeTab == TabWorkbench && m.workbench.executing {
+				break
+			}
 			return m, tea.Quit
 		}
 	case IndexCreatedMsg, IndexDeletedMsg, IndexOpenedMsg, IndexClosedMsg, AliasAddedMsg, AliasRemovedMsg:
@@ -508,12 +680,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.overview.SetSize(msg.Width, msg.Height-4)
-		m.workbench.SetSize(msg.Width, msg.Height-4)
-		m.browser.SetSize(msg.Width, msg.Height-4)
-		m.mappings.SetSize(msg.Width, msg.Height-4)
-		m.nodes.SetSize(msg.Width, msg.Height-4)
-		m.tasks.SetSize(msg.Width, msg.Height-4)
+		m.resizeTabs()
 	case tea.MouseReleaseMsg:
 		if msg.Button == tea.MouseLeft {
 			if msg.Y == 1 {
@@ -575,6 +742,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.activeTab {
 		case TabOverview:
 			m.overview, cmd = m.overview.Update(delegateMsg)
+			m.workbench.SetSelectedIndex(m.overview.SelectedIndex())
 		case TabWorkbench:
 			m.workbench, cmd = m.workbench.Update(delegateMsg)
 		case TabBrowser:
@@ -617,6 +785,14 @@ func (m Model) View() tea.View {
 		return m.makeView(m.shardCalc.View())
 	}
 
+	if m.showAudit {
+		return m.makeView(m.auditView.View())
+	}
+
+	if m.showSwitcher {
+		return m.makeView(m.switcher.View(m.spinner.View()))
+	}
+
 	// Header
 	status := "connecting..."
 	if m.connected {
@@ -629,6 +805,18 @@ func (m Model) View() tea.View {
 		status = m.spinner.View() + " loading"
 	}
 	headerText := fmt.Sprintf("stoptail · %s [%s]", m.cfg.MaskedURL(), status)
+	if m.client != nil && m.client.IsMultiNode() {
+		headerText = fmt.Sprintf("stoptail · %s → %s [%s]", m.cfg.MaskedURL(), m.client.CurrentNode(), status)
+	}
+	if m.sessionLabel != "" {
+		headerText += "  " + m.sessionLabel
+	}
+	if m.cfg.ReadOnly {
+		headerText += "  " + lipgloss.NewStyle().Bold(true).Foreground(ColorOnAccent).Background(ColorYellow).Padding(0, 1).Render("READ-ONLY")
+	}
+	if m.cfg.InsecureSkipVerify {
+		headerText += "  " + lipgloss.NewStyle().Bold(true).Foreground(ColorRed).Background(HeaderBg).Render("⚠ TLS VERIFICATION DISABLED")
+	}
 	header := HeaderStyle.Width(m.width).Render(headerText)
 
 	overviewTab := InactiveTabStyle.Render("Overview")
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
+	"github.com/labtiva/stoptail/internal/estest"
+)
+
+func newTestModel(t *testing.T, f estest.Fixture) (Model, *estest.Server) {
+	t.Helper()
+	srv := estest.NewServer(t, f)
+	cfg := &config.Config{Host: srv.URL}
+	client, err := es.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	m := New(client, cfg)
+	newM, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	return newM.(Model), srv
+}
+
+// run executes cmd and feeds its message back into the model, the way the
+// bubbletea runtime would for a single round trip.
+func run(m Model, cmd tea.Cmd) Model {
+	newM, _ := m.Update(cmd())
+	return newM.(Model)
+}
+
+// drain runs cmd and everything it leads to, skipping spinner ticks.
+func drain(m Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return m
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = drain(m, c)
+		}
+		return m
+	}
+	if _, ok := msg.(spinner.TickMsg); ok || msg == nil {
+		return m
+	}
+	newM, next := m.Update(msg)
+	return drain(newM.(Model), next)
+}
+
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		var msg tea.KeyPressMsg
+		switch k {
+		case "up":
+			msg = tea.KeyPressMsg{Code: tea.KeyUp}
+		case "enter":
+			msg = tea.KeyPressMsg{Code: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyPressMsg{Code: tea.KeyEscape}
+		default:
+			msg = tea.KeyPressMsg{Code: rune(k[0]), Text: k}
+		}
+		newM, cmd := m.Update(msg)
+		m = drain(newM.(Model), cmd)
+	}
+	return m
+}
+
+func TestClusterSwitcher(t *testing.T) {
+	staging := estest.NewServer(t, estest.Fixture{Indices: []estest.Index{{Name: "stg-logs"}}})
+	production := estest.NewServer(t, estest.Fixture{Indices: []estest.Index{{Name: "prod-logs"}}})
+	clusters := &config.ClustersConfig{Clusters: map[string]config.ClusterEntry{
+		"staging":    {URL: staging.URL},
+		"production": {URL: production.URL},
+		"broken":     {URL: "http://127.0.0.1:1"},
+	}}
+	open := func(resolved *config.ResolvedCluster, _ string) (*es.Client, *config.Config, error) {
+		if resolved.Name == "broken" {
+			return nil, nil, errors.New("no route to broken")
+		}
+		cfg := &config.Config{Host: resolved.URL, ClusterName: resolved.Name}
+		client, err := es.NewClient(cfg)
+		return client, cfg, err
+	}
+
+	client, cfg, _ := open(&config.ResolvedCluster{Name: "staging", URL: staging.URL}, "")
+	m := New(client, cfg)
+	m.SetClusterSwitcher(clusters, open)
+	newM, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	m = run(newM.(Model), m.connect())
+	m.workbench.SetBody(`{"query":"staging"}`)
+
+	// Names are sorted: broken, production, staging.
+	m = press(m, "C")
+	if !m.showSwitcher || !strings.Contains(stripANSI(m.View().Content), "staging (current)") {
+		t.Fatal("C should open the switcher with the current cluster marked")
+	}
+	m = press(m, "up", "up", "enter")
+	if !m.showSwitcher || m.switcher.err == nil || m.cfg.ClusterName != "staging" {
+		t.Fatal("a failed switch should keep the switcher open and stay on staging")
+	}
+
+	m = press(m, "down", "enter")
+	if m.showSwitcher || m.cfg.ClusterName != "production" || !m.connected {
+		t.Fatalf("expected to be connected to production, got %q (err %v)", m.cfg.ClusterName, m.err)
+	}
+	view := m.View().Content
+	if !strings.Contains(view, "prod-logs") || strings.Contains(view, "stg-logs") {
+		t.Error("overview should show only the production indices after switching")
+	}
+	if got := m.workbench.editor.Content(); got == `{"query":"staging"}` {
+		t.Error("production should not inherit the staging workbench body")
+	}
+
+	m = press(m, "C", "down", "enter")
+	if m.cfg.ClusterName != "staging" {
+		t.Fatalf("expected staging, got %q", m.cfg.ClusterName)
+	}
+	if got := m.workbench.editor.Content(); got != `{"query":"staging"}` {
+		t.Errorf("staging workbench body = %q, want it restored", got)
+	}
+}
+
+func TestOverviewAgainstFakeCluster(t *testing.T) {
+	m, _ := newTestModel(t, estest.Fixture{
+		Nodes: []estest.Node{{Name: "es-a"}, {Name: "es-b"}},
+		Indices: []estest.Index{
+			{Name: "orders", Replicas: 1},
+			{Name: "payments", Replicas: 1, States: map[string]string{"0p": "UNASSIGNED"}},
+		},
+	})
+
+	m = run(m, m.connect())
+	if !m.connected || m.err != nil {
+		t.Fatalf("connected = %v, err = %v", m.connected, m.err)
+	}
+	view := m.View().Content
+	for _, want := range []string{"orders", "payments", "es-a", "es-b"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("overview should show %q", want)
+		}
+	}
+	if m.cluster.Health.Status != "red" {
+		t.Errorf("cluster health = %q, want red", m.cluster.Health.Status)
+	}
+}
+
+func TestConnectReportsPermissionDenied(t *testing.T) {
+	m, _ := newTestModel(t, estest.Fixture{
+		Failures: map[string]int{"GET /_cat/indices": 403},
+	})
+
+	m = run(m, m.connect())
+	if m.err == nil || !strings.Contains(m.err.Error(), "security_exception") {
+		t.Fatalf("err = %v, want security_exception", m.err)
+	}
+}
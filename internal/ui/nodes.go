@@ -35,6 +35,7 @@ type NodesModel struct {
 	hotThreads       string
 	templates        []es.IndexTemplate
 	deprecations     *es.DeprecationInfo
+	deprecationsNote string
 	shardHealth      []es.ShardHealth
 	activeView     NodesView
 	nav            ListNav
@@ -182,6 +183,11 @@ func (m *NodesModel) SetDeprecations(deprecations *es.DeprecationInfo) {
 	m.deprecations = deprecations
 }
 
+func (m *NodesModel) SetDeprecationsUnavailable(note string) {
+	m.deprecations = &es.DeprecationInfo{}
+	m.deprecationsNote = note
+}
+
 func (m *NodesModel) SetShardHealth(indices []es.IndexInfo) {
 	var health []es.ShardHealth
 	for _, idx := range indices {
@@ -1172,6 +1178,9 @@ func (m NodesModel) renderTemplateDetailModal() string {
 }
 
 func (m NodesModel) renderDeprecations() string {
+	if m.deprecationsNote != "" {
+		return lipgloss.NewStyle().Foreground(ColorGray).Render(m.deprecationsNote)
+	}
 	if m.deprecations == nil {
 		return "Loading deprecations..."
 	}
@@ -22,6 +22,15 @@ const (
 
 type ModalInitMsg struct{}
 
+var overviewWriteActions = map[string]string{
+	"c": "create index",
+	"d": "delete index",
+	"o": "open index",
+	"x": "close index",
+	"a": "add alias",
+	"A": "remove alias",
+}
+
 type IndexCreatedMsg struct{ Err error }
 type IndexDeletedMsg struct{ Err error }
 type IndexOpenedMsg struct{ Err error }
@@ -153,6 +162,12 @@ func (m OverviewModel) Update(msg tea.Msg) (OverviewModel, tea.Cmd) {
 			return m, nil
 		}
 
+		if action, ok := overviewWriteActions[msg.String()]; ok && m.client != nil {
+			if err := m.client.CheckWritable(action); err != nil {
+				return m.showError(err)
+			}
+		}
+
 		switch msg.String() {
 		case "/":
 			m.filterActive = true
@@ -493,27 +508,15 @@ func (m OverviewModel) filteredIndices() []es.IndexInfo {
 	}
 
 	var filtered []es.IndexInfo
-	filterText := strings.ToLower(m.filter.Value())
+	filterText := m.filter.Value()
 
 	for _, idx := range m.cluster.Indices {
 		if !m.showSystem && strings.HasPrefix(idx.Name, ".") {
 			continue
 		}
 
-		if filterText != "" {
-			match := false
-			if strings.Contains(strings.ToLower(idx.Name), filterText) {
-				match = true
-			}
-			if strings.HasSuffix(filterText, "*") {
-				prefix := strings.TrimSuffix(filterText, "*")
-				if strings.HasPrefix(strings.ToLower(idx.Name), prefix) {
-					match = true
-				}
-			}
-			if !match {
-				continue
-			}
+		if filterText != "" && !es.MatchesIndexFilter(idx.Name, filterText) {
+			continue
 		}
 
 		if len(m.aliasFilters) > 0 {
@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+// restEndpoints are the cluster-level APIs offered at the start of a path.
+var restEndpoints = []string{
+	"_alias", "_aliases", "_analyze", "_bulk", "_count", "_field_caps", "_mget", "_msearch", "_query",
+	"_reindex", "_render/template", "_resolve/index", "_search", "_search/scroll", "_sql", "_stats", "_tasks",
+	"_cat/aliases", "_cat/allocation", "_cat/component_templates", "_cat/count", "_cat/fielddata",
+	"_cat/health", "_cat/indices", "_cat/master", "_cat/nodeattrs", "_cat/nodes", "_cat/pending_tasks",
+	"_cat/plugins", "_cat/recovery", "_cat/repositories", "_cat/segments", "_cat/shards", "_cat/snapshots",
+	"_cat/tasks", "_cat/templates", "_cat/thread_pool",
+	"_cluster/allocation/explain", "_cluster/health", "_cluster/pending_tasks", "_cluster/reroute",
+	"_cluster/settings", "_cluster/state", "_cluster/stats",
+	"_component_template", "_data_stream", "_index_template", "_template",
+	"_ilm/policy", "_ilm/start", "_ilm/status", "_ilm/stop",
+	"_ingest/pipeline", "_ingest/pipeline/_simulate",
+	"_license", "_migration/deprecations", "_remote/info", "_health_report",
+	"_nodes", "_nodes/hot_threads", "_nodes/stats", "_nodes/usage",
+	"_security/_authenticate", "_security/api_key", "_security/role", "_security/user",
+	"_slm/policy", "_slm/stats", "_snapshot", "_snapshot/_status",
+	"_transform", "_xpack",
+}
+
+// indexEndpoints are the APIs offered after an index name.
+var indexEndpoints = []string{
+	"_alias", "_analyze", "_block/write", "_bulk", "_cache/clear", "_clone", "_close", "_count", "_create",
+	"_delete_by_query", "_disk_usage", "_doc", "_eql/search", "_explain", "_field_caps", "_flush",
+	"_forcemerge", "_ilm/explain", "_mapping", "_mget", "_msearch", "_open", "_pit", "_recovery",
+	"_refresh", "_rollover", "_search", "_segments", "_settings", "_shard_stores", "_shrink", "_split",
+	"_stats", "_termvectors", "_update", "_update_by_query", "_validate/query",
+}
+
+// nameEndpoints take an index, alias or data stream name as their next
+// segment, like /_cat/indices/logs-*.
+var nameEndpoints = map[string]bool{
+	"_alias": true, "_cat/aliases": true, "_cat/count": true, "_cat/indices": true, "_cat/recovery": true,
+	"_cat/segments": true, "_cat/shards": true, "_cluster/health": true, "_data_stream": true,
+	"_resolve/index": true,
+}
+
+// commonParams work on every API.
+var commonParams = []string{"error_trace", "filter_path=", "human", "pretty"}
+
+// queryParams are offered after ? for paths containing the key segment.
+var queryParams = map[string][]string{
+	"_cat":             {"bytes=", "expand_wildcards=all", "format=json", "h=", "health=", "help", "s=", "v"},
+	"_cluster":         {"flat_settings", "include_defaults", "level=indices", "local", "timeout=", "wait_for_status="},
+	"_count":           {"expand_wildcards=all", "ignore_unavailable=true", "q=", "routing="},
+	"_delete_by_query": {"conflicts=proceed", "max_docs=", "refresh", "requests_per_second=", "scroll_size=", "slices=auto", "wait_for_completion=false"},
+	"_doc":             {"if_primary_term=", "if_seq_no=", "op_type=create", "pipeline=", "refresh=wait_for", "routing="},
+	"_bulk":            {"pipeline=", "refresh=wait_for", "routing="},
+	"_forcemerge":      {"max_num_segments=1", "only_expunge_deletes", "wait_for_completion=false"},
+	"_nodes":           {"flat_settings", "timeout="},
+	"_query":           {"drop_null_columns", "format=txt"},
+	"_reindex":         {"refresh", "requests_per_second=", "slices=auto", "wait_for_completion=false"},
+	"_search": {"_source=", "allow_no_indices=true", "allow_partial_search_results=", "expand_wildcards=all",
+		"explain", "from=", "ignore_unavailable=true", "preference=", "q=", "request_cache=", "rest_total_hits_as_int",
+		"routing=", "scroll=", "search_type=dfs_query_then_fetch", "size=", "sort=", "timeout=", "track_total_hits=true",
+		"typed_keys"},
+	"_settings":        {"flat_settings", "include_defaults"},
+	"_sql":             {"format=txt"},
+	"_tasks":           {"actions=", "detailed", "group_by=parents", "nodes=", "wait_for_completion="},
+	"_update_by_query": {"conflicts=proceed", "max_docs=", "pipeline=", "refresh", "requests_per_second=", "scroll_size=", "slices=auto", "wait_for_completion=false"},
+}
+
+// backingIndexPattern matches the backing indices of a data stream:
+// .ds-<data stream>-<yyyy.MM.dd>-<generation>.
+var backingIndexPattern = regexp.MustCompile(`^\.ds-(.+)-\d{4}\.\d{2}\.\d{2}-\d{6}$`)
+
+// IndexNameCompletions lists the aliases, data streams and indices of the
+// cluster for the path input. Data streams are found through the names of
+// their backing indices.
+func IndexNameCompletions(state *es.ClusterState) []CompletionItem {
+	if state == nil {
+		return nil
+	}
+	var items []CompletionItem
+	seen := make(map[string]bool)
+	add := func(name, kind string) {
+		if !seen[name] {
+			seen[name] = true
+			items = append(items, CompletionItem{Text: name, Kind: kind})
+		}
+	}
+	for _, alias := range state.Aliases {
+		add(alias.Alias, "alias")
+	}
+	for _, index := range state.Indices {
+		if m := backingIndexPattern.FindStringSubmatch(index.Name); m != nil {
+			add(m[1], "data stream")
+		}
+	}
+	for _, index := range state.Indices {
+		add(index.Name, "index")
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Text < items[j].Text })
+	return items
+}
+
+// PathCompletions returns the completions for the word before the cursor
+// in the path input, and the rune offset where that word starts: API
+// endpoints, query parameters after ?, or index names. names comes from
+// IndexNameCompletions.
+func PathCompletions(path string, names []CompletionItem) ([]CompletionItem, int) {
+	var items []CompletionItem
+	var word string
+	rest := strings.TrimPrefix(path, "/")
+	if q := strings.IndexByte(path, '?'); q >= 0 {
+		items, word = paramCompletions(path[:q], path[q+1:])
+	} else if strings.HasPrefix(rest, "_") {
+		if slash := strings.LastIndexByte(rest, '/'); slash >= 0 && nameEndpoints[rest[:slash]] {
+			items, word = nameCompletions(rest[slash+1:], names)
+		} else {
+			items, word = endpointCompletions(restEndpoints, rest), rest
+		}
+	} else if slash := strings.IndexByte(rest, '/'); slash >= 0 {
+		word = rest[slash+1:]
+		items = endpointCompletions(indexEndpoints, word)
+	} else {
+		items, word = nameCompletions(rest, names)
+	}
+	return items, utf8.RuneCountInString(path) - utf8.RuneCountInString(word)
+}
+
+func endpointCompletions(endpoints []string, word string) []CompletionItem {
+	var items []CompletionItem
+	for _, endpoint := range endpoints {
+		if strings.HasPrefix(endpoint, word) {
+			items = append(items, CompletionItem{Text: endpoint, Kind: "api"})
+		}
+	}
+	return items
+}
+
+// nameCompletions completes the last name of a comma-separated list.
+// Names sharing everything up to their last -, . or _ are offered as one
+// wildcard pattern first, so /log offers logs-2026.10.* before each day.
+func nameCompletions(list string, names []CompletionItem) ([]CompletionItem, string) {
+	word := list[strings.LastIndexByte(list, ',')+1:]
+
+	var matches []CompletionItem
+	counts := make(map[string]int)
+	var patterns []string
+	for _, name := range names {
+		if !strings.HasPrefix(name.Text, word) || (strings.HasPrefix(name.Text, ".") && !strings.HasPrefix(word, ".")) {
+			continue
+		}
+		matches = append(matches, name)
+		if name.Kind != "index" {
+			continue
+		}
+		if cut := strings.LastIndexAny(name.Text, "-._"); cut >= len(word) {
+			pattern := name.Text[:cut+1] + "*"
+			if counts[pattern] == 0 {
+				patterns = append(patterns, pattern)
+			}
+			counts[pattern]++
+		}
+	}
+
+	var items []CompletionItem
+	for _, pattern := range patterns {
+		if counts[pattern] > 1 {
+			items = append(items, CompletionItem{Text: pattern, Kind: fmt.Sprintf("%d indices", counts[pattern])})
+		}
+	}
+	return append(items, matches...), word
+}
+
+// paramCompletions offers the query parameters of the API in path that
+// are not set yet. Values are left to the user.
+func paramCompletions(path, query string) ([]CompletionItem, string) {
+	word := query[strings.LastIndexByte(query, '&')+1:]
+	if strings.Contains(word, "=") {
+		return nil, word
+	}
+
+	set := make(map[string]bool)
+	for _, param := range strings.Split(query, "&") {
+		name, _, _ := strings.Cut(param, "=")
+		set[name] = true
+	}
+	params := commonParams
+	for _, segment := range strings.Split(path, "/") {
+		if specific, ok := queryParams[segment]; ok {
+			params = append(append([]string{}, specific...), commonParams...)
+		}
+	}
+
+	var items []CompletionItem
+	for _, param := range params {
+		name := strings.TrimSuffix(param, "=")
+		name, _, _ = strings.Cut(name, "=")
+		if strings.HasPrefix(param, word) && (!set[name] || name == word) {
+			items = append(items, CompletionItem{Text: param, Kind: "param"})
+		}
+	}
+	return items, word
+}
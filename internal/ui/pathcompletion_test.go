@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+var pathTestState = &es.ClusterState{
+	Indices: []es.IndexInfo{
+		{Name: "logs-2026.10.01"}, {Name: "logs-2026.10.02"}, {Name: "logs-2026.10.03"},
+		{Name: "logs-archive"}, {Name: "metrics"}, {Name: ".kibana_1"},
+		{Name: ".ds-traces-app-2026.10.01-000001"}, {Name: ".ds-traces-app-2026.10.08-000002"},
+	},
+	Aliases: []es.AliasInfo{{Alias: "logs", Index: "logs-2026.10.03"}, {Alias: "logs", Index: "logs-2026.10.02"}},
+}
+
+func TestPathCompletions(t *testing.T) {
+	names := IndexNameCompletions(pathTestState)
+	tests := []struct {
+		path      string
+		want      []string // text (kind) of the first completions
+		wantStart int
+	}{
+		{"/log", []string{"logs-2026.10.* (3 indices)", "logs (alias)", "logs-2026.10.01 (index)"}, 1},
+		{"/logs-2026.10.0", []string{"logs-2026.10.01 (index)", "logs-2026.10.02 (index)"}, 1},
+		{"/metrics,tr", []string{"traces-app (data stream)"}, 9},
+		{"/.k", []string{".kibana_1 (index)"}, 1},
+		{"/_cat/in", []string{"_cat/indices (api)"}, 1},
+		{"/_cat/indices/met", []string{"metrics (index)"}, 14},
+		{"/logs/_se", []string{"_search (api)", "_segments (api)", "_settings (api)"}, 6},
+		{"/logs/_search?si", []string{"size= (param)"}, 14},
+		{"/logs/_search?size=10&tr", []string{"track_total_hits=true (param)"}, 22},
+		{"/_cat/shards?v&h", []string{"h= (param)", "health= (param)", "help (param)", "human (param)"}, 15},
+		{"/_cluster/health?pre", []string{"pretty (param)"}, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			items, start := PathCompletions(tt.path, names)
+			var got []string
+			for _, item := range items[:min(len(items), len(tt.want))] {
+				got = append(got, item.Text+" ("+item.Kind+")")
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") || start != tt.wantStart {
+				t.Errorf("PathCompletions(%q) = %v at %d, want %v at %d", tt.path, got, start, tt.want, tt.wantStart)
+			}
+		})
+	}
+
+	for _, path := range []string{"/nothing", "/logs/_search?size=1"} {
+		if items, _ := PathCompletions(path, names); len(items) != 0 {
+			t.Errorf("PathCompletions(%q) = %v, want none", path, items)
+		}
+	}
+	if items, _ := PathCompletions("/log", names); len(items) != 6 {
+		t.Errorf("hidden indices should not be offered without a leading dot, got %v", items)
+	}
+}
+
+func TestWorkbenchPathCompletion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.SetIndexNames(IndexNameCompletions(pathTestState))
+	w.Focus()
+	w.path.SetValue("/met")
+	w.path.CursorEnd()
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if w.path.Value() != "/metrics" || w.focus != FocusPath {
+		t.Fatalf("a single match should be filled in, got %q", w.path.Value())
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if w.focus != FocusBody {
+		t.Fatal("Tab with nothing left to complete should move to the body")
+	}
+
+	w.Focus()
+	w.path.SetValue("/lo")
+	w.path.CursorEnd()
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	if w.path.Value() != "/logs" || !w.pathCompletion.Active {
+		t.Fatalf("several matches should fill in what they share and open the list, got %q", w.path.Value())
+	}
+	if view := ansi.Strip(w.View()); !strings.Contains(view, "logs-2026.10.* (3 indices)") {
+		t.Errorf("the list should show under the path, got\n%s", view)
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: '-', Text: "-"})
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if len(w.pathCompletion.Filtered) != 1 {
+		t.Errorf("typing should narrow the list, got %v", w.pathCompletion.Filtered)
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+	if w.path.Value() != "/logs-archive" || w.pathCompletion.Active {
+		t.Errorf("Enter should take the selected name, got %q", w.path.Value())
+	}
+
+	w.path.SetValue("/logs-archive/_search?")
+	w.path.CursorEnd()
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyTab})
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if w.pathCompletion.Active || w.focus != FocusPath {
+		t.Error("Esc should close the list and keep the path focused")
+	}
+}
@@ -0,0 +1,417 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// profileSlowest is how many components are highlighted as the slowest.
+const profileSlowest = 3
+
+// profileBarWidth is the width of the share-of-shard bar, brackets excluded.
+const profileBarWidth = 8
+
+// profileComponent is a query, collector or aggregation in the profile
+// response, or its fetch phase. Collectors have a name and reason instead
+// of a type and description.
+type profileComponent struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Name        string             `json:"name"`
+	Reason      string             `json:"reason"`
+	TimeInNanos int64              `json:"time_in_nanos"`
+	Breakdown   map[string]int64   `json:"breakdown"`
+	Children    []profileComponent `json:"children"`
+}
+
+type profileResponse struct {
+	Profile struct {
+		Shards []struct {
+			ID       string `json:"id"`
+			Searches []struct {
+				Query       []profileComponent `json:"query"`
+				RewriteTime int64              `json:"rewrite_time"`
+				Collector   []profileComponent `json:"collector"`
+			} `json:"searches"`
+			Aggregations []profileComponent `json:"aggregations"`
+			Fetch        *profileComponent  `json:"fetch"`
+		} `json:"shards"`
+	} `json:"profile"`
+}
+
+// profileTiming is one entry of a component's breakdown, with the count
+// of calls recorded next to it as <name>_count. Entries that took no time
+// are left out; a query has a dozen of them.
+type profileTiming struct {
+	name  string
+	nanos int64
+	count int64
+}
+
+// profileNode is one line of the profile tree: a shard or section heading,
+// or a component with its own time and breakdown.
+type profileNode struct {
+	label     string
+	detail    string
+	nanos     int64
+	self      int64 // nanos minus the children's: time spent in the component itself
+	shard     int64 // total time of the shard, for the share bar
+	breakdown []profileTiming
+	depth     int
+	heading   bool
+	rank      int // 1 for the slowest component by self time, 0 past profileSlowest
+}
+
+// profileRow is a node, or one timing of its unfolded breakdown.
+type profileRow struct {
+	node   int
+	timing int // -1 for the node itself
+}
+
+// SearchProfile shows the profile section of a search response run with
+// "profile": true: per shard, the query tree with rewrite and collector
+// times, the aggregation tree and the fetch phase. Each component unfolds
+// into its timing breakdown, and the slowest are highlighted.
+type SearchProfile struct {
+	nodes    []profileNode
+	unfolded []bool
+	rows     []profileRow
+	shards   int
+	nav      ListNav
+}
+
+var shardIDPattern = regexp.MustCompile(`^\[([^\]]*)\]\[(.*)\]\[(\d+)\]$`)
+
+// NewSearchProfile builds the profile view of a response. It fails when
+// the response has no profile.
+func NewSearchProfile(text string) (*SearchProfile, error) {
+	var response profileResponse
+	if err := json.Unmarshal([]byte(text), &response); err != nil {
+		return nil, err
+	}
+	if len(response.Profile.Shards) == 0 {
+		return nil, fmt.Errorf("response has no profile")
+	}
+
+	p := &SearchProfile{nav: NewCursorNav(), shards: len(response.Profile.Shards)}
+	for _, shard := range response.Profile.Shards {
+		label := "Shard " + shard.ID
+		if m := shardIDPattern.FindStringSubmatch(shard.ID); m != nil {
+			label = fmt.Sprintf("Shard %s[%s] on %s", m[2], m[3], m[1])
+		}
+		heading := p.add(profileNode{label: label, heading: true}, nil)
+
+		var total int64
+		for _, search := range shard.Searches {
+			query := p.add(profileNode{label: "Query", heading: true, depth: 1}, nil)
+			p.nodes[query].nanos = p.addComponents(search.Query, 2)
+			rewrite := p.add(profileNode{label: "Rewrite", heading: true, depth: 1, nanos: search.RewriteTime}, nil)
+			collectors := p.add(profileNode{label: "Collectors", heading: true, depth: 1}, nil)
+			p.nodes[collectors].nanos = p.addComponents(search.Collector, 2)
+			total += p.nodes[query].nanos + p.nodes[rewrite].nanos
+		}
+		if len(shard.Aggregations) > 0 {
+			aggs := p.add(profileNode{label: "Aggregations", heading: true, depth: 1}, nil)
+			p.nodes[aggs].nanos = p.addComponents(shard.Aggregations, 2)
+			total += p.nodes[aggs].nanos
+		}
+		if shard.Fetch != nil {
+			fetch := p.add(profileNode{label: "Fetch", heading: true, depth: 1}, nil)
+			p.nodes[fetch].nanos = p.addComponents([]profileComponent{*shard.Fetch}, 2)
+			total += p.nodes[fetch].nanos
+		}
+		p.nodes[heading].nanos = total
+		for i := heading; i < len(p.nodes); i++ {
+			p.nodes[i].shard = total
+		}
+	}
+
+	p.rankSlowest()
+	p.unfolded = make([]bool, len(p.nodes))
+	p.refresh()
+	return p, nil
+}
+
+func (p *SearchProfile) add(n profileNode, breakdown map[string]int64) int {
+	for name, nanos := range breakdown {
+		if strings.HasSuffix(name, "_count") || nanos == 0 {
+			continue
+		}
+		n.breakdown = append(n.breakdown, profileTiming{name: name, nanos: nanos, count: breakdown[name+"_count"]})
+	}
+	slices.SortFunc(n.breakdown, func(a, b profileTiming) int {
+		return cmp.Or(cmp.Compare(b.nanos, a.nanos), strings.Compare(a.name, b.name))
+	})
+	p.nodes = append(p.nodes, n)
+	return len(p.nodes) - 1
+}
+
+// addComponents adds a list of components and their children depth first,
+// returning their total time.
+func (p *SearchProfile) addComponents(components []profileComponent, depth int) int64 {
+	var total int64
+	for _, c := range components {
+		label, detail := c.Type, c.Description
+		if c.Name != "" {
+			label, detail = c.Name, c.Reason
+		}
+		i := p.add(profileNode{label: label, detail: detail, nanos: c.TimeInNanos, depth: depth}, c.Breakdown)
+		children := p.addComponents(c.Children, depth+1)
+		p.nodes[i].self = max(0, c.TimeInNanos-children)
+		total += c.TimeInNanos
+	}
+	return total
+}
+
+// rankSlowest marks the components that spent the most time themselves.
+// Collectors are left out: their time includes the scoring already
+// counted in the query tree.
+func (p *SearchProfile) rankSlowest() {
+	var candidates []int
+	section := ""
+	for i, n := range p.nodes {
+		if n.depth == 1 {
+			section = n.label
+		}
+		if !n.heading && n.self > 0 && section != "Collectors" {
+			candidates = append(candidates, i)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b int) int {
+		return cmp.Compare(p.nodes[b].self, p.nodes[a].self)
+	})
+	for rank, i := range candidates[:min(profileSlowest, len(candidates))] {
+		p.nodes[i].rank = rank + 1
+	}
+}
+
+func (p *SearchProfile) refresh() {
+	p.rows = p.rows[:0]
+	for i, n := range p.nodes {
+		p.rows = append(p.rows, profileRow{node: i, timing: -1})
+		if p.unfolded[i] {
+			for t := range n.breakdown {
+				p.rows = append(p.rows, profileRow{node: i, timing: t})
+			}
+		}
+	}
+	p.nav.Clamp(len(p.rows))
+}
+
+func (p *SearchProfile) cursorNode() int {
+	if len(p.rows) == 0 {
+		return 0
+	}
+	return p.rows[p.nav.Selected].node
+}
+
+func (p *SearchProfile) selectNode(node, visible int) {
+	for r, row := range p.rows {
+		if row.node == node && row.timing < 0 {
+			p.nav.Selected = r
+			break
+		}
+	}
+	if p.nav.Selected < p.nav.Scroll {
+		p.nav.Scroll = p.nav.Selected
+	} else if p.nav.Selected >= p.nav.Scroll+visible {
+		p.nav.Scroll = p.nav.Selected - visible + 1
+	}
+}
+
+// Toggle unfolds or folds the breakdown of the component under the cursor.
+func (p *SearchProfile) Toggle(visible int) {
+	node := p.cursorNode()
+	if len(p.nodes[node].breakdown) == 0 {
+		return
+	}
+	p.unfolded[node] = !p.unfolded[node]
+	p.refresh()
+	p.selectNode(node, visible)
+}
+
+// SetAllUnfolded shows or hides every breakdown.
+func (p *SearchProfile) SetAllUnfolded(unfolded bool, visible int) {
+	node := p.cursorNode()
+	for i := range p.unfolded {
+		p.unfolded[i] = unfolded
+	}
+	p.refresh()
+	p.selectNode(node, visible)
+}
+
+// NextSlowest moves the cursor to the next highlighted component, wrapping
+// around after the last.
+func (p *SearchProfile) NextSlowest(visible int) {
+	node := p.cursorNode()
+	for step := 1; step <= len(p.nodes); step++ {
+		next := (node + step) % len(p.nodes)
+		if p.nodes[next].rank > 0 {
+			p.selectNode(next, visible)
+			return
+		}
+	}
+}
+
+// HandleKey moves through the profile and folds breakdowns. It reports
+// whether the key was used.
+func (p *SearchProfile) HandleKey(key string, visible int) bool {
+	switch key {
+	case "enter", "space", "right", "left", "l", "h":
+		p.Toggle(visible)
+	case "+", "=":
+		p.SetAllUnfolded(true, visible)
+	case "-":
+		p.SetAllUnfolded(false, visible)
+	case "s":
+		p.NextSlowest(visible)
+	default:
+		return p.nav.HandleKey(key, len(p.rows), visible)
+	}
+	return true
+}
+
+func (p *SearchProfile) HandleWheel(down bool, visible int) {
+	p.nav.HandleWheel(down, len(p.rows), visible)
+}
+
+// Summary describes the profile and its slowest component, for the line
+// above the view.
+func (p *SearchProfile) Summary() string {
+	shards := "1 shard"
+	if p.shards != 1 {
+		shards = fmt.Sprintf("%d shards", p.shards)
+	}
+	for _, n := range p.nodes {
+		if n.rank == 1 {
+			return fmt.Sprintf("%s  slowest: %s %s (%s)", shards, n.label, n.detail, formatNanos(n.self))
+		}
+	}
+	return shards
+}
+
+// SearchLines returns one line per row for the search bar.
+func (p *SearchProfile) SearchLines() []string {
+	lines := make([]string, len(p.rows))
+	for r, row := range p.rows {
+		n := p.nodes[row.node]
+		if row.timing >= 0 {
+			lines[r] = n.breakdown[row.timing].name
+		} else {
+			lines[r] = n.label + " " + n.detail
+		}
+	}
+	return lines
+}
+
+// Reveal moves the cursor to a row found by the search bar.
+func (p *SearchProfile) Reveal(row, visible int) {
+	if row < 0 || row >= len(p.rows) {
+		return
+	}
+	p.nav.Selected = row
+	if row < p.nav.Scroll {
+		p.nav.Scroll = row
+	} else if row >= p.nav.Scroll+visible {
+		p.nav.Scroll = row - visible + 1
+	}
+}
+
+// formatNanos shortens a duration to three significant digits or so:
+// 950ns, 12.3µs, 4.56ms, 1.2s.
+func formatNanos(nanos int64) string {
+	d := time.Duration(nanos)
+	switch {
+	case d < time.Microsecond:
+		return fmt.Sprintf("%dns", nanos)
+	case d < time.Millisecond:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	case d < time.Second:
+		return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// rowText renders a row as its left part, indented, and its timing on the
+// right: the rank of the slowest components, the time and its share of
+// the shard as a bar, or of the component for a breakdown timing.
+func (p *SearchProfile) rowText(row profileRow) (left, right string) {
+	n := p.nodes[row.node]
+	indent := strings.Repeat("  ", n.depth)
+	if row.timing >= 0 {
+		t := n.breakdown[row.timing]
+		left = indent + "    " + t.name
+		if t.count > 0 {
+			left += fmt.Sprintf(" ×%d", t.count)
+		}
+		share := 0.0
+		if n.nanos > 0 {
+			share = float64(t.nanos) / float64(n.nanos) * 100
+		}
+		return left, fmt.Sprintf("   %9s %*s", formatNanos(t.nanos), profileBarWidth+2, fmt.Sprintf("%.0f%%", share))
+	}
+
+	marker := "  "
+	if len(n.breakdown) > 0 {
+		marker = "▸ "
+		if p.unfolded[row.node] {
+			marker = "▾ "
+		}
+	}
+	left = indent + marker + n.label
+	if n.detail != "" {
+		left += "  " + n.detail
+	}
+	share := 0.0
+	if n.shard > 0 {
+		share = float64(n.nanos) / float64(n.shard) * 100
+	}
+	rank := ""
+	if n.rank > 0 {
+		rank = fmt.Sprintf("#%d", n.rank)
+	}
+	return left, fmt.Sprintf("%-3s%9s %s", rank, formatNanos(n.nanos), RenderBar(share, profileBarWidth))
+}
+
+// View renders height rows starting at the scroll position. Headings are
+// bold, the slowest components red with their rank, and the cursor row is
+// drawn on the selection background.
+func (p *SearchProfile) View(width, height int) []string {
+	end := min(p.nav.Scroll+height, len(p.rows))
+	lines := make([]string, 0, end-p.nav.Scroll)
+	for r := p.nav.Scroll; r < end; r++ {
+		row := p.rows[r]
+		n := p.nodes[row.node]
+		left, right := p.rowText(row)
+		leftWidth := max(1, width-ansi.StringWidth(right)-1)
+		left = ansi.Truncate(left, leftWidth, "…")
+		left += strings.Repeat(" ", leftWidth-ansi.StringWidth(left)+1)
+
+		if r == p.nav.Selected {
+			line := ansi.Strip(left + right)
+			lines = append(lines, lipgloss.NewStyle().Background(ActiveBg).Width(width).Render(ansi.Truncate(line, width, "")))
+			continue
+		}
+		style := lipgloss.NewStyle()
+		switch {
+		case row.timing == 0 && len(n.breakdown) > 1:
+			style = style.Foreground(ColorYellow)
+		case row.timing >= 0:
+			style = style.Foreground(ColorGray)
+		case n.heading:
+			style = style.Bold(true)
+		case n.rank > 0:
+			style = style.Foreground(ColorRed).Bold(true)
+		}
+		lines = append(lines, style.Render(left)+right)
+	}
+	return lines
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const sampleProfile = `{
+  "took": 12,
+  "hits": {"total": {"value": 3, "relation": "eq"}, "hits": []},
+  "profile": {
+    "shards": [
+      {
+        "id": "[q2xRZs1TQhS][logs][0]",
+        "searches": [
+          {
+            "query": [
+              {
+                "type": "BooleanQuery",
+                "description": "+message:error +status:active",
+                "time_in_nanos": 9000000,
+                "breakdown": {"build_scorer": 1000000, "build_scorer_count": 2, "next_doc": 500000, "next_doc_count": 40, "score": 0, "score_count": 0},
+                "children": [
+                  {"type": "TermQuery", "description": "message:error", "time_in_nanos": 6000000,
+                   "breakdown": {"build_scorer": 5000000, "build_scorer_count": 2, "advance": 1000000, "advance_count": 12}},
+                  {"type": "TermQuery", "description": "status:active", "time_in_nanos": 1000000,
+                   "breakdown": {"build_scorer": 900000, "build_scorer_count": 2}}
+                ]
+              }
+            ],
+            "rewrite_time": 50000,
+            "collector": [
+              {"name": "QueryPhaseCollector", "reason": "search_query_phase", "time_in_nanos": 8000000,
+               "children": [{"name": "SimpleTopScoreDocCollector", "reason": "search_top_hits", "time_in_nanos": 700}]}
+            ]
+          }
+        ],
+        "aggregations": [
+          {"type": "GlobalOrdinalsStringTermsAggregator", "description": "by_host", "time_in_nanos": 3000000,
+           "breakdown": {"collect": 2500000, "collect_count": 3, "initialize": 500000, "initialize_count": 1}}
+        ]
+      }
+    ]
+  }
+}`
+
+func TestNewSearchProfile(t *testing.T) {
+	p, err := NewSearchProfile(sampleProfile)
+	if err != nil {
+		t.Fatalf("NewSearchProfile() error = %v", err)
+	}
+
+	var got []string
+	for _, n := range p.nodes {
+		got = append(got, strings.Repeat(" ", n.depth)+n.label)
+	}
+	want := []string{
+		"Shard logs[0] on q2xRZs1TQhS", " Query", "  BooleanQuery", "   TermQuery", "   TermQuery",
+		" Rewrite", " Collectors", "  QueryPhaseCollector", "   SimpleTopScoreDocCollector",
+		" Aggregations", "  GlobalOrdinalsStringTermsAggregator",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("nodes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if total := p.nodes[0].nanos; total != 9000000+50000+3000000 {
+		t.Errorf("shard time = %d, want query + rewrite + aggregations", total)
+	}
+	// BooleanQuery spends 2ms itself; the collectors overlap the query and
+	// are not ranked.
+	ranks := map[string]int{}
+	for _, n := range p.nodes {
+		if n.rank > 0 {
+			ranks[n.label+" "+n.detail] = n.rank
+		}
+	}
+	if ranks["TermQuery message:error"] != 1 || ranks["GlobalOrdinalsStringTermsAggregator by_host"] != 2 ||
+		ranks["BooleanQuery +message:error +status:active"] != 3 || len(ranks) != 3 {
+		t.Errorf("ranks = %v", ranks)
+	}
+
+	breakdown := p.nodes[3].breakdown
+	if len(breakdown) != 2 || breakdown[0] != (profileTiming{name: "build_scorer", nanos: 5000000, count: 2}) {
+		t.Errorf("breakdown = %+v, want timings slowest first with their counts", breakdown)
+	}
+
+	if _, err := NewSearchProfile(`{"hits": {"hits": []}}`); err == nil {
+		t.Error("a response without a profile should not give a profile view")
+	}
+}
+
+func TestSearchProfileKeys(t *testing.T) {
+	p, _ := NewSearchProfile(sampleProfile)
+	p.HandleKey("s", 10)
+	if node := p.cursorNode(); p.nodes[node].detail != "+message:error +status:active" {
+		t.Errorf("s moved to %q, want the first highlighted component", p.nodes[node].label)
+	}
+	p.HandleKey("s", 10)
+	p.HandleKey("enter", 10)
+	if len(p.rows) != len(p.nodes)+2 || p.rows[p.nav.Selected+1].timing != 0 {
+		t.Errorf("enter should unfold the breakdown below the component, rows = %v", p.rows)
+	}
+	lines := p.View(80, 20)
+	if line := ansi.Strip(lines[4]); !strings.Contains(line, "build_scorer ×2") || !strings.Contains(line, "5.00ms") || !strings.Contains(line, "83%") {
+		t.Errorf("breakdown line = %q", line)
+	}
+	if line := ansi.Strip(lines[3]); !strings.Contains(line, "#1") || !strings.Contains(line, "6.00ms") {
+		t.Errorf("component line = %q, want its rank and time", line)
+	}
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w > 80 {
+			t.Errorf("line is %d wide: %q", w, ansi.Strip(line))
+		}
+	}
+
+	p.HandleKey("-", 10)
+	if len(p.rows) != len(p.nodes) {
+		t.Error("- should fold every breakdown")
+	}
+	p.HandleKey("+", 10)
+	if len(p.rows) != len(p.nodes)+7 {
+		t.Errorf("+ should unfold every breakdown, got %d rows", len(p.rows))
+	}
+}
+
+func TestFormatNanos(t *testing.T) {
+	tests := map[int64]string{950: "950ns", 12345: "12.3µs", 4560000: "4.56ms", 1200000000: "1.20s"}
+	for nanos, want := range tests {
+		if got := formatNanos(nanos); got != want {
+			t.Errorf("formatNanos(%d) = %q, want %q", nanos, got, want)
+		}
+	}
+}
+
+func TestWorkbenchProfileView(t *testing.T) {
+	w := newWorkbenchWithResult(t, sampleProfile)
+	w.SetSize(160, 30)
+
+	if view := ansi.Strip(w.View()); !strings.Contains(view, "P profile") {
+		t.Error("the response header should offer the profile view")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'P', Text: "P"})
+	view := ansi.Strip(w.View())
+	if !strings.Contains(view, "[profile]") || !strings.Contains(view, "1 shard  slowest: TermQuery message:error (6.00ms)") ||
+		!strings.Contains(view, "GlobalOrdinalsStringTermsAggregator") {
+		t.Errorf("profile view missing:\n%s", view)
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	if w.profileActive() || !w.treeActive() {
+		t.Error("the tree view should replace the profile view")
+	}
+
+	w = showResult(w, `{"hits": {"hits": []}}`)
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'P', Text: "P"})
+	if w.profileActive() || !strings.Contains(w.ClipboardMessage(), "profile") {
+		t.Errorf("P without a profile should explain why, notice = %q", w.ClipboardMessage())
+	}
+}
@@ -0,0 +1,332 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+)
+
+// QueryWarning is a query that is valid JSON and valid DSL but unlikely
+// to do what was meant, given the index mapping.
+type QueryWarning struct {
+	Line    int
+	Col     int
+	Message string
+}
+
+// lintNode is a JSON value with the offset where it starts, so warnings
+// can point at the line that caused them.
+type lintNode struct {
+	offset  int
+	members []lintMember // objects
+	items   []*lintNode  // arrays
+	value   any          // scalars
+	object  bool
+}
+
+type lintMember struct {
+	key    string
+	offset int
+	value  *lintNode
+}
+
+var (
+	textTypes    = map[string]bool{"text": true, "match_only_text": true}
+	keywordTypes = map[string]bool{"keyword": true, "constant_keyword": true, "wildcard": true}
+	numericTypes = map[string]bool{
+		"long": true, "integer": true, "short": true, "byte": true, "double": true, "float": true,
+		"half_float": true, "scaled_float": true, "unsigned_long": true, "date": true, "date_nanos": true,
+	}
+)
+
+// fieldQueries name their field as the only key of their body, next to
+// options like boost.
+var fieldQueries = map[string]bool{
+	"term": true, "terms": true, "match": true, "match_phrase": true, "match_phrase_prefix": true,
+	"match_bool_prefix": true, "range": true, "prefix": true, "wildcard": true, "regexp": true, "fuzzy": true,
+}
+
+// compoundQueries hold further queries under these keys.
+var compoundQueries = map[string][]string{
+	"bool":           {"must", "should", "filter", "must_not"},
+	"constant_score": {"filter"},
+	"function_score": {"query"},
+	"script_score":   {"query"},
+	"dis_max":        {"queries"},
+	"boosting":       {"positive", "negative"},
+	"nested":         {"query"},
+	"has_child":      {"query"},
+	"has_parent":     {"query"},
+}
+
+// LintQuery checks the queries and aggregations of a search body against
+// the field types of the index. It returns nothing for invalid JSON, which
+// has its own marker, or when the mapping is not known.
+func LintQuery(body string, fields map[string]string) []QueryWarning {
+	if len(fields) == 0 || strings.TrimSpace(body) == "" {
+		return nil
+	}
+	root, err := parseLintNode(body, json.NewDecoder(strings.NewReader(body)))
+	if err != nil || !root.object {
+		return nil
+	}
+	l := queryLinter{body: body, fields: withRuntimeFields(fields, root)}
+	for _, m := range root.members {
+		switch m.key {
+		case "query", "post_filter":
+			l.queries(m.value)
+		case "aggs", "aggregations":
+			l.aggs(m.value)
+		}
+	}
+	return l.warnings
+}
+
+// withRuntimeFields adds the fields the search defines for itself in
+// runtime_mappings to those of the mapping, which is left unchanged.
+func withRuntimeFields(fields map[string]string, root *lintNode) map[string]string {
+	for _, m := range root.members {
+		if m.key != "runtime_mappings" || len(m.value.members) == 0 {
+			continue
+		}
+		fields = maps.Clone(fields)
+		for _, field := range m.value.members {
+			typ := ""
+			for _, option := range field.value.members {
+				if option.key == "type" {
+					typ, _ = option.value.value.(string)
+				}
+			}
+			fields[field.key] = typ
+		}
+	}
+	return fields
+}
+
+type queryLinter struct {
+	body     string
+	fields   map[string]string
+	warnings []QueryWarning
+}
+
+func (l *queryLinter) warn(offset int, format string, args ...any) {
+	line, col := offsetToLineCol(l.body, offset)
+	l.warnings = append(l.warnings, QueryWarning{Line: line, Col: col, Message: fmt.Sprintf(format, args...)})
+}
+
+// queries walks a query clause, or an array of them as in bool.must.
+func (l *queryLinter) queries(n *lintNode) {
+	for _, item := range n.items {
+		l.queries(item)
+	}
+	for _, m := range n.members {
+		l.clause(m)
+	}
+}
+
+func (l *queryLinter) clause(q lintMember) {
+	body := q.value
+	if keys, ok := compoundQueries[q.key]; ok {
+		for _, m := range body.members {
+			for _, key := range keys {
+				if m.key == key {
+					l.queries(m.value)
+				}
+			}
+			if q.key == "nested" && m.key == "path" {
+				l.nestedPath(m)
+			}
+			if q.key == "function_score" && m.key == "functions" {
+				for _, fn := range m.value.items {
+					if filter := fn.member("filter"); filter != nil {
+						l.queries(filter)
+					}
+				}
+			}
+		}
+		return
+	}
+
+	switch {
+	case fieldQueries[q.key]:
+		for _, m := range body.members {
+			if m.key != "boost" && m.key != "_name" {
+				l.field(q.key, m.key, m.offset)
+			}
+		}
+	case q.key == "exists":
+		if field, ok := body.memberValue("field").(string); ok {
+			l.field(q.key, field, body.member("field").offset)
+		}
+	case q.key == "multi_match" || q.key == "query_string" || q.key == "simple_query_string":
+		if list := body.member("fields"); list != nil {
+			for _, item := range list.items {
+				if field, ok := item.value.(string); ok {
+					field, _, _ = strings.Cut(field, "^")
+					l.field(q.key, field, item.offset)
+				}
+			}
+		}
+	}
+}
+
+// fieldType returns the mapped type of field. Keys inside a flattened
+// field are not in the mapping; they are indexed as keywords.
+func (l *queryLinter) fieldType(field string) (string, bool) {
+	if typ, ok := l.fields[field]; ok {
+		return typ, true
+	}
+	for i := strings.LastIndex(field, "."); i > 0; i = strings.LastIndex(field[:i], ".") {
+		if l.fields[field[:i]] == "flattened" {
+			return "keyword", true
+		}
+	}
+	return "", false
+}
+
+// field checks one field used by a query.
+func (l *queryLinter) field(query, field string, offset int) {
+	typ, ok := l.fieldType(field)
+	if !ok {
+		if !strings.HasPrefix(field, "_") && !strings.Contains(field, "*") {
+			l.warn(offset, "unknown field %q", field)
+		}
+		return
+	}
+	switch {
+	case (query == "term" || query == "terms") && textTypes[typ]:
+		hint := "use match"
+		if keywordTypes[l.fields[field+".keyword"]] {
+			hint += fmt.Sprintf(" or %s on %s.keyword", query, field)
+		}
+		l.warn(offset, "%s on text field %q only matches single analyzed tokens: %s", query, field, hint)
+	case strings.HasPrefix(query, "match") && numericTypes[typ]:
+		l.warn(offset, "%s on %s field %q: use term or range", query, typ, field)
+	case query == "range" && keywordTypes[typ]:
+		l.warn(offset, "range on keyword field %q compares strings, not numbers or dates", field)
+	}
+}
+
+func (l *queryLinter) aggField(agg, field string, offset int) {
+	typ, ok := l.fieldType(field)
+	switch {
+	case !ok:
+		l.field(agg, field, offset)
+	case textTypes[typ] && agg != "significant_text":
+		hint := "use a keyword field"
+		if keywordTypes[l.fields[field+".keyword"]] {
+			hint = "use " + field + ".keyword"
+		}
+		l.warn(offset, "%s aggregation on text field %q needs fielddata: %s", agg, field, hint)
+	}
+}
+
+func (l *queryLinter) nestedPath(m lintMember) {
+	path, ok := m.value.value.(string)
+	if !ok {
+		return
+	}
+	switch typ, known := l.fieldType(path); {
+	case !known:
+		l.warn(m.value.offset, "unknown field %q", path)
+	case typ != "nested":
+		l.warn(m.value.offset, "nested path %q is mapped as %s, not nested", path, typ)
+	}
+}
+
+// aggs checks the fields of aggregations and the queries of filter and
+// filters aggregations.
+func (l *queryLinter) aggs(n *lintNode) {
+	for _, agg := range n.members {
+		for _, m := range agg.value.members {
+			switch m.key {
+			case "aggs", "aggregations":
+				l.aggs(m.value)
+			case "filter":
+				l.queries(m.value)
+			case "filters":
+				if filters := m.value.member("filters"); filters != nil {
+					for _, f := range filters.members {
+						l.queries(f.value)
+					}
+					for _, f := range filters.items {
+						l.queries(f)
+					}
+				}
+			default:
+				if field, ok := m.value.memberValue("field").(string); ok {
+					l.aggField(m.key, field, m.value.member("field").offset)
+				}
+			}
+		}
+	}
+}
+
+func (n *lintNode) member(key string) *lintNode {
+	for _, m := range n.members {
+		if m.key == key {
+			return m.value
+		}
+	}
+	return nil
+}
+
+func (n *lintNode) memberValue(key string) any {
+	if v := n.member(key); v != nil {
+		return v.value
+	}
+	return nil
+}
+
+// parseLintNode reads the next value from dec, recording where each value
+// starts in text.
+func parseLintNode(text string, dec *json.Decoder) (*lintNode, error) {
+	offset := tokenStart(text, int(dec.InputOffset()))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &lintNode{offset: offset}
+	switch tok {
+	case json.Delim('{'):
+		n.object = true
+		for dec.More() {
+			keyOffset := tokenStart(text, int(dec.InputOffset()))
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseLintNode(text, dec)
+			if err != nil {
+				return nil, err
+			}
+			n.members = append(n.members, lintMember{key: fmt.Sprint(key), offset: keyOffset, value: value})
+		}
+	case json.Delim('['):
+		for dec.More() {
+			item, err := parseLintNode(text, dec)
+			if err != nil {
+				return nil, err
+			}
+			n.items = append(n.items, item)
+		}
+	default:
+		n.value = tok
+		return n, nil
+	}
+	if _, err := dec.Token(); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return n, nil
+}
+
+// tokenStart skips the whitespace and separators between the decoder's
+// offset and the next token.
+func tokenStart(text string, offset int) int {
+	for offset < len(text) && strings.IndexByte(" \t\r\n,:", text[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+var lintFields = map[string]string{
+	"title": "text", "title.keyword": "keyword", "body": "text", "status": "keyword",
+	"age": "long", "created": "date", "user": "object", "user.name": "keyword",
+	"comments": "nested", "comments.author": "keyword", "labels": "flattened",
+}
+
+func TestLintQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string // "line:col message" prefixes
+	}{
+		{"clean", `{"query": {"bool": {"must": [{"match": {"title": "x"}}, {"term": {"status": "ok"}}], "filter": {"range": {"age": {"gte": 3}}}}}}`, nil},
+		{"term on text", "{\n  \"query\": {\n    \"term\": {\"title\": \"Go\"}\n  }\n}", []string{
+			`3:14 term on text field "title" only matches single analyzed tokens: use match or term on title.keyword`,
+		}},
+		{"terms on text without keyword", `{"query": {"terms": {"body": ["a"], "boost": 2}}}`, []string{
+			`1:22 terms on text field "body" only matches single analyzed tokens: use match`,
+		}},
+		{"match on numbers and dates", `{"query": {"bool": {"should": [{"match": {"age": 3}}, {"match_phrase": {"created": "2024"}}]}}}`, []string{
+			`1:43 match on long field "age": use term or range`,
+			`1:73 match_phrase on date field "created": use term or range`,
+		}},
+		{"range on keyword", `{"post_filter": {"range": {"status": {"gte": "10"}}}}`, []string{
+			`1:28 range on keyword field "status" compares strings`,
+		}},
+		{"unknown fields", `{"query": {"bool": {"filter": [{"exists": {"field": "usr"}}, {"term": {"_id": "1"}}, {"multi_match": {"query": "x", "fields": ["title^2", "tittle", "user.*"]}}]}}}`, []string{
+			`1:53 unknown field "usr"`,
+			`1:139 unknown field "tittle"`,
+		}},
+		{"nested", `{"query": {"nested": {"path": "user", "query": {"term": {"comments.author": "ann"}}}}}`, []string{
+			`1:31 nested path "user" is mapped as object, not nested`,
+		}},
+		{"aggregations", `{"aggs": {"t": {"terms": {"field": "title"}, "aggs": {"f": {"filter": {"term": {"title": "x"}}}, "m": {"max": {"field": "agee"}}}}}}`, []string{
+			`1:36 terms aggregation on text field "title" needs fielddata: use title.keyword`,
+			`1:81 term on text field "title"`,
+			`1:121 unknown field "agee"`,
+		}},
+		{"runtime fields", `{"runtime_mappings": {"day": {"type": "keyword", "script": "emit('Mon')"}, "hours": {"type": "long"}}, "query": {"bool": {"filter": [{"term": {"day": "Mon"}}, {"match": {"hours": 3}}, {"exists": {"field": "dya"}}]}}, "aggs": {"d": {"terms": {"field": "day"}}}}`, []string{
+			`1:171 match on long field "hours"`,
+			`1:206 unknown field "dya"`,
+		}},
+		{"flattened", `{"query": {"bool": {"filter": [{"term": {"labels.env": "prod"}}, {"exists": {"field": "labels.team.lead"}}, {"range": {"labels.tier": {"gte": "2"}}}, {"term": {"labelz.env": "x"}}]}}}`, []string{
+			`1:120 range on keyword field "labels.tier" compares strings`,
+			`1:161 unknown field "labelz.env"`,
+		}},
+		{"invalid JSON", `{"query": {"term": {"title": `, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LintQuery(tt.body, lintFields)
+			if len(got) != len(tt.want) {
+				t.Fatalf("LintQuery() = %v, want %d warnings", got, len(tt.want))
+			}
+			for i, w := range got {
+				text := fmt.Sprintf("%d:%d %s", w.Line, w.Col, w.Message)
+				if !strings.HasPrefix(text, tt.want[i]) {
+					t.Errorf("warning %d = %q, want %q", i, text, tt.want[i])
+				}
+			}
+		})
+	}
+
+	if _, ok := lintFields["day"]; ok {
+		t.Error("runtime fields must not be added to the mapping's fields")
+	}
+	if got := LintQuery(`{"query": {"term": {"title": "x"}}}`, nil); got != nil {
+		t.Errorf("without a mapping there is nothing to check, got %v", got)
+	}
+}
+
+func TestWorkbenchQueryWarnings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(140, 30)
+	w.path.SetValue("/books/_search")
+	w.checkIndexChange()
+	w, _ = w.Update(mappingResultMsg{index: "books", fields: []es.FieldType{
+		{Name: "title", Type: "text"}, {Name: "title.keyword", Type: "keyword"}, {Name: "user", Type: "object"},
+	}})
+	if len(w.fieldCache["books"]) != 2 || w.fieldCache["books"][0].Kind != "text" {
+		t.Errorf("completions = %v, want the fields without objects, with their types", w.fieldCache["books"])
+	}
+
+	w.editor.SetContent("{\n  \"query\": {\n    \"term\": {\"title\": \"Go\"}\n  }\n}")
+	lines := strings.Split(w.View(), "\n")
+	if header := ansi.Strip(lines[5]); !strings.Contains(header, `⚠ 3:14 term on text field "title"`) {
+		t.Errorf("body header = %q, want the warning", header)
+	}
+	if !strings.Contains(lines[8], "┃") || strings.Contains(lines[7], "┃") {
+		t.Errorf("only the warning line should be marked:\n%s\n%s", lines[7], lines[8])
+	}
+}
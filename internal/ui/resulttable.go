@@ -0,0 +1,787 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	tableMaxColumnWidth = 40
+	tableMinColumnWidth = 3
+	tableColumnGap      = 2
+	tableResizeStep     = 2
+)
+
+type tableColumn struct {
+	name    string
+	width   int
+	numeric bool // every cell that has a value is a number
+	hidden  bool
+}
+
+// tableSource is one set of rows a response can be shown as: its hits, the
+// buckets of one aggregation or the result of an ES|QL query. Each keeps
+// its own sort, column sizes and cursor.
+type tableSource struct {
+	name     string
+	columns  []tableColumn
+	cells    [][]string
+	order    []int // row indices in display order
+	sortCol  int   // -1 while unsorted
+	sortDesc bool
+	col      int // selected column
+	left     int // first column on screen
+	nav      ListNav
+}
+
+// ResultTable shows a response as rows and columns. A search response can
+// give several sources, its hits and each bucket aggregation, shown one at
+// a time.
+type ResultTable struct {
+	sources []*tableSource
+	current int
+	picking bool
+	pickNav ListNav
+}
+
+// NewResultTable builds the table view of a response: ES|QL and SQL
+// results, search hits, bucket aggregations or any array of objects (such
+// as _cat APIs with format=json, or a filter result).
+func NewResultTable(text string) (*ResultTable, error) {
+	values, err := decodeStream(text)
+	if err != nil {
+		return nil, err
+	}
+	var doc any = values
+	if len(values) == 1 {
+		doc = values[0]
+	}
+	t := &ResultTable{}
+	switch doc := doc.(type) {
+	case orderedObject:
+		t.sources = responseSources(doc)
+	case []any:
+		if src := objectRows("rows", doc); src != nil {
+			t.sources = append(t.sources, src)
+		}
+	}
+	if len(t.sources) == 0 {
+		return nil, fmt.Errorf("no hits, buckets or rows to show as a table")
+	}
+	return t, nil
+}
+
+// decodeStream decodes whitespace-separated JSON values, as in the output
+// of a filter with several results.
+func decodeStream(text string) ([]any, error) {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var values []any
+	for {
+		v, err := decodeOrderedValue(dec)
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
+
+func responseSources(doc orderedObject) []*tableSource {
+	if src := columnarSource(doc); src != nil {
+		return []*tableSource{src}
+	}
+	var sources []*tableSource
+	if items, ok := searchHits(doc); ok {
+		if src := objectRows("hits", items); src != nil {
+			sources = append(sources, src)
+		}
+	}
+	if aggs, ok := doc.get("aggregations"); ok {
+		if aggs, ok := aggs.(orderedObject); ok {
+			sources = append(sources, aggregationSources(aggs)...)
+		}
+	}
+	return sources
+}
+
+// searchHits returns hits.hits of a search response.
+func searchHits(doc orderedObject) ([]any, bool) {
+	hits, _ := doc.get("hits")
+	obj, ok := hits.(orderedObject)
+	if !ok {
+		return nil, false
+	}
+	list, _ := obj.get("hits")
+	items, ok := list.([]any)
+	return items, ok
+}
+
+// columnarSource reads the columns and values of an ES|QL response, or the
+// columns and rows of an SQL one.
+func columnarSource(doc orderedObject) *tableSource {
+	columns, _ := doc.get("columns")
+	colList, ok := columns.([]any)
+	if !ok {
+		return nil
+	}
+	values, ok := doc.get("values")
+	if !ok {
+		values, _ = doc.get("rows")
+	}
+	rows, ok := values.([]any)
+	if !ok {
+		return nil
+	}
+	b := newTableBuilder("ES|QL")
+	for _, c := range colList {
+		col, _ := c.(orderedObject)
+		name, _ := col.get("name")
+		s, ok := name.(string)
+		if !ok {
+			return nil
+		}
+		b.column(s)
+	}
+	for _, r := range rows {
+		vals, _ := r.([]any)
+		row := make([]string, len(b.names))
+		for i, v := range vals {
+			if i < len(row) {
+				row[i] = cellText(v)
+			}
+		}
+		b.rows = append(b.rows, row)
+	}
+	return b.build()
+}
+
+// objectRows makes a row of each object in items. Search hits show their
+// _id and _source fields; _index and _score start hidden.
+func objectRows(name string, items []any) *tableSource {
+	b := newTableBuilder(name)
+	for _, item := range items {
+		obj, ok := item.(orderedObject)
+		if !ok {
+			b.rows = append(b.rows, b.set(nil, "value", cellText(item)))
+			continue
+		}
+		source, isHit := obj.get("_source")
+		if _, hasID := obj.get("_id"); !hasID {
+			isHit = false
+		}
+		if !isHit {
+			b.rows = append(b.rows, b.flatten(nil, "", obj))
+			continue
+		}
+		var row []string
+		for _, key := range []string{"_id", "_index", "_score"} {
+			if v, ok := obj.get(key); ok {
+				row = b.set(row, key, cellText(v))
+			}
+		}
+		row = b.flatten(row, "", source)
+		b.rows = append(b.rows, row)
+	}
+	return b.build("_index", "_score")
+}
+
+// aggregationSources returns a source for each top-level bucket
+// aggregation, and one row with the top-level metrics.
+func aggregationSources(aggs orderedObject) []*tableSource {
+	var sources []*tableSource
+	metrics := newTableBuilder("aggregations")
+	var metricRow []string
+	for _, m := range aggs {
+		agg, ok := m.value.(orderedObject)
+		if !ok {
+			continue
+		}
+		if !hasBuckets(agg) {
+			metricRow = metrics.addMetric(metricRow, m.key, agg)
+			continue
+		}
+		b := newTableBuilder("aggregations." + m.key)
+		b.addBuckets(nil, m.key, agg)
+		if src := b.build(); src != nil {
+			sources = append(sources, src)
+		}
+	}
+	if metricRow != nil {
+		metrics.rows = [][]string{metricRow}
+		sources = append(sources, metrics.build())
+	}
+	return sources
+}
+
+func hasBuckets(agg orderedObject) bool {
+	_, ok := agg.get("buckets")
+	return ok
+}
+
+// aggBuckets returns the keys and buckets of a bucket aggregation. Keyed
+// aggregations return their buckets as an object instead of an array.
+func aggBuckets(agg orderedObject) ([]string, []orderedObject) {
+	var keys []string
+	var buckets []orderedObject
+	v, _ := agg.get("buckets")
+	switch v := v.(type) {
+	case []any:
+		for i, b := range v {
+			bucket, ok := b.(orderedObject)
+			if !ok {
+				continue
+			}
+			key, ok := bucket.get("key_as_string")
+			if !ok {
+				key, ok = bucket.get("key")
+			}
+			if !ok {
+				key = strconv.Itoa(i)
+			}
+			keys = append(keys, cellText(key))
+			buckets = append(buckets, bucket)
+		}
+	case orderedObject:
+		for _, m := range v {
+			if bucket, ok := m.value.(orderedObject); ok {
+				keys = append(keys, m.key)
+				buckets = append(buckets, bucket)
+			}
+		}
+	}
+	return keys, buckets
+}
+
+// tableBuilder collects rows whose columns are only known once every row
+// has been seen.
+type tableBuilder struct {
+	name  string
+	names []string
+	index map[string]int
+	rows  [][]string
+}
+
+func newTableBuilder(name string) *tableBuilder {
+	return &tableBuilder{name: name, index: make(map[string]int)}
+}
+
+func (b *tableBuilder) column(name string) int {
+	i, ok := b.index[name]
+	if !ok {
+		i = len(b.names)
+		b.index[name] = i
+		b.names = append(b.names, name)
+	}
+	return i
+}
+
+func (b *tableBuilder) set(row []string, name, value string) []string {
+	i := b.column(name)
+	for len(row) <= i {
+		row = append(row, "")
+	}
+	row[i] = value
+	return row
+}
+
+// flatten sets a column for each scalar in v, named by its dotted path.
+// Arrays stay whole, as compact JSON.
+func (b *tableBuilder) flatten(row []string, name string, v any) []string {
+	obj, ok := v.(orderedObject)
+	if !ok || (len(obj) == 0 && name != "") {
+		return b.set(row, name, cellText(v))
+	}
+	for _, m := range obj {
+		key := m.key
+		if name != "" {
+			key = name + "." + m.key
+		}
+		row = b.flatten(row, key, m.value)
+	}
+	return row
+}
+
+// addBuckets adds the rows of each bucket of agg, with the bucket key in
+// the column named after the aggregation.
+func (b *tableBuilder) addBuckets(row []string, name string, agg orderedObject) {
+	keys, buckets := aggBuckets(agg)
+	for i, bucket := range buckets {
+		b.addBucket(b.set(slices.Clone(row), name, keys[i]), bucket)
+	}
+}
+
+// addBucket sets the doc count and metrics of a bucket on row, then expands
+// its first bucket sub-aggregation into one row per bucket. Further bucket
+// sub-aggregations are left out, as their rows would not line up.
+func (b *tableBuilder) addBucket(row []string, bucket orderedObject) {
+	var nestedName string
+	var nested orderedObject
+	for _, m := range bucket {
+		if m.key == "key" || m.key == "meta" || strings.HasSuffix(m.key, "_as_string") {
+			continue
+		}
+		sub, ok := m.value.(orderedObject)
+		switch {
+		case !ok:
+			row = b.set(row, m.key, cellText(m.value))
+		case hasBuckets(sub):
+			if nested == nil {
+				nestedName, nested = m.key, sub
+			}
+		default:
+			row = b.addMetric(row, m.key, sub)
+		}
+	}
+	if _, buckets := aggBuckets(nested); len(buckets) > 0 {
+		b.addBuckets(row, nestedName, nested)
+		return
+	}
+	b.rows = append(b.rows, row)
+}
+
+// addMetric sets the result of a metric aggregation on row: one column for
+// single-value metrics, one per statistic for stats or percentiles.
+func (b *tableBuilder) addMetric(row []string, name string, agg orderedObject) []string {
+	if v, ok := agg.get("value_as_string"); ok {
+		return b.set(row, name, cellText(v))
+	}
+	if v, ok := agg.get("value"); ok {
+		return b.set(row, name, cellText(v))
+	}
+	for _, m := range agg {
+		if m.key == "meta" || strings.HasSuffix(m.key, "_as_string") {
+			continue
+		}
+		key := name + "." + m.key
+		if m.key == "values" {
+			key = name
+		}
+		row = b.flatten(row, key, m.value)
+	}
+	return row
+}
+
+// build sizes the columns to their content and hides the named ones. It
+// returns nil when there are no rows.
+func (b *tableBuilder) build(hidden ...string) *tableSource {
+	if len(b.rows) == 0 || len(b.names) == 0 {
+		return nil
+	}
+	s := &tableSource{name: b.name, sortCol: -1, nav: NewCursorNav()}
+	for i, row := range b.rows {
+		for len(row) < len(b.names) {
+			row = append(row, "")
+		}
+		b.rows[i] = row
+		s.order = append(s.order, i)
+	}
+	for i, name := range b.names {
+		col := tableColumn{name: name, width: ansi.StringWidth(name), hidden: slices.Contains(hidden, name)}
+		hasValue, numeric := false, true
+		for _, row := range b.rows {
+			cell := row[i]
+			col.width = max(col.width, ansi.StringWidth(cell))
+			if cell == "" {
+				continue
+			}
+			hasValue = true
+			if _, err := strconv.ParseFloat(cell, 64); err != nil {
+				numeric = false
+			}
+		}
+		col.numeric = hasValue && numeric
+		col.width = min(max(col.width, tableMinColumnWidth), tableMaxColumnWidth)
+		s.columns = append(s.columns, col)
+	}
+	s.cells = b.rows
+	s.col = s.nextVisible(-1, 1)
+	return s
+}
+
+// cellText is the text of a value in a cell. Strings lose their quotes,
+// objects and arrays become compact JSON and null is left empty.
+func cellText(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Map(func(r rune) rune {
+			if r == '\n' || r == '\r' || r == '\t' {
+				return ' '
+			}
+			return r
+		}, SanitizeForTerminal(v))
+	case orderedObject, []any:
+		return compactJSON(v)
+	}
+	return jsonText(v)
+}
+
+func (s *tableSource) nextVisible(from, step int) int {
+	for i := from + step; i >= 0 && i < len(s.columns); i += step {
+		if !s.columns[i].hidden {
+			return i
+		}
+	}
+	return from
+}
+
+// sort puts the rows in the order of the sort column. Empty cells go last
+// both ways; the cursor stays on the same row.
+func (s *tableSource) sort() {
+	selected := -1
+	if len(s.order) > 0 {
+		selected = s.order[min(s.nav.Selected, len(s.order)-1)]
+	}
+	for i := range s.order {
+		s.order[i] = i
+	}
+	if s.sortCol >= 0 {
+		c, numeric := s.sortCol, s.columns[s.sortCol].numeric
+		slices.SortStableFunc(s.order, func(a, b int) int {
+			x, y := s.cells[a][c], s.cells[b][c]
+			if x == "" || y == "" {
+				return strings.Compare(y, x)
+			}
+			var r int
+			if numeric {
+				xf, _ := strconv.ParseFloat(x, 64)
+				yf, _ := strconv.ParseFloat(y, 64)
+				r = cmp.Compare(xf, yf)
+			} else {
+				r = cmp.Or(strings.Compare(strings.ToLower(x), strings.ToLower(y)), strings.Compare(x, y))
+			}
+			if s.sortDesc {
+				r = -r
+			}
+			return r
+		})
+	}
+	if selected >= 0 {
+		s.nav.Selected = slices.Index(s.order, selected)
+	}
+}
+
+// fitFrom returns the visible columns from start that fit in width. The
+// first one is always included, cut short if it has to be.
+func (s *tableSource) fitFrom(start, width int) []int {
+	var cols []int
+	used := 0
+	for i := start; i < len(s.columns); i++ {
+		if s.columns[i].hidden {
+			continue
+		}
+		w := s.columns[i].width
+		if len(cols) > 0 {
+			w += tableColumnGap
+			if used+w > width {
+				break
+			}
+		}
+		cols = append(cols, i)
+		used += w
+	}
+	return cols
+}
+
+// onScreen returns the columns to draw, scrolling sideways so the selected
+// column is one of them.
+func (s *tableSource) onScreen(width int) []int {
+	s.left = min(s.left, s.col)
+	for {
+		cols := s.fitFrom(s.left, width)
+		if len(cols) == 0 || slices.Contains(cols, s.col) || s.left >= s.col {
+			return cols
+		}
+		s.left++
+	}
+}
+
+func (t *ResultTable) src() *tableSource {
+	return t.sources[t.current]
+}
+
+// Picking reports whether the column chooser is open.
+func (t *ResultTable) Picking() bool {
+	return t.picking
+}
+
+// Sort sorts by the selected column, ascending, then descending, then back
+// to the response order.
+func (t *ResultTable) Sort() {
+	s := t.src()
+	switch {
+	case s.sortCol != s.col:
+		s.sortCol, s.sortDesc = s.col, false
+	case !s.sortDesc:
+		s.sortDesc = true
+	default:
+		s.sortCol = -1
+	}
+	s.sort()
+}
+
+// Resize widens or narrows the selected column.
+func (t *ResultTable) Resize(delta int) {
+	col := &t.src().columns[t.src().col]
+	col.width = max(tableMinColumnWidth, col.width+delta)
+}
+
+// NextSource shows the next (or previous) set of rows of the response.
+func (t *ResultTable) NextSource(step int) {
+	t.current = (t.current + step + len(t.sources)) % len(t.sources)
+}
+
+// HandleKey moves through, sorts and resizes the table, or the column
+// chooser while it is open. height is the number of lines View is given.
+func (t *ResultTable) HandleKey(key string, height int) bool {
+	if t.picking {
+		return t.handlePickerKey(key, height)
+	}
+	s := t.src()
+	switch key {
+	case "left", "h":
+		s.col = s.nextVisible(s.col, -1)
+	case "right", "l":
+		s.col = s.nextVisible(s.col, 1)
+	case "s":
+		t.Sort()
+	case "<":
+		t.Resize(-tableResizeStep)
+	case ">":
+		t.Resize(tableResizeStep)
+	case "c":
+		t.picking = true
+		t.pickNav = NewCursorNav()
+		t.pickNav.Selected = s.col
+	case "]":
+		t.NextSource(1)
+	case "[":
+		t.NextSource(-1)
+	default:
+		return s.nav.HandleKey(key, len(s.order), height-1)
+	}
+	return true
+}
+
+// handlePickerKey shows and hides columns. One column always stays.
+func (t *ResultTable) handlePickerKey(key string, height int) bool {
+	s := t.src()
+	switch key {
+	case "space", "x":
+		col := &s.columns[t.pickNav.Selected]
+		if !col.hidden && s.nextVisible(-1, 1) == s.nextVisible(len(s.columns), -1) {
+			return true
+		}
+		col.hidden = !col.hidden
+	case "esc", "enter", "c":
+		t.picking = false
+		if s.columns[s.col].hidden {
+			s.col = s.nextVisible(-1, 1)
+		}
+		s.left = 0
+	default:
+		t.pickNav.HandleKey(key, len(s.columns), height-1)
+	}
+	return true
+}
+
+func (t *ResultTable) HandleWheel(down bool, height int) {
+	if t.picking {
+		t.pickNav.HandleWheel(down, len(t.src().columns), height-1)
+		return
+	}
+	t.src().nav.HandleWheel(down, len(t.src().order), height-1)
+}
+
+// Cell returns the text of the selected cell.
+func (t *ResultTable) Cell() string {
+	s := t.src()
+	if len(s.order) == 0 {
+		return ""
+	}
+	return s.cells[s.order[s.nav.Selected]][s.col]
+}
+
+// Summary describes the shown rows for the line above the table.
+func (t *ResultTable) Summary() string {
+	s := t.src()
+	text := fmt.Sprintf("%s  %d rows", s.name, len(s.cells))
+	if len(s.cells) == 1 {
+		text = s.name + "  1 row"
+	}
+	if len(t.sources) > 1 {
+		text += fmt.Sprintf("  [%d/%d]", t.current+1, len(t.sources))
+	}
+	hidden := 0
+	for _, c := range s.columns {
+		if c.hidden {
+			hidden++
+		}
+	}
+	if hidden > 0 {
+		text += fmt.Sprintf("  %d hidden", hidden)
+	}
+	if s.sortCol >= 0 {
+		dir := "asc"
+		if s.sortDesc {
+			dir = "desc"
+		}
+		text += fmt.Sprintf("  sorted by %s %s", s.columns[s.sortCol].name, dir)
+	}
+	return text
+}
+
+// CSV returns the shown rows with the visible columns, in display order.
+func (t *ResultTable) CSV() string {
+	s := t.src()
+	var cols []int
+	var header []string
+	for i, c := range s.columns {
+		if !c.hidden {
+			cols = append(cols, i)
+			header = append(header, c.name)
+		}
+	}
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write(header)
+	for _, r := range s.order {
+		record := make([]string, len(cols))
+		for i, c := range cols {
+			record[i] = s.cells[r][c]
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// SearchLines returns the text of each row in display order, for the
+// search bar.
+func (t *ResultTable) SearchLines() []string {
+	s := t.src()
+	lines := make([]string, len(s.order))
+	for i, r := range s.order {
+		lines[i] = strings.Join(s.cells[r], "  ")
+	}
+	return lines
+}
+
+// Reveal moves the cursor to a row in display order.
+func (t *ResultTable) Reveal(row, height int) {
+	s := t.src()
+	if row < 0 || row >= len(s.order) {
+		return
+	}
+	s.nav.Selected = row
+	if row < s.nav.Scroll {
+		s.nav.Scroll = row
+	} else if row >= s.nav.Scroll+height-1 {
+		s.nav.Scroll = row - height + 2
+	}
+}
+
+func fitCell(text string, width int, right bool) string {
+	text = ansi.Truncate(text, width, "…")
+	pad := strings.Repeat(" ", width-ansi.StringWidth(text))
+	if right {
+		return pad + text
+	}
+	return text + pad
+}
+
+// View renders the header and the rows that fit in height lines. The
+// cursor row is drawn on the selection background with the selected cell
+// highlighted.
+func (t *ResultTable) View(width, height int) []string {
+	if t.picking {
+		return t.pickerView(width, height)
+	}
+	s := t.src()
+	cols := s.onScreen(width)
+	gap := strings.Repeat(" ", tableColumnGap)
+
+	var header strings.Builder
+	for i, c := range cols {
+		if i > 0 {
+			header.WriteString(gap)
+		}
+		col := s.columns[c]
+		name := col.name
+		if c == s.sortCol && s.sortDesc {
+			name += "▼"
+		} else if c == s.sortCol {
+			name += "▲"
+		}
+		style := lipgloss.NewStyle().Bold(true)
+		if c == s.col {
+			style = style.Foreground(ColorBlue)
+		}
+		header.WriteString(style.Render(fitCell(name, col.width, col.numeric)))
+	}
+	lines := []string{ansi.Truncate(header.String(), width, "…")}
+
+	end := min(s.nav.Scroll+height-1, len(s.order))
+	for r := s.nav.Scroll; r < end; r++ {
+		row := s.cells[s.order[r]]
+		cursor := r == s.nav.Selected
+		var b strings.Builder
+		for i, c := range cols {
+			col := s.columns[c]
+			cell := fitCell(row[c], col.width, col.numeric)
+			if i > 0 {
+				cell = gap + cell
+			}
+			switch {
+			case cursor && c == s.col:
+				b.WriteString(lipgloss.NewStyle().Background(ActiveBg).Foreground(ColorBlue).Bold(true).Render(cell))
+			case cursor:
+				b.WriteString(lipgloss.NewStyle().Background(ActiveBg).Render(cell))
+			default:
+				b.WriteString(cell)
+			}
+		}
+		line := ansi.Truncate(b.String(), width, "…")
+		if cursor {
+			line += lipgloss.NewStyle().Background(ActiveBg).Render(strings.Repeat(" ", max(0, width-ansi.StringWidth(line))))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (t *ResultTable) pickerView(width, height int) []string {
+	s := t.src()
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Columns") +
+		lipgloss.NewStyle().Foreground(ColorGray).Render("  Space show/hide, Enter done")}
+	nav := &t.pickNav
+	if nav.Selected >= nav.Scroll+height-1 {
+		nav.Scroll = nav.Selected - height + 2
+	}
+	end := min(nav.Scroll+height-1, len(s.columns))
+	for i := nav.Scroll; i < end; i++ {
+		box := "[x] "
+		if s.columns[i].hidden {
+			box = "[ ] "
+		}
+		line := ansi.Truncate(box+s.columns[i].name, width, "…")
+		if i == nav.Selected {
+			line = lipgloss.NewStyle().Background(ActiveBg).Width(width).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
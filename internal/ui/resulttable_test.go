@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const esqlResult = `{
+  "columns": [{"name": "host", "type": "keyword"}, {"name": "count", "type": "long"}, {"name": "avg", "type": "double"}],
+  "values": [["web-1", 10, 1.5], ["web-2", 3, null], ["db", 7, 22.25]]
+}`
+
+const searchResult = `{
+  "hits": {
+    "total": {"value": 2},
+    "hits": [
+      {"_index": "logs", "_id": "1", "_score": 1.0, "_source": {"user": {"name": "ann", "age": 31}, "tags": ["a", "b"], "msg": "two\nlines"}},
+      {"_index": "logs", "_id": "2", "_score": 1.0, "_source": {"user": {"name": "bob"}, "extra": true}}
+    ]
+  },
+  "aggregations": {
+    "hosts": {
+      "buckets": [
+        {"key": "web", "doc_count": 5, "status": {"buckets": [
+          {"key": 200, "doc_count": 4, "took": {"value": 12.5}},
+          {"key": 500, "doc_count": 1, "took": {"value": 300}}
+        ]}},
+        {"key": "db", "doc_count": 2, "status": {"buckets": []}}
+      ]
+    },
+    "by_day": {
+      "buckets": [{"key_as_string": "2024-01-01", "key": 1704067200000, "doc_count": 3}]
+    },
+    "total": {"value": 7},
+    "latency": {"values": {"50.0": 3, "99.0": 9}}
+  }
+}`
+
+func TestResultTableSources(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string // summary and CSV of each source
+	}{
+		{"esql", esqlResult, []string{
+			"ES|QL  3 rows", "host,count,avg\nweb-1,10,1.5\nweb-2,3,\ndb,7,22.25\n",
+		}},
+		{"search", searchResult, []string{
+			"hits  2 rows  [1/4]  2 hidden", "_id,user.name,user.age,tags,msg,extra\n1,ann,31,\"[\"\"a\"\",\"\"b\"\"]\",two lines,\n2,bob,,,,true\n",
+			"aggregations.hosts  3 rows  [2/4]", "hosts,doc_count,status,took\nweb,4,200,12.5\nweb,1,500,300\ndb,2,,\n",
+			"aggregations.by_day  1 row  [3/4]", "by_day,doc_count\n2024-01-01,3\n",
+			"aggregations  1 row  [4/4]", "total,latency.50.0,latency.99.0\n7,3,9\n",
+		}},
+		{"cat json", `[{"index": "logs", "docs.count": "12"}, {"index": "metrics", "health": "green"}]`, []string{
+			"rows  2 rows", "index,docs.count,health\nlogs,12,\nmetrics,,green\n",
+		}},
+		{"filter output", "\"a\"\n\"b\"", []string{
+			"rows  2 rows", "value\na\nb\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := NewResultTable(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(table.sources)*2 != len(tt.want) {
+				t.Fatalf("got %d sources, want %d", len(table.sources), len(tt.want)/2)
+			}
+			for i := range table.sources {
+				table.current = i
+				if got := table.Summary(); got != tt.want[2*i] {
+					t.Errorf("source %d summary = %q, want %q", i, got, tt.want[2*i])
+				}
+				if got := table.CSV(); got != tt.want[2*i+1] {
+					t.Errorf("source %d CSV =\n%s\nwant\n%s", i, got, tt.want[2*i+1])
+				}
+			}
+		})
+	}
+
+	for _, text := range []string{`{"acknowledged": true}`, `{"hits": {"hits": []}}`, `not json`} {
+		if _, err := NewResultTable(text); err == nil {
+			t.Errorf("NewResultTable(%q) should fail", text)
+		}
+	}
+}
+
+func TestResultTableKeys(t *testing.T) {
+	table, err := NewResultTable(esqlResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+	column := func() []string {
+		var cells []string
+		for _, line := range strings.Split(strings.TrimSpace(table.CSV()), "\n")[1:] {
+			cells = append(cells, strings.Split(line, ",")[0])
+		}
+		return cells
+	}
+
+	table.HandleKey("right", 10)
+	table.HandleKey("s", 10)
+	if got := strings.Join(column(), " "); got != "web-2 db web-1" || table.Cell() != "10" {
+		t.Errorf("sort by count = %s, cell %q; the cursor should stay on its row", got, table.Cell())
+	}
+	table.HandleKey("s", 10)
+	if got := strings.Join(column(), " "); got != "web-1 db web-2" {
+		t.Errorf("descending sort = %s", got)
+	}
+	table.HandleKey("right", 10)
+	table.HandleKey("s", 10)
+	if got := strings.Join(column(), " "); got != "web-1 db web-2" || !strings.HasSuffix(table.Summary(), "sorted by avg asc") {
+		t.Errorf("empty cells should sort last, got %s (%s)", got, table.Summary())
+	}
+	table.HandleKey("s", 10)
+	table.HandleKey("s", 10)
+	if got := strings.Join(column(), " "); got != "web-1 web-2 db" {
+		t.Errorf("a third s should restore the response order, got %s", got)
+	}
+
+	width := table.src().columns[2].width
+	table.HandleKey(">", 10)
+	table.HandleKey(">", 10)
+	if got := table.src().columns[2].width; got != width+2*tableResizeStep {
+		t.Errorf("width = %d, want %d", got, width+2*tableResizeStep)
+	}
+	for range 20 {
+		table.HandleKey("<", 10)
+	}
+	if got := table.src().columns[2].width; got != tableMinColumnWidth {
+		t.Errorf("width = %d, want the minimum", got)
+	}
+
+	table.HandleKey("c", 10)
+	if !table.Picking() {
+		t.Fatal("c should open the column chooser")
+	}
+	for _, key := range []string{"home", "space", "down", "space", "down", "space", "enter"} {
+		table.HandleKey(key, 10)
+	}
+	if table.Picking() || strings.Split(table.CSV(), "\n")[0] != "avg" {
+		t.Errorf("hiding columns should leave avg, got %q", strings.Split(table.CSV(), "\n")[0])
+	}
+	if table.src().col != 2 {
+		t.Errorf("the selected column should move off hidden ones")
+	}
+
+	lines := table.View(30, 3)
+	if len(lines) != 3 || !strings.Contains(lines[0], "avg") {
+		t.Errorf("view = %q, want a header and two rows", lines)
+	}
+}
+
+func TestResultTableScrollsSideways(t *testing.T) {
+	table, err := NewResultTable(`[{"a": "aaaaaaaaaa", "b": "bbbbbbbbbb", "c": "cccccccccc", "d": "dddddddddd"}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ansi.Strip(table.View(25, 2)[0]); !strings.HasPrefix(got, "a ") || strings.Contains(got, "c") {
+		t.Errorf("header = %q, want the first two columns", got)
+	}
+	for range 3 {
+		table.HandleKey("right", 2)
+	}
+	if got := ansi.Strip(table.View(25, 2)[0]); !strings.HasPrefix(got, "c ") || !strings.Contains(got, "d") {
+		t.Errorf("header = %q, the last column should scroll into view", got)
+	}
+}
+
+func TestWorkbenchTableView(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.toggleMode()
+	w.focus = FocusResponse
+	w = showResult(w, esqlResult)
+	if !w.tableActive() || !strings.Contains(w.View(), "[table]") || !strings.Contains(w.View(), "web-2") {
+		t.Fatal("ES|QL results should open in the table view")
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'c', Text: "c"})
+	if !w.HasActiveInput() {
+		t.Error("the column chooser should hold the keyboard")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'T', Text: "T"})
+	if w.tableActive() || !strings.Contains(w.View(), `"columns"`) {
+		t.Error("T should go back to the JSON")
+	}
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: 't', Text: "t"})
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'T', Text: "T"})
+	if !w.tableActive() || w.treeActive() {
+		t.Error("table and tree views should replace each other")
+	}
+
+	w = showResult(w, `{"acknowledged": true}`)
+	if w.tableActive() || strings.Contains(w.View(), "[table]") {
+		t.Error("a response without rows should be shown as JSON")
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'T', Text: "T"})
+	if !strings.Contains(w.ClipboardMessage(), "Table view needs") {
+		t.Errorf("message = %q", w.ClipboardMessage())
+	}
+}
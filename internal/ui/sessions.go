@@ -0,0 +1,326 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
+)
+
+// sessionMsg carries a message produced by one session's commands back to
+// that session, even if another session is in front by the time it arrives.
+type sessionMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+// openSessionMsg asks the App to add a session for a freshly opened client.
+type openSessionMsg struct {
+	client *es.Client
+	cfg    *config.Config
+}
+
+type session struct {
+	id    int
+	model Model
+}
+
+// App holds one Model per open cluster connection and shows one at a time.
+// Sessions keep loading in the background; ] and [ cycle between them and
+// V compares the front session with the next one.
+type App struct {
+	sessions    []session
+	active      int
+	nextID      int
+	compare     CompareModel
+	showCompare bool
+	modal       *Modal
+	width       int
+	height      int
+}
+
+func NewApp(first Model) App {
+	return App{
+		sessions: []session{{id: 0, model: first}},
+		nextID:   1,
+		compare:  NewCompare(),
+	}
+}
+
+// Clients returns the clients of every open session.
+func (a App) Clients() []*es.Client {
+	clients := make([]*es.Client, 0, len(a.sessions))
+	for _, s := range a.sessions {
+		if s.model.client != nil {
+			clients = append(clients, s.model.client)
+		}
+	}
+	return clients
+}
+
+func (a App) Init() tea.Cmd {
+	s := a.sessions[0]
+	return tagCmd(s.id, s.model.Init())
+}
+
+// sessionScoped marks the messages a session's commands send back to that
+// session. Everything else (quit, clipboard, spinner ticks, huh's own
+// messages) is left alone so the runtime and the front session see it.
+type sessionScoped interface{ sessionScoped() }
+
+func (openSessionMsg) sessionScoped()       {}
+func (browserSearchMsg) sessionScoped()     {}
+func (clusterSelectedMsg) sessionScoped()   {}
+func (clusterSwitchedMsg) sessionScoped()   {}
+func (validateMsg) sessionScoped()          {}
+func (validateTickMsg) sessionScoped()      {}
+func (fetchMappingsMsg) sessionScoped()     {}
+func (fetchSettingsMsg) sessionScoped()     {}
+func (connectedMsg) sessionScoped()         {}
+func (nodesStateMsg) sessionScoped()        {}
+func (clusterSettingsMsg) sessionScoped()   {}
+func (threadPoolsMsg) sessionScoped()       {}
+func (hotThreadsMsg) sessionScoped()        {}
+func (templatesMsg) sessionScoped()         {}
+func (deprecationsMsg) sessionScoped()      {}
+func (tasksMsg) sessionScoped()             {}
+func (pendingTasksMsg) sessionScoped()      {}
+func (taskCancelledMsg) sessionScoped()     {}
+func (mappingsMsg) sessionScoped()          {}
+func (settingsMsg) sessionScoped()          {}
+func (errMsg) sessionScoped()               {}
+func (ModalInitMsg) sessionScoped()         {}
+func (IndexCreatedMsg) sessionScoped()      {}
+func (IndexDeletedMsg) sessionScoped()      {}
+func (IndexOpenedMsg) sessionScoped()       {}
+func (IndexClosedMsg) sessionScoped()       {}
+func (AliasAddedMsg) sessionScoped()        {}
+func (AliasRemovedMsg) sessionScoped()      {}
+func (AllocationExplainMsg) sessionScoped() {}
+func (RecoveryMsg) sessionScoped()          {}
+func (taskCancelRequestMsg) sessionScoped() {}
+func (executeResultMsg) sessionScoped()     {}
+func (taskHandoffMsg) sessionScoped()       {}
+func (mappingResultMsg) sessionScoped()     {}
+func (fieldValuesMsg) sessionScoped()       {}
+
+// cmdListType is the underlying type of tea.BatchMsg and of the message
+// tea.Sequence returns, which bubbletea doesn't export.
+var cmdListType = reflect.TypeFor[[]tea.Cmd]()
+
+// tagCmd wraps the messages cmd produces so they are routed back to the
+// session with the given ID, looking inside batches and sequences.
+func tagCmd(id int, cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := cmd()
+		if _, ok := msg.(sessionScoped); ok {
+			return sessionMsg{id: id, msg: msg}
+		}
+		if v := reflect.ValueOf(msg); v.IsValid() && v.Kind() == reflect.Slice && v.Type().ConvertibleTo(cmdListType) {
+			cmds := v.Convert(cmdListType).Interface().([]tea.Cmd)
+			tagged := make([]tea.Cmd, len(cmds))
+			for i, c := range cmds {
+				tagged[i] = tagCmd(id, c)
+			}
+			return reflect.ValueOf(tagged).Convert(v.Type()).Interface()
+		}
+		return msg
+	}
+}
+
+func (a App) index(id int) int {
+	for i, s := range a.sessions {
+		if s.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a App) updateSession(i int, msg tea.Msg) (App, tea.Cmd) {
+	newM, cmd := a.sessions[i].model.Update(msg)
+	a.sessions[i].model = newM.(Model)
+	return a, tagCmd(a.sessions[i].id, cmd)
+}
+
+func (a App) openSession(from Model, msg openSessionMsg) (App, tea.Cmd) {
+	m := New(msg.client, msg.cfg)
+	m.SetClusterSwitcher(from.switcher.clusters, from.openCluster)
+	newM, _ := m.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	m = newM.(Model)
+
+	id := a.nextID
+	a.nextID++
+	a.sessions = append(a.sessions, session{id: id, model: m})
+	a.active = len(a.sessions) - 1
+	return a, tagCmd(id, m.Init())
+}
+
+func (a App) closeActive() App {
+	if len(a.sessions) < 2 {
+		return a
+	}
+	if client := a.sessions[a.active].model.client; client != nil {
+		client.CloseAuditLog()
+	}
+	a.sessions = append(a.sessions[:a.active], a.sessions[a.active+1:]...)
+	a.active = min(a.active, len(a.sessions)-1)
+	return a
+}
+
+// confirmClose asks before closing the front session.
+func (a App) confirmClose() (App, tea.Cmd) {
+	if len(a.sessions) < 2 {
+		return a, nil
+	}
+	name := "this cluster"
+	if cfg := a.sessions[a.active].model.cfg; cfg != nil && cfg.ClusterName != "" {
+		name = cfg.ClusterName
+	}
+	a.modal = NewCloseSessionModal(name)
+	return a, func() tea.Msg { return ModalInitMsg{} }
+}
+
+func (a App) updateModal(msg tea.Msg) (App, tea.Cmd) {
+	if _, ok := msg.(ModalInitMsg); ok {
+		return a, a.modal.Init()
+	}
+	cmd := a.modal.Update(msg)
+	if a.modal.Cancelled() {
+		a.modal = nil
+		return a, nil
+	}
+	if a.modal.Done() {
+		if a.modal.Confirmed() {
+			a = a.closeActive()
+		}
+		a.modal = nil
+		return a, nil
+	}
+	return a, cmd
+}
+
+// openCompare compares the front session with the one after it, or with
+// the one after the session currently on the right when cycling.
+func (a App) openCompare(right int) (App, tea.Cmd) {
+	left := &a.sessions[a.active]
+	right %= len(a.sessions)
+	if right == a.active {
+		right = (right + 1) % len(a.sessions)
+	}
+	a.showCompare = true
+	a.compare.SetSize(a.width, a.height)
+	return a, a.compare.Open(&left.model, &a.sessions[right].model, left.id, a.sessions[right].id)
+}
+
+func (a App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if a.modal != nil {
+		switch msg.(type) {
+		case sessionMsg, compareLoadedMsg, tea.WindowSizeMsg, spinner.TickMsg:
+		default:
+			return a.updateModal(msg)
+		}
+	}
+
+	switch msg := msg.(type) {
+	case sessionMsg:
+		i := a.index(msg.id)
+		if i < 0 {
+			return a, nil
+		}
+		if open, ok := msg.msg.(openSessionMsg); ok {
+			return a.openSession(a.sessions[i].model, open)
+		}
+		return a.updateSession(i, msg.msg)
+	case compareLoadedMsg:
+		a.compare.setLoaded(msg)
+		return a, nil
+	case tea.WindowSizeMsg:
+		a.width = msg.Width
+		a.height = msg.Height
+		a.compare.SetSize(msg.Width, msg.Height)
+		var cmds []tea.Cmd
+		for i := range a.sessions {
+			var cmd tea.Cmd
+			a, cmd = a.updateSession(i, msg)
+			cmds = append(cmds, cmd)
+		}
+		return a, tea.Batch(cmds...)
+	case spinner.TickMsg:
+		// Every session shares the runtime's ticks; each spinner ignores
+		// ticks that are not its own.
+		var cmds []tea.Cmd
+		for i := range a.sessions {
+			var cmd tea.Cmd
+			a, cmd = a.updateSession(i, msg)
+			cmds = append(cmds, cmd)
+		}
+		return a, tea.Batch(cmds...)
+	case tea.KeyPressMsg:
+		if a.showCompare {
+			switch msg.String() {
+			case "esc", "V", "q":
+				a.showCompare = false
+				return a, nil
+			case "r":
+				return a.openCompare(a.index(a.compare.right.id))
+			case "tab":
+				return a.openCompare((a.index(a.compare.right.id) + 1) % len(a.sessions))
+			}
+			var cmd tea.Cmd
+			a.compare, cmd = a.compare.Update(msg)
+			return a, cmd
+		}
+
+		front := a.sessions[a.active].model
+		if !front.hasActiveInput() && !front.showHelp {
+			switch msg.String() {
+			case "]", "[":
+				if front.switchesTableSource() {
+					break
+				}
+				step := 1
+				if msg.String() == "[" {
+					step = len(a.sessions) - 1
+				}
+				a.active = (a.active + step) % len(a.sessions)
+				return a, nil
+			case "V":
+				if len(a.sessions) > 1 {
+					return a.openCompare(a.active + 1)
+				}
+				return a, nil
+			case "X":
+				return a.confirmClose()
+			}
+		}
+	case tea.MouseWheelMsg:
+		if a.showCompare {
+			var cmd tea.Cmd
+			a.compare, cmd = a.compare.Update(msg)
+			return a, cmd
+		}
+	}
+
+	return a.updateSession(a.active, msg)
+}
+
+func (a App) View() tea.View {
+	front := a.sessions[a.active].model
+	if a.modal != nil {
+		return front.makeView(a.modal.View(a.width, a.height))
+	}
+	if a.showCompare {
+		return front.makeView(a.compare.View())
+	}
+	if len(a.sessions) > 1 {
+		front.sessionLabel = fmt.Sprintf("session %d/%d", a.active+1, len(a.sessions))
+	}
+	return front.View()
+}
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/bubbles/v2/spinner"
+	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
+	"github.com/labtiva/stoptail/internal/estest"
+)
+
+// drainApp runs cmd and everything it leads to, skipping spinner ticks.
+func drainApp(a App, cmd tea.Cmd) App {
+	if cmd == nil {
+		return a
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			a = drainApp(a, c)
+		}
+		return a
+	}
+	if _, ok := msg.(spinner.TickMsg); ok || msg == nil {
+		return a
+	}
+	newA, next := a.Update(msg)
+	return drainApp(newA.(App), next)
+}
+
+func pressApp(a App, keys ...string) App {
+	for _, k := range keys {
+		msg := tea.KeyPressMsg{Code: rune(k[0]), Text: k}
+		switch k {
+		case "enter":
+			msg = tea.KeyPressMsg{Code: tea.KeyEnter}
+		case "tab":
+			msg = tea.KeyPressMsg{Code: tea.KeyTab}
+		case "esc":
+			msg = tea.KeyPressMsg{Code: tea.KeyEscape}
+		}
+		newA, cmd := a.Update(msg)
+		a = drainApp(newA.(App), cmd)
+	}
+	return a
+}
+
+func TestSessionsAndCompare(t *testing.T) {
+	blue := estest.NewServer(t, estest.Fixture{Indices: []estest.Index{
+		{Name: "orders", Docs: []map[string]any{{"n": 1}, {"n": 2}}},
+		{Name: "users"},
+	}})
+	green := estest.NewServer(t, estest.Fixture{Indices: []estest.Index{
+		{Name: "orders", Docs: []map[string]any{{"n": 1}}},
+	}})
+	clusters := &config.ClustersConfig{Clusters: map[string]config.ClusterEntry{
+		"blue":  {URL: blue.URL},
+		"green": {URL: green.URL},
+	}}
+	open := func(resolved *config.ResolvedCluster, _ string) (*es.Client, *config.Config, error) {
+		cfg := &config.Config{Host: resolved.URL, ClusterName: resolved.Name}
+		client, err := es.NewClient(cfg)
+		return client, cfg, err
+	}
+
+	client, cfg, _ := open(&config.ResolvedCluster{Name: "blue", URL: blue.URL}, "")
+	m := New(client, cfg)
+	m.SetClusterSwitcher(clusters, open)
+	a := NewApp(m)
+	newA, _ := a.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	a = drainApp(newA.(App), a.Init())
+
+	// Open green in a second session: C, down to green, n.
+	a = pressApp(a, "C", "j", "n")
+	if len(a.sessions) != 2 || a.active != 1 {
+		t.Fatalf("expected a second active session, got %d sessions (active %d)", len(a.sessions), a.active)
+	}
+	if a.sessions[0].model.cfg.ClusterName != "blue" || a.sessions[1].model.cfg.ClusterName != "green" {
+		t.Fatal("opening a session must not switch the existing one")
+	}
+	for _, s := range a.sessions {
+		if !s.model.connected {
+			t.Errorf("session %s should be connected", s.model.cfg.ClusterName)
+		}
+	}
+	if !strings.Contains(a.View().Content, "session 2/2") {
+		t.Error("header should show the session position")
+	}
+
+	a = pressApp(a, "]")
+	if a.active != 0 {
+		t.Fatalf("] should cycle back to the first session, active = %d", a.active)
+	}
+
+	a = pressApp(a, "V")
+	if !a.showCompare {
+		t.Fatal("V should open the compare view")
+	}
+	view := stripANSI(a.View().Content)
+	for _, want := range []string{"blue ↔ green", "missing on right", "-1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("compare view should contain %q", want)
+		}
+	}
+
+	a = pump(a, updateApp, tea.KeyPressMsg{Code: tea.KeyEscape})
+	a = pump(a, updateApp, tea.KeyPressMsg{Code: 'X', Text: "X"})
+	if a.modal == nil || !strings.Contains(stripANSI(a.View().Content), "Close the session on 'blue'?") {
+		t.Fatal("X should ask before closing the front session")
+	}
+	a = pump(a, updateApp, tea.KeyPressMsg{Code: 'n', Text: "n"})
+	if a.modal != nil || len(a.sessions) != 2 {
+		t.Fatal("declining should keep the session open")
+	}
+	a = pump(a, updateApp, tea.KeyPressMsg{Code: 'X', Text: "X"})
+	a = pump(a, updateApp, tea.KeyPressMsg{Code: 'y', Text: "y"})
+	if a.showCompare || len(a.sessions) != 1 || a.sessions[0].model.cfg.ClusterName != "green" {
+		t.Fatal("confirming should close the front session")
+	}
+	a = pressApp(a, "X")
+	if len(a.sessions) != 1 || a.modal != nil {
+		t.Fatal("the last session cannot be closed")
+	}
+}
+
+func updateApp(a App, msg tea.Msg) (App, tea.Cmd) {
+	newA, cmd := a.Update(msg)
+	return newA.(App), cmd
+}
+
+func TestTagCmdLeavesRuntimeMessagesAlone(t *testing.T) {
+	if msg := tagCmd(3, tea.Quit)(); msg != tea.Quit() {
+		t.Errorf("tea.Quit should pass through untagged, got %T", msg)
+	}
+	msg := tagCmd(3, func() tea.Msg { return errMsg{} })()
+	if tagged, ok := msg.(sessionMsg); !ok || tagged.id != 3 {
+		t.Errorf("ui messages should be tagged with their session, got %#v", msg)
+	}
+	msg = tagCmd(3, func() tea.Msg { return &taskHandoffMsg{} })()
+	if tagged, ok := msg.(sessionMsg); !ok || tagged.id != 3 {
+		t.Errorf("pointer messages should be tagged too, got %#v", msg)
+	}
+}
+
+func TestBatchesAndSequencesReachBackgroundSessions(t *testing.T) {
+	a := NewApp(New(nil, &config.Config{ClusterName: "blue"}))
+	a.sessions = append(a.sessions, session{id: 1, model: New(nil, &config.Config{ClusterName: "green"})})
+	a.active = 1
+
+	fail := func(text string) tea.Cmd {
+		return func() tea.Msg { return errMsg{err: errors.New(text)} }
+	}
+	tests := []struct {
+		name string
+		cmd  tea.Cmd
+	}{
+		{"batch", tea.Batch(fail("batch"), nil, fail("batch"))},
+		{"sequence", tea.Sequence(fail("sequence"), fail("sequence"))},
+		{"sequence in a batch", tea.Batch(tea.Sequence(fail("sequence in a batch")), fail("sequence in a batch"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a
+			for _, msg := range runQuick(tagCmd(a.sessions[0].id, tt.cmd)) {
+				got = pump(got, updateApp, msg)
+			}
+			if err := got.sessions[0].model.err; err == nil || err.Error() != tt.name {
+				t.Errorf("the background session should get the error, got %v", err)
+			}
+			if err := got.sessions[1].model.err; err != nil {
+				t.Errorf("the front session should not, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTableSourceKeysReachTheTable(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := estest.NewServer(t, estest.Fixture{})
+	cfg := &config.Config{Host: server.URL}
+	client, _ := es.NewClient(cfg)
+	a := NewApp(New(client, cfg))
+	newA, _ := a.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	a = drainApp(newA.(App), a.Init())
+	a, cmd := a.openSession(a.sessions[0].model, openSessionMsg{client: client, cfg: cfg})
+	a = drainApp(a, cmd)
+
+	m := a.sessions[1].model
+	m.activeTab = TabWorkbench
+	m.workbench.focus = FocusResponse
+	m.workbench = showResult(m.workbench, searchResult)
+	m.workbench, _ = m.workbench.Update(tea.KeyPressMsg{Code: 'T', Text: "T"})
+	a.sessions[1].model = m
+
+	a = pressApp(a, "]", "]")
+	if a.active != 1 || a.sessions[1].model.workbench.table().current != 2 {
+		t.Fatalf("] in the table should show the next rows, active session %d, table %d", a.active, a.sessions[1].model.workbench.table().current)
+	}
+	a = pressApp(a, "[")
+	if a.active != 1 || a.sessions[1].model.workbench.table().current != 1 {
+		t.Fatalf("[ in the table should show the previous rows, active session %d, table %d", a.active, a.sessions[1].model.workbench.table().current)
+	}
+
+	a = pressApp(a, "T", "]")
+	if a.active != 0 {
+		t.Error("] outside the table should still cycle sessions")
+	}
+}
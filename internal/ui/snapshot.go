@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SnapshotFormats are the formats RenderSnapshot accepts.
+var SnapshotFormats = []string{"ansi", "plain", "html", "svg"}
+
+// RenderSnapshot converts a rendered view to the given format: the ANSI
+// output unchanged, plain text without escape codes, or a standalone HTML
+// page or SVG image that keeps the terminal colors.
+func RenderSnapshot(view, format string) (string, error) {
+	switch format {
+	case "", "ansi":
+		return view, nil
+	case "plain":
+		return ansi.Strip(view), nil
+	case "html":
+		return snapshotHTML(parseANSILines(view)), nil
+	case "svg":
+		return snapshotSVG(parseANSILines(view)), nil
+	}
+	return "", fmt.Errorf("unknown render format %q (use: %s)", format, strings.Join(SnapshotFormats, ", "))
+}
+
+// cellStyle is the subset of SGR attributes lipgloss emits. Colors are CSS
+// hex strings; empty means the terminal default.
+type cellStyle struct {
+	fg, bg    string
+	bold      bool
+	faint     bool
+	italic    bool
+	underline bool
+	strike    bool
+	reverse   bool
+}
+
+type styledSpan struct {
+	text  string
+	style cellStyle
+}
+
+// parseANSILines splits s into lines of spans that share one style. Only
+// SGR sequences are interpreted; other escape sequences are dropped.
+func parseANSILines(s string) [][]styledSpan {
+	var (
+		lines [][]styledSpan
+		line  []styledSpan
+		text  strings.Builder
+		style cellStyle
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			line = append(line, styledSpan{text: text.String(), style: style})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\n':
+			flush()
+			lines = append(lines, line)
+			line = nil
+		case c == '\r':
+		case c == 0x1b && i+1 < len(s) && s[i+1] == '[':
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			if j < len(s) && s[j] == 'm' {
+				flush()
+				style = applySGR(style, s[i+2:j])
+			}
+			i = j
+		case c == 0x1b && i+1 < len(s) && s[i+1] == ']':
+			// OSC (hyperlinks, titles) runs until BEL or ST.
+			j := i + 2
+			for j < len(s) && s[j] != 0x07 && !(s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\') {
+				j++
+			}
+			if j < len(s) && s[j] == 0x1b {
+				j++
+			}
+			i = j
+		case c == 0x1b:
+			i++
+		default:
+			text.WriteByte(c)
+		}
+	}
+	flush()
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func applySGR(st cellStyle, params string) cellStyle {
+	if params == "" {
+		return cellStyle{}
+	}
+	parts := strings.Split(params, ";")
+	for i := 0; i < len(parts); i++ {
+		// Colon sub-parameters (4:3 curly underline, 38:2::r:g:b) are
+		// normalized to their semicolon form.
+		sub := strings.Split(parts[i], ":")
+		n, _ := strconv.Atoi(sub[0])
+		switch {
+		case n == 0:
+			st = cellStyle{}
+		case n == 1:
+			st.bold = true
+		case n == 2:
+			st.faint = true
+		case n == 3:
+			st.italic = true
+		case n == 4:
+			st.underline = len(sub) == 1 || sub[1] != "0"
+		case n == 7:
+			st.reverse = true
+		case n == 9:
+			st.strike = true
+		case n == 22:
+			st.bold, st.faint = false, false
+		case n == 23:
+			st.italic = false
+		case n == 24:
+			st.underline = false
+		case n == 27:
+			st.reverse = false
+		case n == 29:
+			st.strike = false
+		case n >= 30 && n <= 37:
+			st.fg = indexedHex(n - 30)
+		case n >= 90 && n <= 97:
+			st.fg = indexedHex(n - 90 + 8)
+		case n == 39:
+			st.fg = ""
+		case n >= 40 && n <= 47:
+			st.bg = indexedHex(n - 40)
+		case n >= 100 && n <= 107:
+			st.bg = indexedHex(n - 100 + 8)
+		case n == 49:
+			st.bg = ""
+		case n == 38 || n == 48 || n == 58:
+			var args []string
+			if len(sub) > 1 {
+				args = sub[1:]
+				if len(args) == 5 && args[0] == "2" {
+					args = append(args[:1], args[2:]...) // drop the color space ID
+				}
+			} else {
+				args = parts[i+1:]
+			}
+			hex, used := extendedColor(args)
+			if len(sub) == 1 {
+				i += used
+			}
+			switch n {
+			case 38:
+				st.fg = hex
+			case 48:
+				st.bg = hex
+			}
+		}
+	}
+	return st
+}
+
+// extendedColor parses "5;n" or "2;r;g;b" and returns the color and the
+// number of parameters consumed.
+func extendedColor(args []string) (string, int) {
+	if len(args) >= 2 && args[0] == "5" {
+		n, _ := strconv.Atoi(args[1])
+		return indexedHex(n), 2
+	}
+	if len(args) >= 4 && args[0] == "2" {
+		r, _ := strconv.Atoi(args[1])
+		g, _ := strconv.Atoi(args[2])
+		b, _ := strconv.Atoi(args[3])
+		return fmt.Sprintf("#%02x%02x%02x", r&0xff, g&0xff, b&0xff), 4
+	}
+	return "", len(args)
+}
+
+func indexedHex(n int) string {
+	return colorHex(ansi.IndexedColor(uint8(n)))
+}
+
+func colorHex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+func snapshotColors() (fg, bg string) {
+	if ThemeDark {
+		return colorHex(ColorWhite), "#111827"
+	}
+	return colorHex(ColorWhite), "#ffffff"
+}
+
+// resolved returns the colors a span is actually drawn with, applying
+// reverse video against the page defaults.
+func (st cellStyle) resolved(defaultFg, defaultBg string) (fg, bg string) {
+	fg, bg = st.fg, st.bg
+	if fg == "" {
+		fg = defaultFg
+	}
+	if st.reverse {
+		fg, bg = bg, fg
+		if fg == "" {
+			fg = defaultBg
+		}
+	}
+	return fg, bg
+}
+
+func snapshotHTML(lines [][]styledSpan) string {
+	defaultFg, defaultBg := snapshotColors()
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>stoptail</title>\n</head>\n<body style=\"margin:0\">\n")
+	fmt.Fprintf(&b, "<pre style=\"margin:0;padding:12px;background:%s;color:%s;font-family:Menlo,Consolas,'DejaVu Sans Mono',monospace;font-size:13px;line-height:1.25\">", defaultBg, defaultFg)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, span := range line {
+			css := spanCSS(span.style, defaultFg, defaultBg)
+			if css == "" {
+				b.WriteString(html.EscapeString(span.text))
+				continue
+			}
+			fmt.Fprintf(&b, "<span style=\"%s\">%s</span>", css, html.EscapeString(span.text))
+		}
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	return b.String()
+}
+
+func spanCSS(st cellStyle, defaultFg, defaultBg string) string {
+	fg, bg := st.resolved(defaultFg, defaultBg)
+	var css []string
+	if fg != defaultFg {
+		css = append(css, "color:"+fg)
+	}
+	if bg != "" {
+		css = append(css, "background:"+bg)
+	}
+	if st.bold {
+		css = append(css, "font-weight:bold")
+	}
+	if st.faint {
+		css = append(css, "opacity:0.6")
+	}
+	if st.italic {
+		css = append(css, "font-style:italic")
+	}
+	if deco := textDecoration(st); deco != "" {
+		css = append(css, "text-decoration:"+deco)
+	}
+	return strings.Join(css, ";")
+}
+
+func textDecoration(st cellStyle) string {
+	var deco []string
+	if st.underline {
+		deco = append(deco, "underline")
+	}
+	if st.strike {
+		deco = append(deco, "line-through")
+	}
+	return strings.Join(deco, " ")
+}
+
+const (
+	svgCellWidth  = 8.4
+	svgLineHeight = 17.0
+	svgFontSize   = 14
+	svgPadding    = 12.0
+)
+
+// snapshotSVG lays every span out on a fixed cell grid so box-drawing
+// characters and the shard grid line up regardless of the viewer's font.
+func snapshotSVG(lines [][]styledSpan) string {
+	defaultFg, defaultBg := snapshotColors()
+
+	cols := 0
+	for _, line := range lines {
+		w := 0
+		for _, span := range line {
+			w += ansi.StringWidth(span.text)
+		}
+		cols = max(cols, w)
+	}
+	width := float64(cols)*svgCellWidth + 2*svgPadding
+	height := float64(len(lines))*svgLineHeight + 2*svgPadding
+
+	var bgRects, texts strings.Builder
+	for row, line := range lines {
+		y := svgPadding + float64(row)*svgLineHeight
+		col := 0
+		for _, span := range line {
+			w := ansi.StringWidth(span.text)
+			x := svgPadding + float64(col)*svgCellWidth
+			col += w
+			fg, bg := span.style.resolved(defaultFg, defaultBg)
+			if bg != "" {
+				fmt.Fprintf(&bgRects, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n",
+					x, y, float64(w)*svgCellWidth, svgLineHeight, bg)
+			}
+			if strings.TrimSpace(span.text) == "" {
+				continue
+			}
+			attrs := fmt.Sprintf(" fill=\"%s\"", fg)
+			if span.style.bold {
+				attrs += " font-weight=\"bold\""
+			}
+			if span.style.faint {
+				attrs += " opacity=\"0.6\""
+			}
+			if span.style.italic {
+				attrs += " font-style=\"italic\""
+			}
+			if deco := textDecoration(span.style); deco != "" {
+				attrs += fmt.Sprintf(" text-decoration=\"%s\"", deco)
+			}
+			fmt.Fprintf(&texts, "<text x=\"%.1f\" y=\"%.1f\" textLength=\"%.1f\" lengthAdjust=\"spacingAndGlyphs\"%s>%s</text>\n",
+				x, y+svgLineHeight*0.78, float64(w)*svgCellWidth, attrs, html.EscapeString(span.text))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\">\n", width, height, width, height)
+	fmt.Fprintf(&b, "<rect width=\"100%%\" height=\"100%%\" fill=\"%s\"/>\n", defaultBg)
+	b.WriteString(bgRects.String())
+	fmt.Fprintf(&b, "<g font-family=\"Menlo, Consolas, 'DejaVu Sans Mono', monospace\" font-size=\"%d\" xml:space=\"preserve\">\n", svgFontSize)
+	b.WriteString(texts.String())
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"charm.land/lipgloss/v2"
+)
+
+func TestApplySGR(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   cellStyle
+		params string
+		want   cellStyle
+	}{
+		{"reset", cellStyle{italic: true, fg: "#ffffff"}, "", cellStyle{}},
+		{"bold off keeps color", cellStyle{bold: true, fg: "#ffffff"}, "22", cellStyle{fg: "#ffffff"}},
+		{"bold basic fg", cellStyle{}, "1;31", cellStyle{bold: true, fg: "#800000"}},
+		{"bright bg", cellStyle{}, "102", cellStyle{bg: "#00ff00"}},
+		{"truecolor", cellStyle{}, "38;2;34;197;94;48;2;31;41;55", cellStyle{fg: "#22c55e", bg: "#1f2937"}},
+		{"256 color", cellStyle{}, "38;5;196", cellStyle{fg: "#ff0000"}},
+		{"colon truecolor", cellStyle{}, "38:2::239:68:68", cellStyle{fg: "#ef4444"}},
+		{"curly underline", cellStyle{}, "4:3", cellStyle{underline: true}},
+		{"underline off", cellStyle{}, "4:0", cellStyle{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applySGR(tt.from, tt.params); got != tt.want {
+				t.Errorf("applySGR(%q) = %+v, want %+v", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseANSILines(t *testing.T) {
+	view := "\x1b[1;38;2;34;197;94mgreen\x1b[m plain\n\x1b]8;;http://x\x07link\x1b]8;;\x07"
+	lines := parseANSILines(view)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	want := []styledSpan{{text: "green", style: cellStyle{bold: true, fg: "#22c55e"}}, {text: " plain"}}
+	if len(lines[0]) != 2 || lines[0][0] != want[0] || lines[0][1] != want[1] {
+		t.Errorf("line 0 = %+v, want %+v", lines[0], want)
+	}
+	if len(lines[1]) != 1 || lines[1][0].text != "link" {
+		t.Errorf("OSC sequences should be dropped, got %+v", lines[1])
+	}
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	view := lipgloss.NewStyle().Foreground(lipgloss.Color("#22c55e")).Background(lipgloss.Color("#1f2937")).Render("■ <p>") + "\nnext"
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"ansi", []string{"\x1b["}},
+		{"plain", []string{"■ <p>\nnext"}},
+		{"html", []string{"<pre", "color:#22c55e", "background:#1f2937", "&lt;p&gt;"}},
+		{"svg", []string{"<svg", `fill="#22c55e"`, `fill="#1f2937"`, "&lt;p&gt;", "next"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := RenderSnapshot(view, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("output should contain %q:\n%s", want, got)
+				}
+			}
+			if tt.format != "ansi" && strings.Contains(got, "\x1b") {
+				t.Error("output should not contain escape codes")
+			}
+		})
+	}
+
+	if _, err := RenderSnapshot(view, "pdf"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -19,6 +19,9 @@ type TasksModel struct {
 	confirming   string
 	search       SearchBar
 	showingModal bool
+	readOnly     bool
+	notice       string
+	selectTaskID string
 }
 
 func NewTasks() TasksModel {
@@ -35,6 +38,21 @@ func (m *TasksModel) SetTasks(tasks []es.TaskInfo) {
 	if m.nav.Selected >= len(tasks) {
 		m.nav.Selected = max(0, len(tasks)-1)
 	}
+	if m.selectTaskID != "" {
+		for i, t := range tasks {
+			if t.ID == m.selectTaskID {
+				m.nav.Selected = i
+				m.nav.Scroll = max(0, i-5)
+				break
+			}
+		}
+		m.selectTaskID = ""
+	}
+}
+
+// SelectWhenLoaded selects the task with the given ID on the next refresh.
+func (m *TasksModel) SelectWhenLoaded(taskID string) {
+	m.selectTaskID = taskID
 }
 
 func (m *TasksModel) SetPendingTasks(tasks []es.PendingTask) {
@@ -53,6 +71,10 @@ func (m TasksModel) SelectedTaskID() string {
 	return ""
 }
 
+func (m *TasksModel) SetReadOnly(readOnly bool) {
+	m.readOnly = readOnly
+}
+
 func (m *TasksModel) ClearConfirming() {
 	m.confirming = ""
 }
@@ -60,6 +82,7 @@ func (m *TasksModel) ClearConfirming() {
 func (m TasksModel) Update(msg tea.Msg) (TasksModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyPressMsg:
+		m.notice = ""
 		if m.showingModal {
 			if msg.String() == "esc" || msg.String() == "enter" || msg.String() == "q" {
 				m.showingModal = false
@@ -109,6 +132,10 @@ func (m TasksModel) Update(msg tea.Msg) (TasksModel, tea.Cmd) {
 			return m, nil
 		case "c":
 			if m.nav.Selected >= 0 && m.nav.Selected < len(m.tasks) && m.tasks[m.nav.Selected].Cancellable {
+				if m.readOnly {
+					m.notice = "Read-only mode: cancelling tasks is disabled"
+					return m, nil
+				}
 				m.confirming = m.tasks[m.nav.Selected].ID
 			}
 		case "ctrl+f":
@@ -227,6 +254,9 @@ func (m TasksModel) View() string {
 	if m.confirming != "" {
 		content += "\n\n" + lipgloss.NewStyle().Foreground(ColorYellow).Render("Cancel this task? Press 'y' to confirm, 'n' or Esc to abort")
 	}
+	if m.notice != "" {
+		content += "\n\n" + lipgloss.NewStyle().Foreground(ColorRed).Render(m.notice)
+	}
 
 	sections = append(sections, content)
 
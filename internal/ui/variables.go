@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// placeholderPattern matches {{name}}, allowing spaces inside the braces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// nowPattern matches the date built-ins: now, now-1d, now+2h, optionally
+// followed by a Go time layout after a |.
+var nowPattern = regexp.MustCompile(`^now(?:([+-])(\d+)([smhdwMy]))?(?:\|(.+))?$`)
+
+// Variables fills the {{name}} placeholders of workbench requests. Values
+// come from the cluster's variables: map in config.yaml, which wins over
+// the built-ins now, now-1d (or any now±N with s, m, h, d, w, M or y) and
+// selected_index, the index selected on the Overview tab. Any other
+// {{...}} is left as written: Elasticsearch uses the same braces for
+// mustache in search templates, stored scripts, ingest pipelines and
+// watches.
+type Variables struct {
+	Values        map[string]string
+	SelectedIndex string
+	Now           time.Time
+}
+
+func hasPlaceholders(text string) bool {
+	return placeholderPattern.MatchString(text)
+}
+
+// Expand replaces the placeholders in text that name a variable or a
+// built-in and leaves the rest as written. It fails when a built-in has
+// no value, so a request never goes out half filled in.
+func (v Variables) Expand(text string) (string, error) {
+	var firstErr error
+	expanded := placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		value, ok, err := v.lookup(placeholderPattern.FindStringSubmatch(match)[1])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if !ok {
+			return match
+		}
+		return value
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return expanded, nil
+}
+
+// ExpandRequest fills in the path and body of req.
+func (v Variables) ExpandRequest(req ConsoleRequest) (ConsoleRequest, error) {
+	path, err := v.Expand(req.Path)
+	if err != nil {
+		return req, err
+	}
+	body, err := v.Expand(req.Body)
+	if err != nil {
+		return req, err
+	}
+	req.Path, req.Body = path, body
+	return req, nil
+}
+
+// lookup returns the value of name, with ok false for names that are
+// neither variables nor built-ins.
+func (v Variables) lookup(name string) (value string, ok bool, err error) {
+	if value, ok := v.Values[name]; ok {
+		return value, true, nil
+	}
+	if name == "selected_index" {
+		if v.SelectedIndex == "" {
+			return "", true, fmt.Errorf("{{selected_index}} needs an index selected on the Overview tab")
+		}
+		return v.SelectedIndex, true, nil
+	}
+	if m := nowPattern.FindStringSubmatch(name); m != nil {
+		return v.now(m[1], m[2], m[3], m[4]), true, nil
+	}
+	return "", false, nil
+}
+
+// now applies date math like -1d to the current time and formats it with
+// layout, RFC 3339 in UTC by default.
+func (v Variables) now(sign, amount, unit, layout string) string {
+	t := v.Now.UTC()
+	if sign != "" {
+		n, _ := strconv.Atoi(amount)
+		if sign == "-" {
+			n = -n
+		}
+		switch unit {
+		case "s":
+			t = t.Add(time.Duration(n) * time.Second)
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "M":
+			t = t.AddDate(0, n, 0)
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		}
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return t.Format(layout)
+}
+
+// maskPlaceholders blanks out placeholders without moving anything, so the
+// body can be checked as JSON with error positions still matching the
+// editor: {{size}} becomes a 0 padded with spaces.
+func maskPlaceholders(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return "0" + strings.Repeat(" ", len(match)-1)
+	})
+}
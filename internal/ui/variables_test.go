@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestVariablesExpand(t *testing.T) {
+	v := Variables{
+		Values:        map[string]string{"prefix": "logs-prod", "now": "pinned"},
+		SelectedIndex: "orders",
+		Now:           time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr string
+	}{
+		{"no placeholders", `{"query": {"match_all": {}}}`, `{"query": {"match_all": {}}}`, ""},
+		{"config value", "/{{prefix}}-*/_search", "/logs-prod-*/_search", ""},
+		{"spaces inside braces", "/{{ prefix }}/_count", "/logs-prod/_count", ""},
+		{"selected index", "/{{selected_index}}/_mapping", "/orders/_mapping", ""},
+		{"config wins over built-ins", "{{now}}", "pinned", ""},
+		{"date math", `{"gte": "{{now-1d}}"}`, `{"gte": "2026-02-28T10:30:00Z"}`, ""},
+		{"months", "{{now-1M}}", "2026-02-01T10:30:00Z", ""},
+		{"hours ahead", "{{now+2h}}", "2026-03-01T12:30:00Z", ""},
+		{"layout", "/{{prefix}}-{{now-1d|2006.01.02}}/_search", "/logs-prod-2026.02.28/_search", ""},
+		{"unknown left as written", "/{{tenant}}/_search", "/{{tenant}}/_search", ""},
+		{"bad date math left as written", "{{now-1q}}", "{{now-1q}}", ""},
+		{"empty left as written", "{{}}", "{{}}", ""},
+		{"ingest mustache", `{"set": {"field": "received", "value": "{{_ingest.timestamp}}"}}`, `{"set": {"field": "received", "value": "{{_ingest.timestamp}}"}}`, ""},
+		{"search template", `{"source": {"query": {"match": {"{{field}}": "{{value}}"}}, "size": "{{size}}"}, "params": {"field": "msg"}}`, `{"source": {"query": {"match": {"{{field}}": "{{value}}"}}, "size": "{{size}}"}, "params": {"field": "msg"}}`, ""},
+		{"mustache next to a variable", `{"index": "{{prefix}}", "to": "{{ctx.payload.hits.total}}"}`, `{"index": "logs-prod", "to": "{{ctx.payload.hits.total}}"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Expand(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Expand(%q) error = %v, want containing %q", tt.text, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Expand(%q) = %q, %v, want %q", tt.text, got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Variables{}).Expand("/{{selected_index}}/_search"); err == nil || !strings.Contains(err.Error(), "Overview") {
+		t.Errorf("selected_index without a selection: error = %v", err)
+	}
+}
+
+func TestMaskPlaceholders(t *testing.T) {
+	body := `{"size": {{size}}, "query": {"term": {"user": "{{user}}"}}}`
+	masked := maskPlaceholders(body)
+	if len(masked) != len(body) || hasPlaceholders(masked) {
+		t.Errorf("maskPlaceholders() = %q, want the same length without placeholders", masked)
+	}
+}
+
+func TestWorkbenchVariables(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.SetVariables(map[string]string{"prefix": "logs-prod"})
+	w.path.SetValue("/{{prefix}}-*/_search")
+	w.editor.SetContent(`{"size": {{size}}, "query": {"term": {"index": "{{selected_index}}"}}}`)
+
+	if got := ansi.Strip(w.View()); !strings.Contains(got, "✗ {{selected_index}} needs an index selected") {
+		t.Errorf("the preview should say why the request can't be sent, got\n%s", got)
+	}
+	if _, _, msg := w.jsonError(); msg != "" {
+		t.Errorf("placeholders should not count as JSON errors, got %q", msg)
+	}
+	msg := w.execute(context.Background(), 1, w.currentRequest())()
+	if result := msg.(executeResultMsg).result; result.Error == nil || !strings.Contains(result.Error.Error(), "{{selected_index}}") {
+		t.Errorf("execute() should fail before sending, got %+v", result)
+	}
+
+	w.SetSelectedIndex("orders")
+	w.SetVariables(map[string]string{"prefix": "logs-prod", "size": "5"})
+	if got := ansi.Strip(w.View()); !strings.Contains(got, "→ GET /logs-prod-*/_search") {
+		t.Errorf("the preview should show the expanded path, got\n%s", got)
+	}
+	w, _ = w.Update(tea.KeyPressMsg{Code: 'v', Mod: tea.ModAlt})
+	if !strings.Contains(w.responseRawText, `"size": 5`) || !strings.Contains(w.responseRawText, `"index": "orders"`) {
+		t.Errorf("Alt+V should show the expanded body, got\n%s", w.responseRawText)
+	}
+	if w.currentRequest().Path != "/{{prefix}}-*/_search" {
+		t.Error("the path should keep its placeholders")
+	}
+
+	w.path.SetValue("/_search")
+	w.editor.SetContent("{}")
+	if strings.Contains(ansi.Strip(w.View()), "→") {
+		t.Error("requests without placeholders should have no preview")
+	}
+}
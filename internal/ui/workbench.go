@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma/v2/quick"
 	"github.com/charmbracelet/x/ansi"
@@ -34,8 +35,16 @@ type QueryMode int
 const (
 	ModeREST QueryMode = iota
 	ModeESSQL
+	ModeConsole
 )
 
+const defaultConsoleContent = `# One request per block, as in Kibana Dev Tools.
+# Ctrl+R runs the request under the cursor, Alt+R runs them all.
+GET /_cluster/health
+
+GET /_cat/indices?v
+`
+
 var methods = []string{"GET", "POST", "PUT", "DELETE", "HEAD"}
 
 var bracketPairs = map[string]string{
@@ -53,6 +62,12 @@ type WorkbenchModel struct {
 	responseRawText    string
 	responseNav        ListNav
 	responseLines      []string
+	responseTree       *JSONTree
+	treeMode           bool
+	responseTable      *ResultTable
+	tableMode          bool
+	responseProfile    *SearchProfile
+	profileMode        bool
 	statusCode         int
 	duration           string
 	focus              WorkbenchFocus
@@ -64,9 +79,20 @@ type WorkbenchModel struct {
 	historyIdx         int
 	spinner            spinner.Model
 	search             SearchBar
+	filter             ResponseFilter
+	filterFor          string // filter result that filterText and filterTree show
+	filterText         string
+	filterTree         *JSONTree
+	filterTable        *ResultTable
 	completion         CompletionState
+	pathCompletion     CompletionState
+	indexNames         []CompletionItem
 	fieldCache         map[string][]CompletionItem
+	fieldTypes         map[string]map[string]string
+	valueCache         map[string]map[string][]CompletionItem // index, field; nil while fetching
 	lastIndex          string
+	variables          map[string]string
+	selectedIndex      string
 	clipboard          Clipboard
 	bookmarkUI         BookmarkUI
 	bookmarks          *storage.Bookmarks
@@ -74,15 +100,44 @@ type WorkbenchModel struct {
 	dslContent         string
 	dslPath            string
 	esqlContent        string
+	consoleContent     string
+	consoleFile        string
+	exportFile         string
+	modal              *Modal
+	notice             string
+	cancelExec         context.CancelFunc
+	execCtx            context.Context
+	execStart          time.Time
+	execSeq            int
+	pending            []ConsoleRequest
+	runAll             bool
+	runDone            int
+	runFailed          int
+	runTotal           time.Duration
 }
 
 type executeResultMsg struct {
-	result es.RequestResult
+	result  es.RequestResult
+	request ConsoleRequest
+	seq     int
+}
+
+// taskHandoffMsg asks the main model to follow a task started with
+// wait_for_completion=false on the Tasks tab.
+type taskHandoffMsg struct {
+	taskID string
 }
 
 type mappingResultMsg struct {
 	index  string
-	fields []string
+	fields []es.FieldType
+}
+
+type fieldValuesMsg struct {
+	index  string
+	field  string
+	values []es.FieldValue
+	err    error
 }
 
 func NewWorkbench() WorkbenchModel {
@@ -146,7 +201,10 @@ func NewWorkbench() WorkbenchModel {
 		historyIdx:     -1,
 		spinner:        newSpinner(),
 		search:         NewSearchBar(),
+		filter:         NewResponseFilter(),
 		fieldCache:     make(map[string][]CompletionItem),
+		fieldTypes:     make(map[string]map[string]string),
+		valueCache:     make(map[string]map[string][]CompletionItem),
 		clipboard:      NewClipboard(),
 		bookmarkUI:     NewBookmarkUI(),
 		bookmarks:      bookmarks,
@@ -154,6 +212,7 @@ func NewWorkbench() WorkbenchModel {
 		dslContent:     dslContent,
 		dslPath:        dslPath,
 		esqlContent:    esqlContent,
+		consoleContent: defaultConsoleContent,
 	}
 }
 
@@ -162,12 +221,37 @@ func (m *WorkbenchModel) SetClient(client *es.Client) {
 	m.editor.SetClient(client)
 }
 
+// SetVariables sets the values for {{name}} placeholders, from the
+// cluster's variables: map.
+func (m *WorkbenchModel) SetVariables(variables map[string]string) {
+	m.variables = variables
+}
+
+// SetIndexNames sets the index, alias and data stream names offered in
+// the path input, from IndexNameCompletions.
+func (m *WorkbenchModel) SetIndexNames(names []CompletionItem) {
+	m.indexNames = names
+}
+
+// SetSelectedIndex sets the index that {{selected_index}} stands for.
+func (m *WorkbenchModel) SetSelectedIndex(index string) {
+	m.selectedIndex = index
+}
+
+func (m WorkbenchModel) templateVariables() Variables {
+	return Variables{Values: m.variables, SelectedIndex: m.selectedIndex, Now: time.Now()}
+}
+
 func (m WorkbenchModel) HasActiveInput() bool {
-	return m.focus == FocusPath || m.focus == FocusBody || m.search.Active() || m.bookmarkUI.Active() || m.methodDropdown.Open()
+	return m.focus == FocusPath || m.focus == FocusBody || m.search.Active() || m.bookmarkUI.Active() || m.methodDropdown.Open() || m.modal != nil || m.filter.Editing() ||
+		(m.tableActive() && m.table().Picking())
 }
 
 func (m WorkbenchModel) ClipboardMessage() string {
-	return m.clipboard.Message()
+	if msg := m.clipboard.Message(); msg != "" {
+		return msg
+	}
+	return m.notice
 }
 
 func (m WorkbenchModel) paneInnerWidth() int {
@@ -196,12 +280,27 @@ func (m *WorkbenchModel) SetSize(width, height int) {
 }
 
 func (m *WorkbenchModel) Prefill(index string) {
+	if m.queryMode == ModeConsole {
+		m.toggleConsole()
+	}
 	m.methodDropdown.SetSelectedIdx(0) // GET
 	m.path.SetValue("/" + index + "/_search")
 	m.editor.SetContent("{}")
 }
 
 func (m *WorkbenchModel) toggleMode() {
+	if m.queryMode == ModeConsole {
+		m.toggleConsole()
+		return
+	}
+	if m.queryMode == ModeREST && !m.esqlSupported() {
+		m.responseRawText = fmt.Sprintf("ES|QL is not available on %s", m.client.ServerInfo())
+		m.responseText = m.responseRawText
+		m.responseTree = nil
+		m.responseTable = nil
+		m.responseProfile = nil
+		return
+	}
 	if m.queryMode == ModeREST {
 		m.dslContent = m.editor.Content()
 		m.dslPath = m.path.Value()
@@ -216,6 +315,214 @@ func (m *WorkbenchModel) toggleMode() {
 	}
 }
 
+// toggleConsole switches between the single-request editor and the console
+// buffer, keeping the content of both.
+func (m *WorkbenchModel) toggleConsole() {
+	m.completion.Close()
+	switch m.queryMode {
+	case ModeConsole:
+		m.consoleContent = m.editor.Content()
+		m.queryMode = ModeREST
+		m.editor.SetContent(m.dslContent)
+		m.path.SetValue(m.dslPath)
+		return
+	case ModeESSQL:
+		m.esqlContent = m.editor.Content()
+	default:
+		m.dslContent = m.editor.Content()
+		m.dslPath = m.path.Value()
+	}
+	m.queryMode = ModeConsole
+	m.editor.SetContent(m.consoleContent)
+	if m.focus == FocusMethod || m.focus == FocusPath {
+		m.path.Blur()
+		m.methodDropdown.Close()
+		m.focus = FocusNone
+	}
+}
+
+// consoleRequests parses the console buffer and returns the requests and
+// the index of the one under the cursor.
+func (m WorkbenchModel) consoleRequests() ([]ConsoleRequest, int) {
+	requests := ParseConsole(m.editor.Content())
+	return requests, ConsoleRequestAt(requests, m.editor.Line())
+}
+
+// openConsoleFile asks for the file to load the console buffer from, or
+// with ModalSaveFile to save it to.
+func (m *WorkbenchModel) openConsoleFile(modalType ModalType) tea.Cmd {
+	title := "Open console file"
+	if modalType == ModalSaveFile {
+		title = "Save console to file"
+	}
+	m.modal = NewFileModal(modalType, title, "", m.consoleFile)
+	return func() tea.Msg { return ModalInitMsg{} }
+}
+
+// updateModal passes msg to the open modal and acts on it once submitted.
+func (m WorkbenchModel) updateModal(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
+	if _, ok := msg.(ModalInitMsg); ok {
+		return m, m.modal.Init()
+	}
+	cmd := m.modal.Update(msg)
+	if m.modal.Cancelled() {
+		m.modal = nil
+		return m, nil
+	}
+	if !m.modal.Done() {
+		return m, cmd
+	}
+
+	modal := m.modal
+	m.modal = nil
+	path := modal.Path()
+	action := modal.Type()
+	switch action {
+	case ModalOverwrite:
+		if !modal.Confirmed() {
+			return m, nil
+		}
+		action = modal.Action()
+	case ModalSaveFile, ModalExport:
+		// Saving over the file the console came from needs no question.
+		if (action == ModalExport || path != m.consoleFile) && fileExists(path) {
+			m.modal = NewOverwriteModal(action, path)
+			return m, func() tea.Msg { return ModalInitMsg{} }
+		}
+	}
+
+	var err error
+	switch action {
+	case ModalSaveFile:
+		if err = saveConsoleFile(path, m.editor.Content()); err == nil {
+			m.consoleFile = path
+			m.notice = "Saved " + path
+		}
+	case ModalOpenFile:
+		var content string
+		if content, err = loadConsoleFile(path); err == nil {
+			m.editor.SetContent(content)
+			m.consoleFile = path
+			m.notice = "Opened " + path
+		}
+	case ModalExport:
+		var what string
+		if what, err = exportResponse(path, m.displayedResponse(), m.table()); err == nil {
+			m.exportFile = path
+			m.notice = fmt.Sprintf("Saved %s to %s", what, path)
+		}
+	}
+	if err != nil {
+		m.modal = NewErrorModal(err.Error())
+		return m, func() tea.Msg { return ModalInitMsg{} }
+	}
+	return m, nil
+}
+
+// openExport asks where to save the response pane: the response as shown,
+// or its table view.
+func (m *WorkbenchModel) openExport() tea.Cmd {
+	if m.responseRawText == "" {
+		m.notice = "Nothing to save yet"
+		return nil
+	}
+	m.modal = NewFileModal(ModalExport, "Save response", exportHint, m.exportFile)
+	return func() tea.Msg { return ModalInitMsg{} }
+}
+
+// importCurl fills in the request from a pasted curl command and reports
+// whether it did. Anything else is pasted as usual.
+func (m *WorkbenchModel) importCurl(text string) bool {
+	if !strings.HasPrefix(strings.TrimSpace(text), "curl ") {
+		return false
+	}
+	req, err := es.ParseCurl(strings.TrimSpace(text))
+	if err != nil {
+		m.notice = err.Error()
+		return false
+	}
+	body := strings.TrimSpace(req.Body)
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, []byte(body), "", "  ") == nil {
+		body = pretty.String()
+	}
+
+	if m.queryMode == ModeConsole {
+		m.editor.SaveState()
+		m.editor.InsertString(FormatConsoleRequest(req.Method, req.Path, body))
+	} else {
+		if m.queryMode == ModeESSQL {
+			m.toggleMode()
+		}
+		m.selectMethod(req.Method)
+		m.path.SetValue(req.Path)
+		m.editor.SetContent(body)
+	}
+	m.notice = "Imported " + req.Method + " " + req.Path
+	return true
+}
+
+// copyAsCurl copies the current request, or in console mode the request
+// under the cursor, as a curl command for the connected cluster.
+func (m *WorkbenchModel) copyAsCurl() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	req, ok := m.selectedRequest()
+	if !ok {
+		m.notice = "No request under the cursor"
+		return nil
+	}
+	req, err := m.templateVariables().ExpandRequest(req)
+	if err != nil {
+		m.notice = err.Error()
+		return nil
+	}
+	return m.clipboard.Copy(m.client.CurlCommand(req.Method, req.Path, req.Body))
+}
+
+// selectedRequest returns the request in the editor, or in console mode
+// the request under the cursor.
+func (m WorkbenchModel) selectedRequest() (ConsoleRequest, bool) {
+	if m.queryMode != ModeConsole {
+		return m.currentRequest(), true
+	}
+	requests, i := m.consoleRequests()
+	if i < 0 {
+		return ConsoleRequest{}, false
+	}
+	return requests[i], true
+}
+
+// showExpandedRequest shows the request with its placeholders filled in
+// in the response pane, without sending it.
+func (m *WorkbenchModel) showExpandedRequest() {
+	req, ok := m.selectedRequest()
+	if !ok {
+		m.notice = "No request under the cursor"
+		return
+	}
+	if !hasPlaceholders(req.Path) && !hasPlaceholders(req.Body) {
+		m.notice = "The request has no {{variables}}"
+		return
+	}
+	expanded, err := m.templateVariables().ExpandRequest(req)
+	if err != nil {
+		m.showResponseMessage(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	body := expanded.Body
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(body), "", "  "); err == nil {
+		body = pretty.String()
+	}
+	m.showResponseMessage(SanitizeForTerminal(FormatConsoleRequest(expanded.Method, expanded.Path, body)))
+}
+
+func (m WorkbenchModel) esqlSupported() bool {
+	return m.client == nil || m.client.ServerInfo().SupportsESQL()
+}
+
 func (m *WorkbenchModel) Focus() {
 	m.path.Focus()
 	m.focus = FocusPath
@@ -232,7 +539,7 @@ func (m *WorkbenchModel) SetBody(body string) {
 }
 
 func (m WorkbenchModel) jsonError() (line, col int, msg string) {
-	val := m.editor.Content()
+	val := maskPlaceholders(m.editor.Content())
 	if val == "" {
 		return 0, 0, ""
 	}
@@ -273,6 +580,15 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
+	if m.modal != nil {
+		switch msg.(type) {
+		case spinner.TickMsg, executeResultMsg, mappingResultMsg, fieldValuesMsg, validateTickMsg, validateMsg:
+			// Requests started before the modal opened still complete.
+		default:
+			return m.updateModal(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case spinner.TickMsg:
 		if m.executing {
@@ -280,11 +596,22 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			return m, cmd
 		}
 	case executeResultMsg:
+		if !m.executing || msg.seq != m.execSeq {
+			return m, nil
+		}
+		if m.runAll {
+			return m, m.collectRunAllResult(msg)
+		}
 		m.executing = false
+		m.cancelExec()
+		var handoff tea.Cmd
 		if msg.result.Error != nil {
 			m.err = msg.result.Error
 			m.responseRawText = fmt.Sprintf("Error: %v", msg.result.Error)
 			m.responseText = m.responseRawText
+			m.responseTree = nil
+			m.responseTable = nil
+			m.responseProfile = nil
 		} else {
 			m.err = nil
 			m.statusCode = msg.result.StatusCode
@@ -296,20 +623,60 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 				m.responseRawText = SanitizeForTerminal(msg.result.Body)
 			}
 			m.responseText = highlightJSON(m.responseRawText)
+			m.responseTree, _ = NewJSONTree(m.responseRawText)
+			m.responseTable, _ = NewResultTable(m.responseRawText)
+			m.responseProfile, _ = NewSearchProfile(m.responseRawText)
+			if m.responseProfile == nil {
+				m.profileMode = false
+			}
+			if m.queryMode == ModeESSQL && m.responseTable != nil {
+				// ES|QL results are rows; raw JSON is unreadable past a
+				// couple of columns.
+				m.tableMode, m.treeMode = true, false
+			}
 			if msg.result.StatusCode < 400 {
-				m.addToHistory()
+				if m.queryMode == ModeConsole {
+					m.addRequestToHistory(msg.request)
+				} else {
+					m.addToHistory()
+				}
+				if taskID := startedTaskID(msg.request.Path, msg.result.Body); taskID != "" {
+					handoff = func() tea.Msg { return taskHandoffMsg{taskID: taskID} }
+				}
 			}
 		}
 		m.wrapResponseLines()
 		m.responseNav.Reset()
-		return m, nil
+		return m, handoff
 
 	case mappingResultMsg:
-		items := make([]CompletionItem, len(msg.fields))
-		for i, f := range msg.fields {
-			items[i] = CompletionItem{Text: f, Kind: "field"}
+		var items []CompletionItem
+		types := make(map[string]string, len(msg.fields))
+		for _, f := range msg.fields {
+			types[f.Name] = f.Type
+			if f.Type != "object" {
+				items = append(items, CompletionItem{Text: f.Name, Kind: f.Type})
+			}
 		}
 		m.fieldCache[msg.index] = items
+		m.fieldTypes[msg.index] = types
+		return m, nil
+
+	case fieldValuesMsg:
+		if _, requested := m.valueCache[msg.index][msg.field]; !requested {
+			return m, nil
+		}
+		if msg.err != nil {
+			// Not cached, so the next value completion on the field asks again.
+			delete(m.valueCache[msg.index], msg.field)
+			m.notice = fmt.Sprintf("Values of %s: %v", msg.field, msg.err)
+			return m, nil
+		}
+		m.valueCache[msg.index][msg.field] = fieldValueItems(msg.values)
+		if field, ok := m.valueCompletionField(); ok && field == msg.field && msg.index == m.lastIndex &&
+			m.focus == FocusBody && !m.completion.Active {
+			return m, m.openValueCompletion(field)
+		}
 		return m, nil
 
 	case validateTickMsg:
@@ -328,6 +695,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 		return m, nil
 
 	case tea.PasteMsg:
+		if (m.focus == FocusBody || m.focus == FocusPath) && m.importCurl(msg.Content) {
+			return m, m.checkIndexChange()
+		}
 		switch m.focus {
 		case FocusBody:
 			if msg.Content != "" {
@@ -344,6 +714,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 		return m, nil
 
 	case tea.ClipboardMsg:
+		if m.focus == FocusBody && m.importCurl(msg.Content) {
+			return m, m.checkIndexChange()
+		}
 		if m.focus == FocusBody {
 			text := msg.Content
 			if text != "" {
@@ -358,11 +731,27 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 
 	case tea.KeyPressMsg:
 		m.clipboard.ClearMessage()
+		m.notice = ""
+		if m.executing && (msg.String() == "esc" || msg.String() == "ctrl+c") {
+			m.cancelExecution()
+			return m, nil
+		}
 		if m.bookmarkUI.Active() {
 			action, bookmark := m.bookmarkUI.HandleKey(msg)
 			switch action {
 			case BookmarkActionSave:
-				if bookmark != nil {
+				if bookmark != nil && m.queryMode == ModeConsole {
+					// The bookmark is the request under the cursor, the one
+					// Ctrl+B would insert back.
+					req, ok := m.selectedRequest()
+					if !ok {
+						m.notice = "No request under the cursor"
+						return m, nil
+					}
+					bookmark.Method, bookmark.Path, bookmark.Body = req.Method, req.Path, req.Body
+					m.bookmarks.Add(*bookmark)
+					_ = storage.SaveBookmarks(m.bookmarks)
+				} else if bookmark != nil {
 					bookmark.Method = methods[m.methodDropdown.SelectedIdx()]
 					bookmark.Path = m.path.Value()
 					bookmark.Body = m.editor.Content()
@@ -370,7 +759,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 					_ = storage.SaveBookmarks(m.bookmarks)
 				}
 			case BookmarkActionLoad:
-				if bookmark != nil {
+				if bookmark != nil && m.queryMode == ModeConsole {
+					m.editor.InsertString(FormatConsoleRequest(bookmark.Method, bookmark.Path, bookmark.Body))
+				} else if bookmark != nil {
 					m.selectMethod(bookmark.Method)
 					m.path.SetValue(bookmark.Path)
 					m.editor.SetContent(bookmark.Body)
@@ -393,9 +784,44 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			}
 			return m, cmd
 		}
+		if m.filter.Editing() {
+			m.filter.HandleKey(msg)
+			m.refreshFilter()
+			return m, nil
+		}
+		if m.focus == FocusResponse && m.profileActive() && m.handleProfileKey(msg.String()) {
+			return m, nil
+		}
+		if m.focus == FocusResponse && m.tableActive() && m.handleTableKey(msg.String()) {
+			return m, nil
+		}
+		if m.focus == FocusPath && m.pathCompletion.Active {
+			switch msg.String() {
+			case "tab", "down":
+				m.pathCompletion.MoveDown()
+				return m, nil
+			case "shift+tab", "up":
+				m.pathCompletion.MoveUp()
+				return m, nil
+			case "enter":
+				m.acceptPathCompletion()
+				return m, m.checkIndexChange()
+			case "esc":
+				m.pathCompletion.Close()
+				return m, nil
+			}
+		}
+		if m.focus == FocusResponse && m.treeActive() && m.handleTreeKey(msg.String()) {
+			return m, nil
+		}
 
 		switch msg.String() {
 		case "enter":
+			if m.focus == FocusNone && m.queryMode == ModeConsole {
+				m.editor.Focus()
+				m.focus = FocusBody
+				return m, nil
+			}
 			if m.focus == FocusNone {
 				m.path.Focus()
 				m.focus = FocusPath
@@ -411,10 +837,31 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			if cmd := m.startExecution(); cmd != nil {
 				return m, cmd
 			}
+		case "alt+r":
+			if m.queryMode == ModeConsole {
+				return m, m.startRunAll()
+			}
+		case "ctrl+k":
+			if m.focus != FocusBody {
+				m.toggleConsole()
+				return m, nil
+			}
+		case "ctrl+o":
+			if m.focus != FocusBody && m.queryMode == ModeConsole {
+				return m, m.openConsoleFile(ModalOpenFile)
+			}
+		case "alt+e":
+			if m.focus == FocusResponse {
+				return m, m.openExport()
+			}
 		case "ctrl+s":
 			if m.focus != FocusBody {
 				return m, m.bookmarkUI.OpenSave()
 			}
+		case "alt+s":
+			if m.focus != FocusBody && m.queryMode == ModeConsole {
+				return m, m.openConsoleFile(ModalSaveFile)
+			}
 		case "ctrl+b":
 			if m.focus != FocusBody {
 				m.bookmarkUI.OpenLoad()
@@ -426,9 +873,43 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			case FocusBody:
 				text = m.editor.Content()
 			case FocusResponse:
-				text = m.responseRawText
+				text = m.displayedResponse()
+				if m.tableActive() {
+					text = m.table().CSV()
+				}
 			}
 			return m, m.clipboard.Copy(text)
+		case "alt+c":
+			return m, m.copyAsCurl()
+		case "alt+v":
+			m.showExpandedRequest()
+			return m, nil
+		case "/":
+			if m.focus == FocusResponse {
+				return m, m.filter.Open()
+			}
+		case "t":
+			if m.focus == FocusResponse {
+				m.toggleTreeMode()
+				return m, nil
+			}
+		case "T":
+			if m.focus == FocusResponse {
+				m.toggleTableMode()
+				return m, nil
+			}
+		case "P":
+			if m.focus == FocusResponse {
+				m.toggleProfileMode()
+				return m, nil
+			}
+		case "y":
+			if m.focus == FocusResponse && m.tableActive() {
+				return m, m.clipboard.Copy(m.table().Cell())
+			}
+			if m.focus == FocusResponse && m.treeActive() {
+				return m, m.clipboard.Copy(m.tree().Value(m.tree().CursorNode()))
+			}
 		case "ctrl+e":
 			if m.focus != FocusBody {
 				m.toggleMode()
@@ -486,6 +967,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			if m.focus == FocusNone {
 				break
 			}
+			if m.focus == FocusPath && m.openPathCompletion() {
+				return m, m.checkIndexChange()
+			}
 			if m.focus == FocusBody {
 				if m.queryMode == ModeREST {
 					if m.completion.Active {
@@ -496,6 +980,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 						m.triggerCompletion()
 						return m, nil
 					}
+					if field, ok := m.valueCompletionField(); ok {
+						return m, m.openValueCompletion(field)
+					}
 				}
 				m.editor.InsertString("  ")
 				return m, nil
@@ -534,12 +1021,14 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			}
 		}
 
-		if m.focus == FocusBody && m.queryMode == ModeREST {
+		if m.focus == FocusBody && m.queryMode != ModeESSQL {
 			if pair, ok := bracketPairs[msg.String()]; ok {
 				m.editor.InsertString(msg.String() + pair)
 				m.editor.Update(tea.KeyPressMsg{Code: tea.KeyLeft})
 				if msg.String() == "\"" && m.shouldAutoComplete() {
 					m.triggerCompletion()
+				} else if field, ok := m.valueCompletionField(); ok && msg.String() == "\"" {
+					return m, m.openValueCompletion(field)
 				}
 				return m, nil
 			}
@@ -558,6 +1047,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 		case FocusPath:
 			m.path, cmd = m.path.Update(msg)
 			cmds = append(cmds, cmd)
+			if m.pathCompletion.Active {
+				m.refreshPathCompletion()
+			}
 			if cmd := m.checkIndexChange(); cmd != nil {
 				cmds = append(cmds, cmd)
 			}
@@ -611,12 +1103,7 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			if msg.Y < topRowHeight+1 {
 				btnStyle := lipgloss.NewStyle().Padding(0, 1)
 
-				var modeView string
-				if m.queryMode == ModeREST {
-					modeView = btnStyle.Bold(true).Render("[REST]")
-				} else {
-					modeView = btnStyle.Bold(true).Render("[ES|QL]")
-				}
+				modeView := btnStyle.Bold(true).Render(m.modeLabel())
 
 				pos := 0
 				modeEnd := pos + lipgloss.Width(modeView)
@@ -661,6 +1148,9 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 					m.editor.Blur()
 					m.focus = FocusMethod
 					m.methodDropdown.Toggle()
+				} else if msg.X < pathEnd && m.queryMode == ModeConsole {
+					m.editor.Focus()
+					m.focus = FocusBody
 				} else if msg.X < pathEnd {
 					m.editor.Blur()
 					m.path.Focus()
@@ -716,6 +1206,18 @@ func (m WorkbenchModel) Update(msg tea.Msg) (WorkbenchModel, tea.Cmd) {
 			}
 			return m, nil
 		}
+		if m.profileActive() {
+			m.responseProfile.HandleWheel(msg.Button != tea.MouseWheelUp, m.responseVisibleHeight())
+			return m, nil
+		}
+		if m.tableActive() {
+			m.table().HandleWheel(msg.Button != tea.MouseWheelUp, m.responseVisibleHeight())
+			return m, nil
+		}
+		if m.treeActive() {
+			m.tree().HandleWheel(msg.Button != tea.MouseWheelUp, m.responseVisibleHeight())
+			return m, nil
+		}
 		m.responseNav.HandleWheel(msg.Button != tea.MouseWheelUp, len(m.responseLines), m.responseVisibleHeight())
 		return m, nil
 	}
@@ -727,7 +1229,11 @@ func (m *WorkbenchModel) cycleFocus() {
 	m.path.Blur()
 	m.editor.Blur()
 
+	m.pathCompletion.Close()
 	m.focus = (m.focus + 1) % 5
+	if m.queryMode == ModeConsole && (m.focus == FocusMethod || m.focus == FocusPath) {
+		m.focus = FocusBody
+	}
 	switch m.focus {
 	case FocusMethod:
 		// No component to focus
@@ -760,13 +1266,153 @@ func (m *WorkbenchModel) prettyPrintBody() {
 	}
 }
 
+// startExecution sends the request in the editor, or in console mode the
+// request under the cursor.
 func (m *WorkbenchModel) startExecution() tea.Cmd {
 	if m.client == nil || m.executing {
 		return nil
 	}
-	m.prettyPrintBody()
+	if m.queryMode != ModeConsole {
+		m.prettyPrintBody()
+		return m.run([]ConsoleRequest{m.currentRequest()}, false)
+	}
+	requests, i := m.consoleRequests()
+	if i < 0 {
+		m.showResponseMessage("No request under the cursor")
+		return nil
+	}
+	return m.run(requests[i:i+1], false)
+}
+
+// startRunAll sends every request in the console buffer in order and shows
+// the responses one after another.
+func (m *WorkbenchModel) startRunAll() tea.Cmd {
+	if m.client == nil || m.executing {
+		return nil
+	}
+	requests, _ := m.consoleRequests()
+	if len(requests) == 0 {
+		m.showResponseMessage("No requests in the console")
+		return nil
+	}
+	return m.run(requests, true)
+}
+
+func (m *WorkbenchModel) run(queue []ConsoleRequest, all bool) tea.Cmd {
 	m.executing = true
-	return tea.Batch(m.spinner.Tick, m.execute())
+	m.execSeq++
+	m.execStart = time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.execCtx, m.cancelExec = ctx, cancel
+	m.pending = queue[1:]
+	m.runAll = all
+	if all {
+		m.runDone, m.runFailed, m.runTotal = 0, 0, 0
+		m.statusCode = 0
+		m.err = nil
+		m.responseRawText, m.responseText = "", ""
+		m.responseTree = nil
+		m.responseTable = nil
+		m.responseProfile = nil
+		m.wrapResponseLines()
+		m.responseNav.Reset()
+	}
+	return tea.Batch(m.spinner.Tick, m.execute(ctx, m.execSeq, queue[0]))
+}
+
+// collectRunAllResult appends one response of a run-all to the response
+// pane and sends the next request, if any.
+func (m *WorkbenchModel) collectRunAllResult(msg executeResultMsg) tea.Cmd {
+	req := msg.request
+	header := fmt.Sprintf("# %s %s", req.Method, req.Path)
+	var body string
+	if msg.result.Error != nil {
+		header += "  failed"
+		body = fmt.Sprintf("Error: %v", msg.result.Error)
+		m.runFailed++
+	} else {
+		header += fmt.Sprintf("  %d  %s", msg.result.StatusCode, msg.result.Duration)
+		body = SanitizeForTerminal(msg.result.Body)
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, []byte(msg.result.Body), "", "  "); err == nil {
+			body = SanitizeForTerminal(pretty.String())
+		}
+		m.runTotal += msg.result.Duration
+		m.statusCode = max(m.statusCode, msg.result.StatusCode)
+		if msg.result.StatusCode >= 400 {
+			m.runFailed++
+		} else {
+			m.addRequestToHistory(req)
+		}
+	}
+	m.runDone++
+	m.responseRawText += header + "\n" + body + "\n\n"
+	m.responseText += lipgloss.NewStyle().Foreground(ColorGray).Render(header) + "\n" + highlightJSON(body) + "\n\n"
+	m.wrapResponseLines()
+
+	if len(m.pending) > 0 {
+		next := m.pending[0]
+		m.pending = m.pending[1:]
+		return m.execute(m.execCtx, m.execSeq, next)
+	}
+	m.executing = false
+	m.runAll = false
+	m.cancelExec()
+	m.duration = fmt.Sprintf("%d requests, %d failed, %s", m.runDone, m.runFailed, m.runTotal)
+	return nil
+}
+
+func (m *WorkbenchModel) showResponseMessage(text string) {
+	m.responseRawText = text
+	m.responseText = text
+	m.responseTree = nil
+	m.responseTable = nil
+	m.responseProfile = nil
+	m.statusCode = 0
+	m.wrapResponseLines()
+	m.responseNav.Reset()
+}
+
+func (m *WorkbenchModel) cancelExecution() {
+	m.cancelExec()
+	m.executing = false
+	m.err = context.Canceled
+	m.pending = nil
+	notice := fmt.Sprintf("Request cancelled after %s", m.elapsed())
+	if m.runAll {
+		// Keep the responses that already arrived.
+		m.runAll = false
+		m.responseRawText += notice
+		m.responseText += notice
+		m.wrapResponseLines()
+		return
+	}
+	m.responseRawText = notice
+	m.responseText = m.responseRawText
+	m.responseTree = nil
+	m.responseTable = nil
+	m.responseProfile = nil
+	m.wrapResponseLines()
+	m.responseNav.Reset()
+}
+
+func (m WorkbenchModel) elapsed() time.Duration {
+	return time.Since(m.execStart).Round(100 * time.Millisecond)
+}
+
+// startedTaskID returns the task ID from a response to a request sent with
+// wait_for_completion=false, or "" for any other request.
+func startedTaskID(path, body string) string {
+	if !strings.Contains(path, "wait_for_completion=false") {
+		return ""
+	}
+	var response struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		return ""
+	}
+	return response.Task
 }
 
 func (m *WorkbenchModel) addToHistory() {
@@ -787,8 +1433,16 @@ func (m *WorkbenchModel) addToHistory() {
 	m.historyIdx = -1
 }
 
+func (m *WorkbenchModel) addRequestToHistory(req ConsoleRequest) {
+	entry := storage.HistoryEntry{Method: req.Method, Path: req.Path, Body: req.Body}
+	if m.history.Add(entry) {
+		_ = storage.SaveHistory(m.history)
+	}
+	m.historyIdx = -1
+}
+
 func (m *WorkbenchModel) historyPrev() {
-	if m.history == nil || len(m.history.Entries) == 0 {
+	if m.history == nil || len(m.history.Entries) == 0 || m.queryMode == ModeConsole {
 		return
 	}
 
@@ -812,7 +1466,7 @@ func (m *WorkbenchModel) historyPrev() {
 }
 
 func (m *WorkbenchModel) historyNext() {
-	if m.history == nil || len(m.history.Entries) == 0 || m.historyIdx == -1 {
+	if m.history == nil || len(m.history.Entries) == 0 || m.historyIdx == -1 || m.queryMode == ModeConsole {
 		return
 	}
 
@@ -843,13 +1497,29 @@ func (m *WorkbenchModel) loadHistoryEntry() {
 }
 
 func (m *WorkbenchModel) updateSearchMatches() {
-	lines := strings.Split(m.responseRawText, "\n")
-	m.search.FindMatches(lines)
+	if m.profileActive() {
+		m.search.FindMatches(m.responseProfile.SearchLines())
+	} else if m.tableActive() {
+		m.search.FindMatches(m.table().SearchLines())
+	} else if m.treeActive() {
+		m.search.FindMatches(m.tree().SearchLines())
+	} else {
+		m.search.FindMatches(strings.Split(m.displayedResponse(), "\n"))
+	}
 	m.scrollToSearchMatch()
 }
 
+// scrollToSearchMatch shows the current match. In tree mode matches are
+// nodes, and folded ones are unfolded to show them; in table mode they are
+// rows.
 func (m *WorkbenchModel) scrollToSearchMatch() {
-	if match := m.search.CurrentMatch(); match >= 0 {
+	if match := m.search.CurrentMatch(); match >= 0 && m.profileActive() {
+		m.responseProfile.Reveal(match, m.responseVisibleHeight())
+	} else if match >= 0 && m.tableActive() {
+		m.table().Reveal(match, m.responseVisibleHeight())
+	} else if match >= 0 && m.treeActive() {
+		m.tree().Reveal(match, m.responseVisibleHeight())
+	} else if match >= 0 {
 		m.responseNav.Scroll = match
 		ms := navMaxScroll(len(m.responseLines), m.responseVisibleHeight())
 		if m.responseNav.Scroll > ms {
@@ -863,6 +1533,12 @@ func (m WorkbenchModel) responseVisibleHeight() int {
 	if m.search.Active() {
 		h--
 	}
+	if m.treeActive() || m.tableActive() || m.profileActive() {
+		h-- // path breadcrumb, table or profile summary
+	}
+	if m.filter.Visible() {
+		h--
+	}
 	if h < 1 {
 		return 10
 	}
@@ -872,19 +1548,46 @@ func (m WorkbenchModel) responseVisibleHeight() int {
 func (m WorkbenchModel) renderResponseContent(paneInnerWidth int) string {
 	var b strings.Builder
 	visibleHeight := m.responseVisibleHeight()
-	endIdx := min(m.responseNav.Scroll+visibleHeight, len(m.responseLines))
-	startIdx := m.responseNav.Scroll
-	if startIdx >= len(m.responseLines) {
-		startIdx = max(0, len(m.responseLines)-1)
-	}
-
-	for i := startIdx; i < endIdx; i++ {
-		b.WriteString(m.responseLines[i])
-		if i < endIdx-1 {
+	if m.profileActive() {
+		profile := m.responseProfile
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorGray).Render(Truncate(profile.Summary(), paneInnerWidth-2)))
+		for _, line := range profile.View(paneInnerWidth-2, visibleHeight) {
+			b.WriteString("\n")
+			b.WriteString(line)
+		}
+	} else if m.tableActive() {
+		table := m.table()
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorGray).Render(Truncate(table.Summary(), paneInnerWidth-2)))
+		for _, line := range table.View(paneInnerWidth-2, visibleHeight) {
+			b.WriteString("\n")
+			b.WriteString(line)
+		}
+	} else if m.treeActive() {
+		tree := m.tree()
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorGray).Render(Truncate(tree.Path(tree.CursorNode()), paneInnerWidth-2)))
+		for _, line := range tree.View(paneInnerWidth-2, visibleHeight) {
 			b.WriteString("\n")
+			b.WriteString(line)
+		}
+	} else {
+		endIdx := min(m.responseNav.Scroll+visibleHeight, len(m.responseLines))
+		startIdx := m.responseNav.Scroll
+		if startIdx >= len(m.responseLines) {
+			startIdx = max(0, len(m.responseLines)-1)
+		}
+
+		for i := startIdx; i < endIdx; i++ {
+			b.WriteString(m.responseLines[i])
+			if i < endIdx-1 {
+				b.WriteString("\n")
+			}
 		}
 	}
 
+	if m.filter.Visible() {
+		b.WriteString("\n")
+		b.WriteString(m.filter.View(paneInnerWidth - 4))
+	}
 	if m.search.Active() {
 		b.WriteString("\n")
 		b.WriteString(m.search.View(paneInnerWidth - 4))
@@ -894,7 +1597,18 @@ func (m WorkbenchModel) renderResponseContent(paneInnerWidth int) string {
 }
 
 func (m *WorkbenchModel) wrapResponseLines() {
-	if m.responseText == "" {
+	m.filter.Run(m.responseRawText)
+	text := m.responseText
+	if m.filter.Applied() {
+		if m.filterFor != m.filter.Result() {
+			m.filterFor = m.filter.Result()
+			m.filterText = highlightJSON(m.filterFor)
+			m.filterTree, _ = NewJSONTree(m.filterFor)
+			m.filterTable, _ = NewResultTable(m.filterFor)
+		}
+		text = m.filterText
+	}
+	if text == "" {
 		m.responseLines = nil
 		return
 	}
@@ -903,29 +1617,168 @@ func (m *WorkbenchModel) wrapResponseLines() {
 		paneInnerWidth = 40
 	}
 	wrapWidth := paneInnerWidth - 2
-	wrapped := ansi.Hardwrap(m.responseText, wrapWidth, false)
+	wrapped := ansi.Hardwrap(text, wrapWidth, false)
 	m.responseLines = strings.Split(wrapped, "\n")
 }
 
-func (m WorkbenchModel) execute() tea.Cmd {
-	return func() tea.Msg {
-		ctx := context.Background()
-		var method, path, body string
+// tree returns the tree of what the response pane shows: the filter result
+// when a filter is applied.
+func (m WorkbenchModel) tree() *JSONTree {
+	if m.filter.Applied() {
+		return m.filterTree
+	}
+	return m.responseTree
+}
 
-		if m.queryMode == ModeESSQL {
-			method = "POST"
-			path = m.path.Value()
-			query := m.editor.Content()
-			escaped, _ := json.Marshal(query)
-			body = fmt.Sprintf(`{"query":%s}`, string(escaped))
-		} else {
-			method = methods[m.methodDropdown.SelectedIdx()]
-			path = m.path.Value()
-			body = m.editor.Content()
+func (m WorkbenchModel) treeActive() bool {
+	return m.treeMode && m.tree() != nil
+}
+
+// table returns the table of what the response pane shows, like tree.
+func (m WorkbenchModel) table() *ResultTable {
+	if m.filter.Applied() {
+		return m.filterTable
+	}
+	return m.responseTable
+}
+
+func (m WorkbenchModel) tableActive() bool {
+	return m.tableMode && m.table() != nil
+}
+
+// profileActive reports whether the response pane shows the search
+// profile. It covers the whole response, so a filter does not change it.
+func (m WorkbenchModel) profileActive() bool {
+	return m.profileMode && m.responseProfile != nil
+}
+
+// displayedResponse is the plain text of what the response pane shows.
+func (m WorkbenchModel) displayedResponse() string {
+	if m.filter.Applied() {
+		return m.filter.Result()
+	}
+	return m.responseRawText
+}
+
+// refreshFilter shows the result of the edited filter expression.
+func (m *WorkbenchModel) refreshFilter() {
+	m.wrapResponseLines()
+	m.responseNav.Reset()
+	if m.search.Query() != "" {
+		m.updateSearchMatches()
+	}
+}
+
+func (m *WorkbenchModel) toggleTreeMode() {
+	if m.tree() == nil {
+		m.notice = "Tree view needs a JSON response"
+		return
+	}
+	m.treeMode = !m.treeMode
+	m.tableMode, m.profileMode = false, false
+	if m.search.Query() != "" {
+		m.updateSearchMatches()
+	}
+}
+
+func (m *WorkbenchModel) toggleTableMode() {
+	if m.table() == nil {
+		m.notice = "Table view needs search hits, aggregations or ES|QL results"
+		return
+	}
+	m.tableMode = !m.tableMode
+	m.treeMode, m.profileMode = false, false
+	if m.search.Query() != "" {
+		m.updateSearchMatches()
+	}
+}
+
+func (m *WorkbenchModel) toggleProfileMode() {
+	if m.responseProfile == nil {
+		m.notice = `Profile view needs a search run with "profile": true`
+		return
+	}
+	m.profileMode = !m.profileMode
+	m.treeMode, m.tableMode = false, false
+	if m.search.Query() != "" {
+		m.updateSearchMatches()
+	}
+}
+
+// handleProfileKey moves through the profile and folds breakdowns. Folding
+// adds and removes rows, so search matches are found again.
+func (m *WorkbenchModel) handleProfileKey(key string) bool {
+	rows := len(m.responseProfile.rows)
+	if !m.responseProfile.HandleKey(key, m.responseVisibleHeight()) {
+		return false
+	}
+	if m.search.Query() != "" && len(m.responseProfile.rows) != rows {
+		m.search.FindMatches(m.responseProfile.SearchLines())
+	}
+	return true
+}
+
+// handleTableKey passes a key to the response table. Sorting moves rows,
+// so search matches are found again.
+func (m *WorkbenchModel) handleTableKey(key string) bool {
+	if !m.table().HandleKey(key, m.responseVisibleHeight()) {
+		return false
+	}
+	if m.search.Query() != "" && (key == "s" || key == "[" || key == "]") {
+		m.search.FindMatches(m.table().SearchLines())
+	}
+	return true
+}
+
+// handleTreeKey moves through and folds the response tree. It reports
+// whether the key was used.
+func (m *WorkbenchModel) handleTreeKey(key string) bool {
+	tree, visible := m.tree(), m.responseVisibleHeight()
+	switch key {
+	case "enter", "space":
+		tree.Toggle(visible)
+	case "left", "h":
+		tree.Collapse(visible)
+	case "right", "l":
+		tree.Expand(visible)
+	case "+", "=":
+		tree.ExpandAll(visible)
+	case "-":
+		tree.CollapseAll(visible)
+	default:
+		return tree.HandleKey(key, visible)
+	}
+	return true
+}
+
+// currentRequest builds the request from the method, path and editor of
+// the REST and ES|QL modes.
+func (m WorkbenchModel) currentRequest() ConsoleRequest {
+	if m.queryMode == ModeESSQL {
+		escaped, _ := json.Marshal(m.editor.Content())
+		return ConsoleRequest{Method: "POST", Path: m.path.Value(), Body: fmt.Sprintf(`{"query":%s}`, string(escaped))}
+	}
+	return ConsoleRequest{Method: methods[m.methodDropdown.SelectedIdx()], Path: m.path.Value(), Body: m.editor.Content()}
+}
+
+// execute sends req with its placeholders filled in. The message keeps
+// the request as written, so history and bookmarks keep the placeholders.
+func (m WorkbenchModel) execute(ctx context.Context, seq int, req ConsoleRequest) tea.Cmd {
+	client, variables := m.client, m.templateVariables()
+	return func() tea.Msg {
+		expanded, err := variables.ExpandRequest(req)
+		if err != nil {
+			return executeResultMsg{result: es.RequestResult{Error: err}, request: req, seq: seq}
 		}
+		result := client.Request(ctx, expanded.Method, expanded.Path, expanded.Body)
+		return executeResultMsg{result: result, request: req, seq: seq}
+	}
+}
 
-		result := m.client.Request(ctx, method, path, body)
-		return executeResultMsg{result}
+func (m WorkbenchModel) fetchFieldValues(index, field string) tea.Cmd {
+	return func() tea.Msg {
+		values, err := m.client.FetchFieldValues(context.Background(), index, field, fieldValueCount)
+		return fieldValuesMsg{index: index, field: field, values: values, err: err}
 	}
 }
 
@@ -958,10 +1811,12 @@ func (m *WorkbenchModel) checkIndexChange() tea.Cmd {
 func (m WorkbenchModel) View() string {
 	modeStyle := lipgloss.NewStyle().Padding(0, 1).Bold(true)
 	var modeView string
-	if m.queryMode == ModeREST {
-		modeView = modeStyle.Render("[REST]")
+	if m.queryMode == ModeREST && !m.esqlSupported() {
+		modeView = modeStyle.Foreground(ColorGray).Render(m.modeLabel())
+	} else if m.queryMode == ModeREST {
+		modeView = modeStyle.Render(m.modeLabel())
 	} else {
-		modeView = modeStyle.Background(ColorBlue).Foreground(ColorOnAccent).Render("[ES|QL]")
+		modeView = modeStyle.Background(ColorBlue).Foreground(ColorOnAccent).Render(m.modeLabel())
 	}
 
 	var methodView string
@@ -981,6 +1836,9 @@ func (m WorkbenchModel) View() string {
 	}
 	pathStyle := lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(pathBorderColor)
 	pathView := pathStyle.Render(m.path.View())
+	if m.queryMode == ModeConsole {
+		pathView = pathStyle.Render(m.consoleRequestLabel(lipgloss.Width(m.path.View())))
+	}
 
 	btnStyle := lipgloss.NewStyle().Padding(0, 1).Background(ActiveBg)
 	execBtn := btnStyle.Render("▶ Run")
@@ -1040,6 +1898,11 @@ func (m WorkbenchModel) View() string {
 	if m.queryMode == ModeESSQL {
 		bodyHeaderText = "Body (ES|QL)"
 		bodyValidation = ""
+	} else if m.queryMode == ModeConsole {
+		bodyHeaderText = "Console"
+		if m.consoleFile != "" {
+			bodyValidation = lipgloss.NewStyle().Foreground(ColorGray).Render(m.consoleFile)
+		}
 	} else {
 		bodyHeaderText = "Body"
 		if errMsg != "" {
@@ -1063,7 +1926,14 @@ func (m WorkbenchModel) View() string {
 			}
 		}
 	}
-	bodyHeader := lipgloss.NewStyle().Bold(true).Render(bodyHeaderText) + "  " + bodyValidation
+	var warnings []QueryWarning
+	if m.queryMode == ModeREST && errMsg == "" {
+		warnings = LintQuery(maskPlaceholders(m.editor.Content()), m.fieldTypes[m.lastIndex])
+	}
+	if len(warnings) > 0 {
+		bodyValidation += " " + lipgloss.NewStyle().Foreground(ColorYellow).Render(m.warningSummary(warnings))
+	}
+	bodyHeader := ansi.Truncate(lipgloss.NewStyle().Bold(true).Render(bodyHeaderText)+"  "+bodyValidation, paneInnerWidth, "…")
 
 	paneHeight := m.height - 6
 	bodyPaneContent := lipgloss.JoinVertical(lipgloss.Left,
@@ -1076,6 +1946,7 @@ func (m WorkbenchModel) View() string {
 		Height(paneHeight).
 		Render(bodyPaneContent)
 	bodyPane = clipPane(bodyPane, paneHeight)
+	bodyPane = m.markWarningLines(bodyPane, warnings)
 
 	// Right pane - response
 	responseBorder := lipgloss.RoundedBorder()
@@ -1094,8 +1965,23 @@ func (m WorkbenchModel) View() string {
 			lipgloss.NewStyle().Foreground(statusColor).Render(fmt.Sprintf("%d", m.statusCode)),
 			lipgloss.NewStyle().Foreground(ColorGray).Render(m.duration))
 	}
-	if m.executing {
-		responseHeader = m.spinner.View() + " Executing..."
+	if m.treeActive() {
+		responseHeader += lipgloss.NewStyle().Foreground(ColorGray).Render("  [tree]")
+	}
+	if m.tableActive() {
+		responseHeader += lipgloss.NewStyle().Foreground(ColorGray).Render("  [table]")
+	}
+	if m.profileActive() {
+		responseHeader += lipgloss.NewStyle().Foreground(ColorGray).Render("  [profile]")
+	} else if m.responseProfile != nil && !m.executing {
+		responseHeader += lipgloss.NewStyle().Foreground(ColorYellow).Render("  P profile")
+	}
+	if m.executing && m.runAll {
+		responseHeader = m.spinner.View() + fmt.Sprintf(" Executing %d/%d... ", m.runDone+1, m.runDone+1+len(m.pending)) +
+			lipgloss.NewStyle().Foreground(ColorGray).Render(m.elapsed().String()+"  (Esc to cancel)")
+	} else if m.executing {
+		responseHeader = m.spinner.View() + " Executing... " +
+			lipgloss.NewStyle().Foreground(ColorGray).Render(m.elapsed().String()+"  (Esc to cancel)")
 	}
 
 	responseContent := m.renderResponseContent(paneInnerWidth)
@@ -1114,13 +2000,22 @@ func (m WorkbenchModel) View() string {
 
 	panes := JoinPanesHorizontal(0, bodyPane, responsePane)
 
-	output := lipgloss.JoinVertical(lipgloss.Left, topRow, "", panes)
+	output := lipgloss.JoinVertical(lipgloss.Left, topRow, m.variablePreview(), panes)
 
 	lines := strings.Split(output, "\n")
 	for i, line := range lines {
 		lines[i] = strings.TrimRight(line, " ")
 	}
 
+	if m.focus == FocusPath && m.pathCompletion.Active {
+		x := lipgloss.Width(modeView) + 1
+		if methodView != "" {
+			x += lipgloss.Width(methodView) + 1
+		}
+		x += 1 + lipgloss.Width(m.path.Prompt) + m.pathCompletion.TriggerCol
+		lines = overlayAt(lines, renderCompletionList(m.pathCompletion), x, lipgloss.Height(topRow))
+	}
+
 	if m.methodDropdown.Open() && m.queryMode == ModeREST {
 		output = m.methodDropdown.Overlay(strings.Join(lines, "\n"))
 		lines = strings.Split(output, "\n")
@@ -1129,13 +2024,61 @@ func (m WorkbenchModel) View() string {
 	if m.bookmarkUI.Active() {
 		return m.bookmarkUI.View(m.width, m.height)
 	}
+	if m.modal != nil {
+		return m.modal.View(m.width, m.height)
+	}
 
 	return strings.Join(lines, "\n")
 }
 
-func (m *WorkbenchModel) triggerCompletion() {
-	text := m.editor.Content()
-	lines := strings.Split(text, "\n")
+// variablePreview shows where a request with {{variables}} will go, on
+// the line under the path, or why it can't be sent. It is empty for
+// requests without placeholders.
+func (m WorkbenchModel) variablePreview() string {
+	req, ok := m.selectedRequest()
+	if !ok || (!hasPlaceholders(req.Path) && !hasPlaceholders(req.Body)) {
+		return ""
+	}
+	var line string
+	if expanded, err := m.templateVariables().ExpandRequest(req); err != nil {
+		line = lipgloss.NewStyle().Foreground(ColorRed).Render("✗ " + err.Error())
+	} else {
+		line = lipgloss.NewStyle().Foreground(ColorGray).Render("→ "+expanded.Method+" ") + expanded.Path +
+			lipgloss.NewStyle().Foreground(ColorGray).Render("  (Alt+V for the body)")
+	}
+	return ansi.Truncate(" "+line, m.width, "…")
+}
+
+func (m WorkbenchModel) modeLabel() string {
+	switch m.queryMode {
+	case ModeESSQL:
+		return "[ES|QL]"
+	case ModeConsole:
+		return "[Console]"
+	}
+	return "[REST]"
+}
+
+// consoleRequestLabel shows the request under the cursor where the path
+// input sits in the other modes, padded to the same width.
+func (m WorkbenchModel) consoleRequestLabel(width int) string {
+	requests, i := m.consoleRequests()
+	label := lipgloss.NewStyle().Foreground(ColorGray).Render("No request under the cursor")
+	if i >= 0 {
+		r := requests[i]
+		label = lipgloss.NewStyle().Foreground(ColorGray).Render(fmt.Sprintf("%d/%d ", i+1, len(requests))) +
+			lipgloss.NewStyle().Bold(true).Render(r.Method) + " " + r.Path
+	}
+	label = ansi.Truncate(label, width, "…")
+	if pad := width - lipgloss.Width(label); pad > 0 {
+		label += strings.Repeat(" ", pad)
+	}
+	return label
+}
+
+// textBeforeCursor returns the body up to the cursor.
+func (m WorkbenchModel) textBeforeCursor() string {
+	lines := strings.Split(m.editor.Content(), "\n")
 	row := m.editor.Line()
 	col := m.editor.LineInfo().CharOffset
 
@@ -1151,8 +2094,12 @@ func (m *WorkbenchModel) triggerCompletion() {
 			textUpToCursor += lines[row]
 		}
 	}
+	return textUpToCursor
+}
 
-	ctx := ParseJSONContext(textUpToCursor)
+func (m *WorkbenchModel) triggerCompletion() {
+	col := m.editor.LineInfo().CharOffset
+	ctx := ParseJSONContext(m.textBeforeCursor())
 
 	var items []CompletionItem
 	keywords := GetKeywordsForContext(ctx.Path)
@@ -1172,6 +2119,53 @@ func (m *WorkbenchModel) triggerCompletion() {
 	m.completion.SelectedIdx = 0
 	m.completion.TriggerCol = col
 	m.completion.Query = ""
+	m.completion.Values = false
+}
+
+// valueCompletionField returns the keyword field whose value the cursor is
+// in, for a term, terms or match query.
+func (m WorkbenchModel) valueCompletionField() (string, bool) {
+	if m.queryMode != ModeREST || m.lastIndex == "" {
+		return "", false
+	}
+	field, _, ok := fieldValueContext(m.textBeforeCursor())
+	if !ok || !keywordTypes[m.fieldTypes[m.lastIndex][field]] {
+		return "", false
+	}
+	return field, true
+}
+
+// openValueCompletion offers the most common values of field, fetching
+// them with a terms aggregation the first time. The list opens when they
+// arrive if the cursor is still in the value.
+func (m *WorkbenchModel) openValueCompletion(field string) tea.Cmd {
+	values, cached := m.valueCache[m.lastIndex][field]
+	if !cached {
+		if m.client == nil {
+			return nil
+		}
+		if m.valueCache[m.lastIndex] == nil {
+			m.valueCache[m.lastIndex] = make(map[string][]CompletionItem)
+		}
+		m.valueCache[m.lastIndex][field] = nil
+		return m.fetchFieldValues(m.lastIndex, field)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, partial, _ := fieldValueContext(m.textBeforeCursor())
+	m.completion.Active = true
+	m.completion.Items = values
+	m.completion.Filtered = values
+	m.completion.SelectedIdx = 0
+	m.completion.TriggerCol = m.editor.LineInfo().CharOffset - len([]rune(partial))
+	m.completion.Query = ""
+	m.completion.Values = true
+	if partial != "" {
+		m.completion.Filter(partial)
+	}
+	return nil
 }
 
 func (m *WorkbenchModel) shouldAutoComplete() bool {
@@ -1238,6 +2232,10 @@ func (m *WorkbenchModel) acceptCompletion() {
 		m.completion.Close()
 		return
 	}
+	if m.completion.Values {
+		m.acceptValueCompletion(selected.Text)
+		return
+	}
 
 	query := m.getCompletionQuery()
 	if len(query) > len(selected.Text) {
@@ -1273,8 +2271,108 @@ func (m *WorkbenchModel) acceptCompletion() {
 	m.completion.Close()
 }
 
+// acceptValueCompletion replaces the partial value with value, quoted,
+// leaving the cursor after the closing quote.
+func (m *WorkbenchModel) acceptValueCompletion(value string) {
+	for range []rune(m.getCompletionQuery()) {
+		m.editor.Update(tea.KeyPressMsg{Code: tea.KeyBackspace})
+	}
+
+	lineRunes := []rune(strings.Split(m.editor.Content(), "\n")[m.editor.Line()])
+	col := m.editor.LineInfo().CharOffset
+	text := quoteJSON(value)
+	if col == 0 || lineRunes[col-1] != '"' {
+		text = `"` + text
+	}
+	if col < len(lineRunes) && lineRunes[col] == '"' {
+		m.editor.InsertString(text)
+		m.editor.Update(tea.KeyPressMsg{Code: tea.KeyRight})
+	} else {
+		m.editor.InsertString(text + `"`)
+	}
+	m.completion.Close()
+}
+
+// pathCompletions returns the completions for the path before the cursor
+// and the rune offset of the word they replace.
+func (m WorkbenchModel) pathCompletions() ([]CompletionItem, int) {
+	before := string([]rune(m.path.Value())[:m.path.Position()])
+	return PathCompletions(before, m.indexNames)
+}
+
+// openPathCompletion completes the word before the cursor in the path:
+// a single match is filled in, several open the dropdown after filling in
+// what they share. It reports false when there is nothing to offer, so
+// Tab moves on to the body as before.
+func (m *WorkbenchModel) openPathCompletion() bool {
+	items, start := m.pathCompletions()
+	word := string([]rune(m.path.Value())[start:m.path.Position()])
+	if len(items) == 0 || (len(items) == 1 && items[0].Text == word) {
+		return false
+	}
+	if len(items) == 1 {
+		m.replacePathWord(start, items[0].Text)
+		return true
+	}
+	if shared := commonPrefix(items); len(shared) > len(word) {
+		m.replacePathWord(start, shared)
+	}
+	m.pathCompletion.Items = items
+	m.pathCompletion.TriggerCol = start
+	m.refreshPathCompletion()
+	m.pathCompletion.Active = len(m.pathCompletion.Filtered) > 0
+	return true
+}
+
+// refreshPathCompletion narrows the dropdown to the word being typed,
+// closing it when the cursor leaves the word or nothing matches.
+func (m *WorkbenchModel) refreshPathCompletion() {
+	items, start := m.pathCompletions()
+	if start != m.pathCompletion.TriggerCol || len(items) == 0 {
+		m.pathCompletion.Close()
+		return
+	}
+	m.pathCompletion.Active = true
+	m.pathCompletion.Items = items
+	m.pathCompletion.Filtered = items
+	m.pathCompletion.SelectedIdx = 0
+	m.pathCompletion.Query = string([]rune(m.path.Value())[start:m.path.Position()])
+}
+
+func (m *WorkbenchModel) acceptPathCompletion() {
+	if selected := m.pathCompletion.Selected(); selected != nil {
+		m.replacePathWord(m.pathCompletion.TriggerCol, selected.Text)
+	}
+	m.pathCompletion.Close()
+}
+
+// replacePathWord replaces the path from start to the cursor with text.
+func (m *WorkbenchModel) replacePathWord(start int, text string) {
+	value := []rune(m.path.Value())
+	pos := m.path.Position()
+	m.path.SetValue(string(value[:start]) + text + string(value[pos:]))
+	m.path.SetCursor(start + len([]rune(text)))
+}
+
+func commonPrefix(items []CompletionItem) string {
+	prefix := items[0].Text
+	for _, item := range items[1:] {
+		for !strings.HasPrefix(item.Text, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
 func (m *WorkbenchModel) extractIndexFromPath() string {
 	path := m.path.Value()
+	if hasPlaceholders(path) {
+		expanded, err := m.templateVariables().Expand(path)
+		if err != nil {
+			return ""
+		}
+		path = expanded
+	}
 	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
 	for _, part := range parts {
 		if part != "" && !strings.HasPrefix(part, "_") {
@@ -1285,14 +2383,19 @@ func (m *WorkbenchModel) extractIndexFromPath() string {
 }
 
 func (m WorkbenchModel) renderCompletionDropdown() string {
-	if !m.completion.Active || len(m.completion.Filtered) == 0 {
+	return renderCompletionList(m.completion)
+}
+
+// renderCompletionList draws the open completions of c, scrolled so the
+// selected one is visible.
+func renderCompletionList(c CompletionState) string {
+	if !c.Active || len(c.Filtered) == 0 {
 		return ""
 	}
 
-	items := m.completion.Filtered
-	if len(items) > completionMaxVisible {
-		items = items[:completionMaxVisible]
-	}
+	items := c.Filtered
+	offset := max(0, c.SelectedIdx-completionMaxVisible+1)
+	items = items[offset:min(len(items), offset+completionMaxVisible)]
 
 	var lines []string
 	for i, item := range items {
@@ -1302,7 +2405,7 @@ func (m WorkbenchModel) renderCompletionDropdown() string {
 		}
 
 		style := lipgloss.NewStyle().Background(ActiveBg)
-		if i == m.completion.SelectedIdx {
+		if offset+i == c.SelectedIdx {
 			style = style.Background(ColorBlue).Foreground(ColorOnAccent)
 		}
 		lines = append(lines, style.Render(text))
@@ -1316,6 +2419,40 @@ func (m WorkbenchModel) renderCompletionDropdown() string {
 	return dropdown
 }
 
+// warningSummary describes the query warning on the cursor line, or the
+// first one, with the number of others.
+func (m WorkbenchModel) warningSummary(warnings []QueryWarning) string {
+	w := warnings[0]
+	for _, candidate := range warnings {
+		if candidate.Line == m.editor.Line()+1 {
+			w = candidate
+			break
+		}
+	}
+	summary := fmt.Sprintf("⚠ %d:%d %s", w.Line, w.Col, w.Message)
+	if len(warnings) > 1 {
+		summary += fmt.Sprintf(" (+%d)", len(warnings)-1)
+	}
+	return summary
+}
+
+// markWarningLines highlights the left border of the body pane next to
+// lines with query warnings.
+func (m WorkbenchModel) markWarningLines(pane string, warnings []QueryWarning) string {
+	if len(warnings) == 0 {
+		return pane
+	}
+	mark := lipgloss.NewStyle().Foreground(ColorYellow).Render("┃")
+	lines := strings.Split(pane, "\n")
+	for _, w := range warnings {
+		// Editor rows start below the top border and the body header.
+		if row := m.editor.ViewRow(w.Line-1) + 2; row >= 2 && row < len(lines)-1 {
+			lines[row] = strings.Replace(lines[row], "│", mark, 1)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m WorkbenchModel) overlayErrorMarker(bodyView string, errorLine int) string {
 	if errorLine <= 0 {
 		return bodyView
@@ -1352,6 +2489,29 @@ func (m WorkbenchModel) overlayDropdown(bodyView, dropdown string) string {
 	return strings.Join(result, "\n")
 }
 
+// overlayAt draws block over lines with its top left corner at x, y,
+// moved left when it would run past the widest line.
+func overlayAt(lines []string, block string, x, y int) []string {
+	blockLines := strings.Split(block, "\n")
+	width := 0
+	for _, line := range lines {
+		width = max(width, ansi.StringWidth(line))
+	}
+	x = max(0, min(x, width-lipgloss.Width(block)))
+	for i, blockLine := range blockLines {
+		row := y + i
+		if row >= len(lines) {
+			break
+		}
+		left := ansi.Truncate(lines[row], x, "")
+		if pad := x - ansi.StringWidth(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		lines[row] = left + blockLine + ansi.TruncateLeft(lines[row], x+ansi.StringWidth(blockLine), "")
+	}
+	return lines
+}
+
 func clipPane(rendered string, targetHeight int) string {
 	lines := strings.Split(rendered, "\n")
 	if len(lines) <= targetHeight {
@@ -6,8 +6,30 @@ import (
 	"testing"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/labtiva/stoptail/internal/config"
+	"github.com/labtiva/stoptail/internal/es"
 )
 
+// newWorkbenchWithResult returns a workbench with the response focused
+// and body in it, as if a request had just come back.
+func newWorkbenchWithResult(t *testing.T, body string) WorkbenchModel {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	w := NewWorkbench()
+	w.SetSize(120, 40)
+	w.focus = FocusResponse
+	return showResult(w, body)
+}
+
+// showResult delivers body as the response to a request in flight.
+func showResult(w WorkbenchModel, body string) WorkbenchModel {
+	w.executing = true
+	w.execSeq++
+	w.cancelExec = func() {}
+	w, _ = w.Update(executeResultMsg{seq: w.execSeq, result: es.RequestResult{StatusCode: 200, Body: body}})
+	return w
+}
+
 func TestOffsetToLineCol(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -267,6 +289,54 @@ func TestCtrlRExecutionState(t *testing.T) {
 	}
 }
 
+func TestCancelExecution(t *testing.T) {
+	w := NewWorkbench()
+	w.SetSize(80, 30)
+
+	cancelled := false
+	w.executing = true
+	w.execSeq = 1
+	w.cancelExec = func() { cancelled = true }
+
+	w, _ = w.Update(tea.KeyPressMsg{Code: tea.KeyEscape})
+	if !cancelled {
+		t.Error("esc while executing should cancel the request")
+	}
+	if w.executing {
+		t.Error("executing should be false after cancel")
+	}
+	if !strings.Contains(w.responseRawText, "cancelled") {
+		t.Errorf("response = %q, want cancellation notice", w.responseRawText)
+	}
+
+	w, cmd := w.Update(executeResultMsg{result: es.RequestResult{StatusCode: 200, Body: "{}"}, seq: 1})
+	if cmd != nil || w.statusCode == 200 {
+		t.Error("result of a cancelled request should be ignored")
+	}
+}
+
+func TestStartedTaskID(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		body string
+		want string
+	}{
+		{"async reindex", "/_reindex?wait_for_completion=false", `{"task":"n1:42"}`, "n1:42"},
+		{"sync reindex", "/_reindex", `{"task":"n1:42"}`, ""},
+		{"no task in body", "/logs/_forcemerge?wait_for_completion=false", `{"_shards":{}}`, ""},
+		{"invalid body", "/_reindex?wait_for_completion=false", `oops`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startedTaskID(tt.path, tt.body); got != tt.want {
+				t.Errorf("startedTaskID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestResponsePgDownFullFlow(t *testing.T) {
 	m := New(nil, nil)
 	m.connected = true
@@ -458,3 +528,44 @@ func TestExtractIndexFromPath(t *testing.T) {
 		}
 	}
 }
+
+func TestCurlImportExport(t *testing.T) {
+	client, err := es.NewClient(&config.Config{Host: "https://es.example.com:9200", Username: "elastic", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorkbench()
+	w.SetClient(client)
+	w.SetSize(120, 40)
+	w.editor.Focus()
+	w.focus = FocusBody
+
+	w, _ = w.Update(tea.PasteMsg{Content: `curl -u elastic:secret -XPOST 'localhost:9200/logs/_count' -d '{"query":{"match_all":{}}}'`})
+	if got := methods[w.methodDropdown.SelectedIdx()]; got != "POST" || w.path.Value() != "/logs/_count" {
+		t.Errorf("imported %s %s, want POST /logs/_count", got, w.path.Value())
+	}
+	if w.editor.Content() != "{\n  \"query\": {\n    \"match_all\": {}\n  }\n}" {
+		t.Errorf("body = %q, want pretty JSON", w.editor.Content())
+	}
+
+	w, _ = w.Update(tea.PasteMsg{Content: "curling is fun"})
+	if !strings.HasSuffix(w.editor.Content(), "curling is fun") {
+		t.Error("other text should be pasted as usual")
+	}
+
+	w.focus = FocusNone
+	w.editor.Blur()
+	w, cmd := w.Update(tea.KeyPressMsg{Code: 'c', Mod: tea.ModAlt})
+	if cmd == nil || w.ClipboardMessage() != "Copied!" {
+		t.Errorf("alt+c should copy the request, message %q", w.ClipboardMessage())
+	}
+
+	w.toggleConsole()
+	w.editor.SetContent("")
+	w.editor.Focus()
+	w.focus = FocusBody
+	w, _ = w.Update(tea.PasteMsg{Content: "curl localhost:9200/_cat/shards"})
+	if w.editor.Content() != "GET /_cat/shards\n" {
+		t.Errorf("console import = %q, want a console block", w.editor.Content())
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
@@ -13,6 +14,7 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/labtiva/stoptail/internal/config"
 	"github.com/labtiva/stoptail/internal/es"
+	"github.com/labtiva/stoptail/internal/storage"
 	"github.com/labtiva/stoptail/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -24,14 +26,19 @@ var (
 )
 
 var (
-	themeFlag  string
-	renderFlag string
-	tabFlag    string
-	keysFlag   string
-	widthFlag  int
-	heightFlag int
-	bodyFlag   string
-	viewFlag   string
+	themeFlag        string
+	renderFlag       string
+	renderFormatFlag string
+	tabFlag          string
+	keysFlag         string
+	widthFlag        int
+	heightFlag       int
+	bodyFlag         string
+	viewFlag         string
+
+	readOnlyFlag bool
+	recordFlag   string
+	replayFlag   string
 )
 
 func main() {
@@ -55,6 +62,7 @@ Examples:
 
 	rootCmd.Flags().StringVar(&themeFlag, "theme", "auto", "Color theme: auto, dark, light")
 	rootCmd.Flags().StringVar(&renderFlag, "render", "", "Render a tab and exit (overview, workbench, browser, mappings, cluster, tasks)")
+	rootCmd.Flags().StringVar(&renderFormatFlag, "render-format", "ansi", "Output format for --render: ansi, plain, html, svg")
 	rootCmd.Flags().StringVar(&tabFlag, "tab", "", "Start on a specific tab (overview, cluster, workbench, browser, mappings, tasks)")
 	rootCmd.Flags().StringVar(&keysFlag, "keys", "", "Simulate keypresses for --render (comma-separated: up,down,right,enter,pgdown,...)")
 	rootCmd.Flags().IntVar(&widthFlag, "width", 120, "Terminal width for --render")
@@ -62,7 +70,13 @@ Examples:
 	rootCmd.Flags().StringVar(&bodyFlag, "body", "", "JSON body for --render workbench")
 	rootCmd.Flags().StringVar(&viewFlag, "view", "", "View for --render cluster (memory, disk, fielddata, settings, threadpools, hotthreads, templates, deprecations)")
 
+	rootCmd.Flags().BoolVar(&readOnlyFlag, "read-only", false, "Block every request that could modify the cluster")
+	rootCmd.Flags().StringVar(&recordFlag, "record", "", "Record every HTTP exchange to a directory")
+	rootCmd.Flags().StringVar(&replayFlag, "replay", "", "Serve responses recorded with --record instead of contacting a cluster")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.AddCommand(newExecCmd(), newCatCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -99,26 +113,37 @@ func run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	cfg, err := config.ParseURL(resolved.URL)
+	cfg, err := buildConfig(resolved, awsProfile)
 	if err != nil {
-		return fmt.Errorf("config error: %w", err)
+		return err
 	}
-	cfg.AWSProfile = awsProfile
-	cfg.TLSCert = resolved.TLSCert
-	cfg.TLSKey = resolved.TLSKey
-	cfg.TLSCA = resolved.TLSCA
 
-	client, err := es.NewClient(cfg)
+	client, err := newClient(cfg)
 	if err != nil {
-		return fmt.Errorf("client error: %w", err)
+		return err
 	}
 
 	model := ui.New(client, cfg)
 	if tabFlag != "" {
 		model.SetStartTab(tabFlag, viewFlag)
 	}
-	p := tea.NewProgram(model)
-	if _, err := p.Run(); err != nil {
+	if replayFlag == "" {
+		if clusters, err := config.LoadClustersConfig(); err == nil && clusters != nil {
+			model.SetClusterSwitcher(clusters, openCluster)
+		}
+	}
+	p := tea.NewProgram(ui.NewApp(model))
+	final, err := p.Run()
+	// Clients change as sessions are opened and clusters switched, so close
+	// the audit logs of whichever ones are open at exit.
+	if app, ok := final.(ui.App); ok {
+		for _, c := range app.Clients() {
+			if auditLog := c.AuditLog(); auditLog != nil {
+				auditLog.Close()
+			}
+		}
+	}
+	if err != nil {
 		return err
 	}
 	fmt.Print("\033[?1000l\033[?1002l\033[?1003l\033[?1006l")
@@ -126,29 +151,102 @@ func run(cmd *cobra.Command, args []string) error {
 }
 
 func runRenderMode(args []string) error {
+	if !slices.Contains(ui.SnapshotFormats, renderFormatFlag) {
+		return fmt.Errorf("unknown render format %q (use: %s)", renderFormatFlag, strings.Join(ui.SnapshotFormats, ", "))
+	}
+
 	resolved, awsProfile, err := resolveESURL(args, true)
 	if err != nil {
 		return err
 	}
 
-	cfg, err := config.ParseURL(resolved.URL)
+	cfg, err := buildConfig(resolved, awsProfile)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cfg)
 	if err != nil {
-		return fmt.Errorf("config error: %w", err)
+		return err
+	}
+	if auditLog := client.AuditLog(); auditLog != nil {
+		defer auditLog.Close()
+	}
+
+	return renderAndExit(client, renderFlag, renderFormatFlag, widthFlag, heightFlag, bodyFlag, viewFlag, keysFlag)
+}
+
+func buildConfig(resolved *config.ResolvedCluster, awsProfile string) (*config.Config, error) {
+	cfg, err := config.ParseURLs(resolved.AllURLs())
+	if err != nil {
+		return nil, fmt.Errorf("config error: %w", err)
 	}
 	cfg.AWSProfile = awsProfile
 	cfg.TLSCert = resolved.TLSCert
 	cfg.TLSKey = resolved.TLSKey
 	cfg.TLSCA = resolved.TLSCA
+	cfg.APIKey = resolved.APIKey
+	cfg.BearerToken = resolved.BearerToken
+	cfg.TLSServerName = resolved.TLSServerName
+	cfg.InsecureSkipVerify = resolved.InsecureSkipVerify
+	cfg.NodeSelection = resolved.NodeSelection
+	cfg.Sniff = resolved.Sniff
+	cfg.ReadOnly = resolved.ReadOnly || readOnlyFlag
+	cfg.RequestTimeout = resolved.RequestTimeout
+	cfg.ClusterName = resolved.Name
+	cfg.Variables = resolved.Variables
+	cfg.RecordDir = recordFlag
+	cfg.ReplayDir = replayFlag
+	return cfg, nil
+}
 
+// newClient creates the ES client with every request recorded to the audit
+// log in ~/.stoptail/audit.log. Replayed sessions never reach a cluster and
+// are not audited.
+func newClient(cfg *config.Config) (*es.Client, error) {
 	client, err := es.NewClient(cfg)
 	if err != nil {
-		return fmt.Errorf("client error: %w", err)
+		return nil, fmt.Errorf("client error: %w", err)
+	}
+	if cfg.ReplayDir != "" {
+		return client, nil
+	}
+
+	auditLog, err := storage.OpenAuditLog(cfg.AuditName())
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
 	}
+	client.SetAuditLog(auditLog)
+	return client, nil
+}
 
-	return renderAndExit(client, renderFlag, widthFlag, heightFlag, bodyFlag, viewFlag, keysFlag)
+// openCluster builds the client for a cluster picked with the in-app
+// switcher, applying the same command-line flags as at startup.
+func openCluster(resolved *config.ResolvedCluster, awsProfile string) (*es.Client, *config.Config, error) {
+	cfg, err := buildConfig(resolved, awsProfile)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, cfg, nil
 }
 
 func resolveESURL(args []string, skipUI bool) (*config.ResolvedCluster, string, error) {
+	if replayFlag != "" {
+		// Replays never contact the cluster, so skip credential commands.
+		if len(args) > 0 && (strings.HasPrefix(args[0], "http://") || strings.HasPrefix(args[0], "https://")) {
+			return &config.ResolvedCluster{URL: args[0]}, "", nil
+		}
+		resolved := &config.ResolvedCluster{URL: "http://localhost:9200"}
+		if len(args) > 0 {
+			resolved.Name = args[0]
+		}
+		return resolved, "", nil
+	}
+
 	if err := config.EnsureConfigDir(); err != nil {
 		return nil, "", fmt.Errorf("creating config dir: %w", err)
 	}
@@ -175,7 +273,7 @@ func resolveESURL(args []string, skipUI bool) (*config.ResolvedCluster, string,
 
 	if clusters != nil && len(clusters.Clusters) > 0 {
 		if skipUI {
-			return nil, "", fmt.Errorf("cluster name required with --render when multiple clusters configured")
+			return nil, "", fmt.Errorf("cluster name required with --render, exec or cat when multiple clusters configured")
 		}
 		return selectCluster(clusters)
 	}
@@ -311,7 +409,7 @@ func newURLResolver(clusters *config.ClustersConfig, name string) urlResolverMod
 	s.Style = lipgloss.NewStyle().Foreground(ui.SpinnerClr)
 
 	message := "Fetching cluster URL..."
-	if entry, ok := clusters.Clusters[name]; ok && entry.CredentialsCommand != "" {
+	if entry, ok := clusters.Clusters[name]; ok && (entry.CredentialsCommand != "" || entry.APIKeyCommand != "") {
 		message = "Fetching cluster credentials..."
 	}
 
@@ -381,11 +479,7 @@ func resolveClusterWithProgress(clusters *config.ClustersConfig, name string, sk
 		return nil, "", fmt.Errorf("cluster %q not found", name)
 	}
 
-	if entry.URL != "" {
-		return &config.ResolvedCluster{URL: entry.URL}, entry.AWSProfile, nil
-	}
-
-	if skipUI {
+	if skipUI || !entry.NeedsCommand() {
 		resolved, err := clusters.Resolve(name)
 		return resolved, entry.AWSProfile, err
 	}
@@ -403,10 +497,12 @@ func resolveClusterWithProgress(clusters *config.ClustersConfig, name string, sk
 	return resolver.resolved, entry.AWSProfile, nil
 }
 
-func renderAndExit(client *es.Client, tab string, width, height int, body, view, keys string) error {
+func renderAndExit(client *es.Client, tab, format string, width, height int, body, view, keys string) error {
 	ctx := context.Background()
 	keyMsgs := parseKeys(keys)
 
+	var output string
+
 	switch tab {
 	case "overview":
 		state, err := client.FetchClusterState(ctx)
@@ -419,7 +515,7 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			overview, _ = overview.Update(k)
 		}
-		fmt.Println(overview.View())
+		output = overview.View()
 
 	case "cluster":
 		state, err := client.FetchNodesState(ctx)
@@ -442,7 +538,7 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			cluster, _ = cluster.Update(k)
 		}
-		fmt.Println(cluster.View())
+		output = cluster.View()
 
 	case "workbench":
 		workbench := ui.NewWorkbench()
@@ -454,7 +550,7 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			workbench, _ = workbench.Update(k)
 		}
-		fmt.Println(workbench.View())
+		output = workbench.View()
 
 	case "mappings":
 		state, err := client.FetchClusterState(ctx)
@@ -467,7 +563,7 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			mappings, _ = mappings.Update(k)
 		}
-		fmt.Println(mappings.View())
+		output = mappings.View()
 
 	case "browser":
 		state, err := client.FetchClusterState(ctx)
@@ -488,7 +584,7 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			browser, _ = browser.Update(k)
 		}
-		fmt.Println(browser.View())
+		output = browser.View()
 
 	case "tasks":
 		tasks, err := client.FetchTasks(ctx)
@@ -501,11 +597,17 @@ func renderAndExit(client *es.Client, tab string, width, height int, body, view,
 		for _, k := range keyMsgs {
 			tasksModel, _ = tasksModel.Update(k)
 		}
-		fmt.Println(tasksModel.View())
+		output = tasksModel.View()
 
 	default:
 		return fmt.Errorf("unknown tab: %s (use: overview, workbench, browser, mappings, cluster, tasks)", tab)
 	}
+
+	snapshot, err := ui.RenderSnapshot(output, format)
+	if err != nil {
+		return err
+	}
+	fmt.Print(ensureNewline(snapshot))
 	return nil
 }
 
@@ -540,4 +642,3 @@ func parseKeys(keys string) []tea.KeyPressMsg {
 	}
 	return msgs
 }
-